@@ -0,0 +1,201 @@
+import * as cheerio from 'cheerio';
+import { extractStructuredData, shortTypeName } from './structured-data';
+
+export type AddressSource = "structured-data" | "microformat" | "text";
+
+export interface PostalAddress {
+  /** Name of the business or place the address belongs to, when known. */
+  name?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postalCode?: string;
+  /** Country as written on the page ("Deutschland", "US"). */
+  country?: string;
+  /** ISO 3166 code implied by the postal code format, when it is unambiguous. */
+  countryCode?: string;
+  /** The address text the fields were parsed from (text source only). */
+  raw?: string;
+  source: AddressSource;
+}
+
+/** Containers that hold the address on contact, location and footer sections. */
+const ADDRESS_CONTAINERS = [
+  "address", '[itemprop="address"]', '[class*="address" i]', '[id*="address" i]', '[class*="location" i]',
+  '[class*="contact" i]', "footer",
+].join(",");
+
+const STREET_RE =
+  /\d.*\b(?:street|st|avenue|ave|road|rd|boulevard|blvd|lane|ln|drive|dr|way|court|ct|place|pl|square|sq|highway|hwy|parkway|pkwy|suite|ste)\b\.?|(?:straße|strasse|str\.|weg|platz|gasse|allee|ring|damm|ufer)\s*\d|^\d+[a-z]?,?\s+(?:rue|avenue|boulevard|bd|place|chemin|allée|impasse|quai)\b|^(?:rue|via|viale|calle|avenida|av\.|plaza|paseo|carrer|rua|ulica|ul\.)\s/i;
+
+/** Postal-code line shapes, most specific first. */
+const POSTAL_PATTERNS: { re: RegExp; countryCode?: string; parse: (m: RegExpMatchArray) => Partial<PostalAddress> }[] = [
+  // "Springfield, IL 62704" / "Springfield IL 62704-1234"
+  { re: /^(?:(.+?)\s+)?([A-Z]{2})\.?\s+(\d{5}(?:-\d{4})?)$/, countryCode: "US", parse: (m) => ({ city: m[1], region: m[2], postalCode: m[3] }) },
+  // "Toronto, ON M5V 2T6"
+  { re: /^(?:(.+?)\s+)?([A-Z]{2})\s+([A-Z]\d[A-Z]\s?\d[A-Z]\d)$/, countryCode: "CA", parse: (m) => ({ city: m[1], region: m[2], postalCode: m[3] }) },
+  // "London SW1A 2AA" / "SW1A 2AA"
+  { re: /^(?:(.+?)\s+)?([A-Z]{1,2}\d[A-Z\d]?\s\d[A-Z]{2})$/, countryCode: "GB", parse: (m) => ({ city: m[1], postalCode: m[2] }) },
+  // "1012 AB Amsterdam"
+  { re: /^(\d{4}\s?[A-Z]{2})\s+(.+)$/, countryCode: "NL", parse: (m) => ({ postalCode: m[1], city: m[2] }) },
+  // "〒100-0001 東京都千代田区千代田1-1"
+  { re: /^〒?\s*(\d{3}-\d{4})\s*(.*)$/, countryCode: "JP", parse: (m) => ({ postalCode: m[1], street: m[2] || undefined }) },
+  // "10115 Berlin", "D-10115 Berlin", "75008 Paris", "1010 Wien"
+  { re: /^(?:[A-Z]{1,2}-)?(\d{4,5})\s+([\p{L}][\p{L}\s.'\-]+(?:\s\d{1,2})?)$/u, parse: (m) => ({ postalCode: m[1], city: m[2] }) },
+];
+
+const COUNTRY_RE =
+  /^(?:USA|U\.S\.A?\.?|United States(?: of America)?|Canada|United Kingdom|UK|England|Scotland|Wales|Ireland|Germany|Deutschland|Austria|Österreich|Switzerland|Schweiz|Suisse|France|Belgium|Belgique|België|Netherlands|Nederland|Spain|España|Italy|Italia|Portugal|Poland|Polska|Denmark|Danmark|Sweden|Sverige|Norway|Norge|Finland|Japan|日本|Australia|New Zealand|[A-Z]{2})$/;
+
+/**
+ * Finds postal addresses. schema.org PostalAddress data (JSON-LD, microdata,
+ * RDFa) is used first, then h-adr/adr microformats, then address-looking text
+ * in <address>, contact and footer blocks, recognised by its postal-code line
+ * (US ZIP, Canadian, UK, Dutch, Japanese and the four/five-digit European
+ * forms). Duplicates across sources are dropped.
+ */
+export function extractAddresses(html: string | null | undefined, baseUrl?: string | null): PostalAddress[] {
+  if (!html) return [];
+
+  const addresses: PostalAddress[] = [];
+  const seen = new Set<string>();
+  const add = (address: PostalAddress | null) => {
+    if (!address || (!address.street && !address.postalCode && !address.city)) return;
+    const key = [address.street, address.postalCode, address.city].map((v) => (v || "").toLowerCase().replace(/\W+/g, "")).join("|");
+    if (seen.has(key)) return;
+    seen.add(key);
+    addresses.push(address);
+  };
+
+  for (const item of extractStructuredData(html, baseUrl)) {
+    for (const address of findStructuredAddresses(item.data)) add(address);
+  }
+
+  const $ = cheerio.load(html);
+  $(".h-adr, .adr, .h-card, .vcard").each((_i, el) => {
+    const $el = $(el);
+    const pick = (...classes: string[]) =>
+      $el.find(classes.map((c) => `.${c}`).join(",")).first().text().replace(/\s+/g, " ").trim() || undefined;
+    add(clean({
+      name: $el.is(".h-card, .vcard") ? pick("p-name", "fn", "org", "p-org") : undefined,
+      street: pick("p-street-address", "street-address"),
+      city: pick("p-locality", "locality"),
+      region: pick("p-region", "region"),
+      postalCode: pick("p-postal-code", "postal-code"),
+      country: pick("p-country-name", "country-name"),
+      source: "microformat",
+    }));
+  });
+
+  if (!addresses.length) {
+    $("script, style, noscript, template").remove();
+    $(ADDRESS_CONTAINERS).each((_i, el) => {
+      // Innermost containers only, so a footer doesn't repeat an <address> it contains.
+      if ($(el).find(ADDRESS_CONTAINERS).length) return;
+      add(parseAddress(blockText($, el)));
+    });
+  }
+
+  return addresses;
+}
+
+/**
+ * Parses free-form address text, one component per line or comma, into
+ * fields. Returns null when no postal-code line is found.
+ */
+export function parseAddress(text: string | null | undefined): PostalAddress | null {
+  if (!text) return null;
+  const parts = text
+    .split(/\n|,|·|\|/)
+    .map((p) => p.replace(/\s+/g, " ").trim())
+    .filter((p) => p && !/@|^(?:tel|phone|fax|email|e-mail|mobile|open|hours)\b|^\+?\d[\d\s().\-\/]{6,}$/i.test(p));
+
+  for (let i = 0; i < parts.length; i++) {
+    for (const pattern of POSTAL_PATTERNS) {
+      // "1600 Amphitheatre Parkway" has the shape of "10115 Berlin"; street lines are never the postal line.
+      if (pattern.countryCode !== "JP" && STREET_RE.test(parts[i])) continue;
+      const m = parts[i].match(pattern.re);
+      if (!m) continue;
+      const fields = pattern.parse(m);
+      const address: PostalAddress = { source: "text" };
+
+      // US/CA style puts the city in its own comma part before "IL 62704".
+      if (!fields.city && i > 0 && pattern.countryCode !== "JP" && !STREET_RE.test(parts[i - 1])) {
+        fields.city = parts[i - 1];
+      }
+      if (!fields.street) {
+        const before = parts.slice(0, i).filter((p) => p !== fields.city);
+        const street = [...before].reverse().find((p) => STREET_RE.test(p)) ?? (before.length ? before[before.length - 1] : undefined);
+        if (street) fields.street = street;
+      }
+      const country = parts[i + 1] && COUNTRY_RE.test(parts[i + 1]) ? parts[i + 1] : undefined;
+
+      Object.assign(address, clean({ ...fields, country, source: "text" }));
+      if (pattern.countryCode) address.countryCode = pattern.countryCode;
+      address.raw = parts.slice(0, country ? i + 2 : i + 1).join(", ");
+      return address;
+    }
+  }
+  return null;
+}
+
+function findStructuredAddresses(node: any, owner?: string, depth = 0, out: PostalAddress[] = []): PostalAddress[] {
+  if (!node || typeof node !== "object" || depth > 8) return out;
+  if (Array.isArray(node)) {
+    for (const child of node) findStructuredAddresses(child, owner, depth + 1, out);
+    return out;
+  }
+
+  const types = (Array.isArray(node["@type"]) ? node["@type"] : [node["@type"]]).filter(Boolean).map(String).map(shortTypeName);
+  if (types.includes("PostalAddress") || node.streetAddress || node.addressLocality || node.postalCode) {
+    out.push(clean({
+      name: owner,
+      street: [text(node.streetAddress), text(node.extendedAddress)].filter(Boolean).join(", ") || text(node.postOfficeBoxNumber),
+      city: text(node.addressLocality),
+      region: text(node.addressRegion),
+      postalCode: text(node.postalCode),
+      country: text(node.addressCountry),
+      source: "structured-data",
+    }));
+    return out;
+  }
+
+  const name = text(node.name) ?? owner;
+  if (typeof node.address === "string") {
+    const parsed = parseAddress(node.address);
+    if (parsed) out.push({ ...parsed, ...(name ? { name } : {}), source: "structured-data" });
+  }
+  for (const [key, value] of Object.entries(node)) {
+    if (key === "@context") continue;
+    findStructuredAddresses(value, name, depth + 1, out);
+  }
+  return out;
+}
+
+/** Text of a block with <br> and child blocks turned into line breaks. */
+function blockText($: cheerio.CheerioAPI, el: any): string {
+  const $copy = $(el).clone();
+  $copy.find("br").replaceWith("\n");
+  $copy.find("p, div, li, span[itemprop], dd, dt, h1, h2, h3, h4, h5, h6").each((_i, child) => {
+    $(child).prepend("\n").append("\n");
+  });
+  return $copy.text();
+}
+
+function text(value: any): string | undefined {
+  const v = Array.isArray(value) ? value[0] : value;
+  if (v === undefined || v === null) return undefined;
+  if (typeof v === "string" || typeof v === "number") return String(v).replace(/\s+/g, " ").trim() || undefined;
+  if (typeof v === "object") return text(v.name ?? v["@value"]);
+  return undefined;
+}
+
+/** Drops empty fields so absent parts are absent rather than "". */
+function clean(address: Partial<PostalAddress> & { source: AddressSource }): PostalAddress {
+  const out: PostalAddress = { source: address.source };
+  for (const key of ["name", "street", "city", "region", "postalCode", "country"] as const) {
+    const value = address[key]?.trim();
+    if (value) out[key] = value;
+  }
+  return out;
+}
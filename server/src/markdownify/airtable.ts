@@ -0,0 +1,117 @@
+import { Inline, MdBlock, parseBlocks, plainText } from './blocks';
+
+/**
+ * Renders converter markdown in the subset Airtable's rich text fields
+ * understand: headings 1-3, bold, italic, strikethrough, inline and block
+ * code, links, quotes, bullet, numbered and checkbox lists. Everything else
+ * degrades rather than showing up as raw syntax:
+ * - tables become one bullet per row, "Header: value" pairs joined by " · "
+ * - images become links to the image
+ * - headings 4-6 become bold lines
+ * - superscripts, rules and raw HTML keep only their text
+ */
+export function markdownToAirtable(markdown: string): string {
+  const out = renderBlocks(parseBlocks(markdown));
+  return out ? `${out}\n` : "";
+}
+
+function renderBlocks(blocks: MdBlock[]): string {
+  return blocks.map(renderBlock).filter(Boolean).join("\n\n");
+}
+
+function renderBlock(block: MdBlock): string {
+  switch (block.kind) {
+    case "heading": {
+      const text = renderInline(block.content).replace(/\n/g, " ").trim();
+      if (!text) return "";
+      return block.level <= 3 ? `${"#".repeat(block.level)} ${text}` : `**${text}**`;
+    }
+    case "paragraph":
+      return renderInline(block.content);
+    case "code": {
+      const fence = "`".repeat(Math.max(3, ...(block.code.match(/`{3,}/g) || []).map((run) => run.length + 1)));
+      return `${fence}\n${block.code}\n${fence}`;
+    }
+    case "quote":
+      return renderBlocks(block.blocks).split("\n").map((line) => `> ${line}`.trimEnd()).join("\n");
+    case "list":
+      return block.items
+        .map((item, i) => {
+          const marker = block.ordered ? `${block.start + i}.` : "-";
+          const task = item.task === undefined ? "" : item.task ? "[x] " : "[ ] ";
+          const body = item.blocks.map(renderBlock).filter(Boolean).join("\n");
+          return `${marker} ${task}${body.replace(/\n/g, `\n${" ".repeat(marker.length + 1)}`)}`.trimEnd();
+        })
+        .join("\n");
+    case "table": {
+      const header = block.header.map((cell) => plainText(cell).replace(/\s+/g, " ").trim());
+      return block.rows
+        .map((row) => {
+          const pairs = row
+            .map((cell, col) => {
+              const value = renderInline(cell).replace(/\n/g, " ").trim();
+              if (!value) return "";
+              return header[col] ? `${escape(header[col])}: ${value}` : value;
+            })
+            .filter(Boolean);
+          return pairs.length ? `- ${pairs.join(" · ")}` : "";
+        })
+        .filter(Boolean)
+        .join("\n");
+    }
+    case "rule":
+      return "";
+    case "html":
+      return escape(block.html.replace(/<[^>]+>/g, " ").replace(/\s+/g, " ").trim());
+  }
+}
+
+function renderInline(nodes: Inline[]): string {
+  return nodes
+    .map((node) => {
+      switch (node.kind) {
+        case "text":
+          return escape(node.text);
+        case "strong":
+          return wrap("**", renderInline(node.children));
+        case "em":
+          return wrap("_", renderInline(node.children));
+        case "strike":
+          return wrap("~~", renderInline(node.children));
+        case "sup":
+          return renderInline(node.children);
+        case "code": {
+          const ticks = "`".repeat(Math.max(1, ...(node.text.match(/`+/g) || []).map((run) => run.length + 1)));
+          return node.text ? `${ticks}${ticks.length > 1 ? ` ${node.text} ` : node.text}${ticks}` : "";
+        }
+        case "link": {
+          const text = renderInline(node.children).trim() || escape(node.href);
+          return node.href.startsWith("#") ? text : `[${text}](${destination(node.href)})`;
+        }
+        case "image":
+          return `[${escape(node.alt || "Image")}](${destination(node.src)})`;
+        case "break":
+          return "\n";
+        case "html":
+          return "";
+      }
+    })
+    .join("");
+}
+
+/** Airtable drops emphasis whose markers touch spaces, so the spaces move outside. */
+function wrap(mark: string, text: string): string {
+  const m = text.match(/^(\s*)([\s\S]*?)(\s*)$/)!;
+  return m[2] ? `${m[1]}${mark}${m[2]}${mark}${m[3]}` : text;
+}
+
+function escape(text: string): string {
+  return text
+    .replace(/[\\`*_~\[\]]/g, "\\$&")
+    .replace(/^(\s*)([#>]|[-+](?=\s))/gm, "$1\\$2")
+    .replace(/^(\s*\d+)\.(?=\s)/gm, "$1\\.");
+}
+
+function destination(url: string): string {
+  return url.replace(/[\s()]/g, (c) => encodeURIComponent(c));
+}
@@ -0,0 +1,81 @@
+import * as cheerio from 'cheerio';
+
+/** AMP runtime and monetisation components that never hold article content. */
+const AMP_CHROME_SELECTOR = [
+  "amp-analytics", "amp-pixel", "amp-ad", "amp-embed", "amp-sticky-ad", "amp-auto-ads",
+  "amp-consent", "amp-user-notification", "amp-geo", "amp-state", "amp-bind-macro",
+  "amp-sidebar", "amp-install-serviceworker", "amp-call-tracking", "amp-access",
+].join(",");
+
+const IMAGE_ATTRS = ["src", "srcset", "sizes", "alt", "title", "width", "height"];
+
+/** Third-party embeds keyed by component, with the page each one shows. */
+const EMBEDS: Record<string, (el: cheerio.Cheerio<any>) => string | undefined> = {
+  "amp-youtube": (el) => el.attr("data-videoid") && `https://www.youtube.com/watch?v=${el.attr("data-videoid")}`,
+  "amp-vimeo": (el) => el.attr("data-videoid") && `https://vimeo.com/${el.attr("data-videoid")}`,
+  "amp-dailymotion": (el) => el.attr("data-videoid") && `https://www.dailymotion.com/video/${el.attr("data-videoid")}`,
+  "amp-twitter": (el) => el.attr("data-tweetid") && `https://twitter.com/i/status/${el.attr("data-tweetid")}`,
+  "amp-instagram": (el) => el.attr("data-shortcode") && `https://www.instagram.com/p/${el.attr("data-shortcode")}/`,
+  "amp-facebook": (el) => el.attr("data-href"),
+  "amp-soundcloud": (el) => el.attr("data-trackid") && `https://api.soundcloud.com/tracks/${el.attr("data-trackid")}`,
+  "amp-iframe": (el) => el.attr("src"),
+};
+
+/**
+ * Rewrites AMP components into the elements the converter understands, in
+ * place: amp-img and amp-anim become <img>, amp-video and amp-audio become a
+ * link to the media (wrapping the poster image when there is one), and
+ * amp-iframe and the social/video embeds become links to what they embed,
+ * since iframes are dropped by the sanitizer. Placeholders, fallbacks and the
+ * runtime's own components are removed. Runs before sanitizing, so the links
+ * it creates are sanitized like any other.
+ */
+export function normalizeAmp($: cheerio.CheerioAPI): void {
+  $(AMP_CHROME_SELECTOR).remove();
+
+  // A <noscript><img></noscript> fallback is what the page shows without the AMP runtime; it wins over the component.
+  $("amp-img, amp-anim").each((_i, el) => {
+    const $fallback = $(el).children("noscript").find("img[src]").first();
+    if ($fallback.length) $(el).replaceWith($fallback);
+  });
+  $("[placeholder], [fallback], [overflow]").filter((_i, el) => /^amp-/.test((el as any).parent?.name || "")).remove();
+
+  $("amp-img, amp-anim").each((_i, el) => {
+    const $img = $("<img>");
+    for (const name of IMAGE_ATTRS) {
+      const value = $(el).attr(name);
+      if (value) $img.attr(name, value);
+    }
+    $(el).replaceWith($img);
+  });
+
+  $("amp-video, amp-audio").each((_i, el) => {
+    const $el = $(el);
+    const src = $el.attr("src") || $el.find("source[src]").first().attr("src");
+    if (!src) {
+      $el.remove();
+      return;
+    }
+    const title = ($el.attr("title") || $el.attr("aria-label") || "").trim();
+    const $link = $("<a>").attr("href", src);
+    if (title) $link.attr("title", title);
+    const poster = $el.attr("poster");
+    if (poster) $link.append($("<img>").attr("src", poster).attr("alt", title || (el.name === "amp-video" ? "Video" : "Audio")));
+    $el.replaceWith($link);
+  });
+
+  for (const [name, target] of Object.entries(EMBEDS)) {
+    $(name).each((_i, el) => {
+      const $el = $(el);
+      const href = target($el);
+      if (!href) {
+        $el.remove();
+        return;
+      }
+      const $link = $("<a>").attr("href", href);
+      const title = ($el.attr("title") || $el.attr("aria-label") || "").trim();
+      if (title) $link.attr("title", title);
+      $el.replaceWith($link);
+    });
+  }
+}
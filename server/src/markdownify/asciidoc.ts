@@ -0,0 +1,146 @@
+import { Inline, ListItem, MdBlock, parseBlocks } from './blocks';
+
+/**
+ * Renders converter markdown as AsciiDoc (Asciidoctor/Antora dialect). A
+ * leading level-1 heading becomes the document title; raw HTML blocks are
+ * kept as passthrough blocks.
+ */
+export function markdownToAsciiDoc(markdown: string): string {
+  const out = renderBlocks(parseBlocks(markdown), 0);
+  return out ? `${out}\n` : "";
+}
+
+function renderBlocks(blocks: MdBlock[], depth: number): string {
+  return blocks.map((block) => renderBlock(block, depth)).filter(Boolean).join("\n\n");
+}
+
+function renderBlock(block: MdBlock, depth: number): string {
+  switch (block.kind) {
+    case "heading":
+      return `${"=".repeat(block.level)} ${renderInline(block.content).replace(/\n/g, " ")}`;
+    case "paragraph": {
+      const [only] = block.content;
+      if (block.content.length === 1 && only.kind === "image") return `image::${target(only.src)}[${attribute(only.alt)}]`;
+      return guardLineStarts(renderInline(block.content));
+    }
+    case "code":
+      return `${block.lang ? `[source,${block.lang}]\n` : ""}${delimit("-", block.code)}`;
+    case "quote":
+      return delimit("_", renderBlocks(block.blocks, depth));
+    case "list":
+      return block.items.map((item) => renderItem(item, block.ordered, depth)).join("\n");
+    case "table":
+      return renderTable(block);
+    case "rule":
+      return "'''";
+    case "html":
+      return `++++\n${block.html}\n++++`;
+  }
+}
+
+/**
+ * Nested AsciiDoc lists repeat the marker ("**", "..") instead of indenting;
+ * further blocks of an item are attached with a "+" continuation line.
+ */
+function renderItem(item: ListItem, ordered: boolean, depth: number): string {
+  const marker = (ordered ? "." : "*").repeat(depth + 1);
+  const task = item.task === undefined ? "" : item.task ? "[x] " : "[ ] ";
+  const [first, ...rest] = item.blocks;
+  const lines: string[] = [];
+
+  if (first && first.kind === "paragraph") lines.push(`${marker} ${task}${renderInline(first.content)}`);
+  else {
+    lines.push(`${marker} ${task}{empty}`);
+    if (first) rest.unshift(first);
+  }
+  for (const block of rest) {
+    if (block.kind === "list") lines.push(renderBlock(block, depth + 1));
+    else lines.push("+", renderBlock(block, depth + 1));
+  }
+  return lines.join("\n");
+}
+
+function renderTable(block: Extract<MdBlock, { kind: "table" }>): string {
+  const width = Math.max(block.header.length, ...block.rows.map((r) => r.length));
+  const cols = Array.from({ length: width }, (_v, i) => ({ left: "<", center: "^", right: ">" }[block.align[i] ?? ""] ?? "") + "1");
+  const row = (cells: Inline[][]) =>
+    Array.from({ length: width }, (_v, i) => `| ${renderInline(cells[i] ?? []).replace(/\n/g, " +\n").replace(/\|/g, "\\|")}`).join(" ");
+
+  return [
+    `[cols="${cols.join(",")}",options="header"]`,
+    "|===",
+    row(block.header),
+    "",
+    ...block.rows.map(row),
+    "|===",
+  ].join("\n");
+}
+
+function renderInline(nodes: Inline[]): string {
+  return nodes
+    .map((node) => {
+      switch (node.kind) {
+        case "text":
+          return escapeText(node.text);
+        case "strong":
+          return unconstrained("*", renderInline(node.children));
+        case "em":
+          return unconstrained("_", renderInline(node.children));
+        case "strike":
+          return `[.line-through]#${renderInline(node.children)}#`;
+        case "sup":
+          return `^${renderInline(node.children)}^`;
+        case "code":
+          // The + passthrough keeps AsciiDoc from reading markup inside code.
+          return node.text.includes("+") ? `\`pass:[${node.text.replace(/]/g, "\\]")}]\`` : `\`+${node.text}+\``;
+        case "link": {
+          const text = attribute(renderInline(node.children));
+          if (node.href.startsWith("#")) return `<<${node.href.slice(1)},${text}>>`;
+          if (/^mailto:/i.test(node.href)) return `${node.href}[${text}]`;
+          return `link:${target(node.href)}[${text}]`;
+        }
+        case "image":
+          return `image:${target(node.src)}[${attribute(node.alt)}]`;
+        case "break":
+          return " +\n";
+        case "html":
+          // Inline tags have no AsciiDoc equivalent; their text content is kept.
+          return "";
+      }
+    })
+    .join("");
+}
+
+/** Doubled marks work mid-word ("un**believ**able"); single ones need word boundaries. */
+function unconstrained(mark: string, content: string): string {
+  return content ? `${mark}${mark}${content}${mark}${mark}` : "";
+}
+
+/**
+ * Backslash-escapes the marks AsciiDoc would read as formatting or attribute
+ * references. A single "*" or "_" inside a word ("snake_case") is inert and
+ * left alone.
+ */
+function escapeText(text: string): string {
+  return text.replace(/\*\*|__|\+\+|<<|[`#^~{]|(?<![\p{L}\p{N}])[*_]|[*_](?![\p{L}\p{N}])/gu, "\\$&");
+}
+
+/** Block-level syntax at the start of a paragraph line ("* ", ". ", "= ") is neutralised with {empty}. */
+function guardLineStarts(text: string): string {
+  return text.replace(/^(?=(?:[*.=\-]+|\d+\.|[|<>/]{2,}|:[\w-]+:)(?:\s|$))/gm, "{empty}");
+}
+
+function attribute(text: string): string {
+  return text.replace(/\n/g, " ").replace(/]/g, "\\]");
+}
+
+function target(url: string): string {
+  return url.replace(/[\s\[\]]/g, (c) => encodeURIComponent(c));
+}
+
+/** A delimited block whose fence is longer than any run of the fence character inside it. */
+function delimit(char: string, content: string): string {
+  const runs = content.match(new RegExp(`^\\${char}{4,}$`, "gm")) || [];
+  const fence = char.repeat(Math.max(4, ...runs.map((run) => run.length + 1)));
+  return `${fence}\n${content}\n${fence}`;
+}
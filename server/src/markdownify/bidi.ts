@@ -0,0 +1,115 @@
+import * as cheerio from 'cheerio';
+
+/**
+ * How right-to-left text keeps its direction once markup is gone.
+ * - marks: an RLM/LRM starts each block whose direction differs from the
+ *   left-to-right default or its surroundings, so viewers pick the right
+ *   base direction and punctuation stays at the correct end
+ * - annotate: runs of blocks with their own direction are wrapped in
+ *   <div dir="…"> so renderers that honour HTML lay them out natively;
+ *   list items and table cells, which cannot be wrapped, fall back to marks
+ * - none: no direction handling
+ * In every mode but none, inline elements (and <bdi>) whose direction
+ * differs from the text around them are wrapped in Unicode isolates so an
+ * embedded fragment doesn't reorder its surroundings. Pages without
+ * right-to-left text come out unchanged.
+ */
+export type BidiMode = "marks" | "annotate" | "none";
+
+export type Direction = "ltr" | "rtl";
+
+/** Attribute on the wrappers annotate mode inserts; the converter emits them as <div dir>. */
+export const BIDI_DIR_ATTR = "data-md-dir";
+
+const RLM = "\u200F";
+const LRM = "\u200E";
+const ISOLATES = { ltr: "\u2066", rtl: "\u2067" };
+const POP_ISOLATE = "\u2069";
+
+const BLOCK_TAGS = new Set([
+  "address", "article", "aside", "blockquote", "caption", "dd", "details", "dialog", "div", "dl", "dt",
+  "fieldset", "figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header",
+  "hr", "li", "main", "nav", "ol", "p", "section", "summary", "table", "tbody", "td", "tfoot", "th",
+  "thead", "tr", "ul",
+]);
+
+/** Blocks whose parent only accepts them as direct children, so they can't get a wrapper. */
+const UNWRAPPABLE_TAGS = new Set(["li", "dt", "dd", "tr", "td", "th", "thead", "tbody", "tfoot", "caption", "summary"]);
+
+/** Code keeps its characters exactly. */
+const VERBATIM_TAGS = new Set(["pre", "code", "kbd", "samp", "textarea"]);
+
+const RTL_CHAR_RE = /[\p{Script=Hebrew}\p{Script=Arabic}\p{Script=Syriac}\p{Script=Thaana}\p{Script=Nko}\p{Script=Adlam}]/u;
+
+/** Direction of the first strongly directional character, as dir="auto" resolves it. */
+export function detectDirection(text: string): Direction | undefined {
+  const first = text.match(/\p{L}/u);
+  if (!first) return undefined;
+  return RTL_CHAR_RE.test(first[0]) ? "rtl" : "ltr";
+}
+
+/**
+ * Applies `mode` to the content in place, before conversion. Directions
+ * come from dir attributes, inherited from the content element and its
+ * ancestors, and for dir="auto" from the text.
+ */
+export function applyBidi($: cheerio.CheerioAPI, $content: cheerio.Cheerio<any>, mode: BidiMode): void {
+  if (mode === "none") return;
+  const $declared = $content.first().closest("[dir]");
+  const rootDir = $declared.length ? resolve($, $declared[0], "ltr") : "ltr";
+
+  // Markdown viewers lay text out left to right, so that is what the reader sees until a wrapper says otherwise.
+  let shown: Direction = "ltr";
+  if (mode === "annotate" && rootDir === "rtl" && $content.first()[0]?.type !== "root") {
+    $content.first().wrapInner(`<div ${BIDI_DIR_ATTR}="rtl"></div>`);
+    shown = "rtl";
+  }
+  for (const el of $content.toArray()) visit($, el, rootDir, shown, mode);
+}
+
+function visit($: cheerio.CheerioAPI, parent: any, parentDir: Direction, shown: Direction, mode: BidiMode): void {
+  for (const el of $(parent).children().toArray() as any[]) {
+    if (VERBATIM_TAGS.has(el.name)) continue;
+    const $el = $(el);
+    const dir = resolve($, el, parentDir);
+    const attr = ($el.attr("dir") || "").toLowerCase();
+
+    if (!BLOCK_TAGS.has(el.name)) {
+      // For dir="auto" and <bdi> the direction is resolved above, so a strong isolate does what FSI would.
+      if ((el.name === "bdi" || attr) && dir !== parentDir) $el.prepend(ISOLATES[dir]).append(POP_ISOLATE);
+      visit($, el, dir, shown, mode);
+      continue;
+    }
+
+    let inner = shown;
+    if (mode === "annotate" && dir !== shown && !UNWRAPPABLE_TAGS.has(el.name)) {
+      $el.wrap(`<div ${BIDI_DIR_ATTR}="${dir}"></div>`);
+      inner = dir;
+    } else if ((dir !== shown || dir !== parentDir) && startsWithText($, el)) {
+      $el.prepend(dir === "rtl" ? RLM : LRM);
+    }
+    visit($, el, dir, inner, mode);
+  }
+}
+
+function resolve($: cheerio.CheerioAPI, el: any, parentDir: Direction): Direction {
+  const attr = ($(el).attr("dir") || "").toLowerCase();
+  if (attr === "rtl" || attr === "ltr") return attr;
+  if (attr === "auto" || el.name === "bdi") return detectDirection($(el).text()) ?? parentDir;
+  return parentDir;
+}
+
+/** True when the block's first content is text rather than another block, so a mark there starts a line. */
+function startsWithText($: cheerio.CheerioAPI, el: any): boolean {
+  for (const node of el.children || []) {
+    if (node.type === "comment") continue;
+    if (node.type === "text") {
+      if (node.data.trim()) return true;
+      continue;
+    }
+    if (node.type !== "tag") continue;
+    if (BLOCK_TAGS.has(node.name) || node.name === "pre") return false;
+    return $(node).text().trim() !== "";
+  }
+  return false;
+}
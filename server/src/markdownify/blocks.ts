@@ -0,0 +1,435 @@
+/**
+ * A small parser for the markdown this package emits, shared by the non-markdown
+ * output backends. It covers what the converter produces — ATX/setext
+ * headings, paragraphs, nested lists, blockquotes, fenced code, GFM tables,
+ * rules and raw HTML blocks — not every corner of CommonMark.
+ */
+
+export type Inline =
+  | { kind: "text"; text: string }
+  | { kind: "strong" | "em" | "strike" | "sup"; children: Inline[] }
+  | { kind: "code"; text: string }
+  | { kind: "link"; href: string; title?: string; children: Inline[] }
+  | { kind: "image"; src: string; alt: string; title?: string }
+  | { kind: "break" }
+  | { kind: "html"; html: string };
+
+export type TableAlign = "left" | "center" | "right" | null;
+
+export interface ListItem {
+  blocks: MdBlock[];
+  /** Checked state of a GFM task list item. */
+  task?: boolean;
+}
+
+export type MdBlock =
+  | { kind: "heading"; level: number; content: Inline[] }
+  | { kind: "paragraph"; content: Inline[] }
+  | { kind: "code"; lang: string; code: string }
+  | { kind: "quote"; blocks: MdBlock[] }
+  | { kind: "list"; ordered: boolean; start: number; items: ListItem[] }
+  | { kind: "table"; align: TableAlign[]; header: Inline[][]; rows: Inline[][][] }
+  | { kind: "rule" }
+  | { kind: "html"; html: string };
+
+const FENCE_RE = /^(\s{0,3})(`{3,}|~{3,})\s*([^`\s]*)[^`]*$/;
+const ATX_RE = /^\s{0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$/;
+const RULE_RE = /^\s{0,3}([-*_])(?:\s*\1){2,}\s*$/;
+const LIST_RE = /^(\s*)([-*+]|\d{1,9}[.)])(\s+|$)/;
+const HTML_BLOCK_RE = /^\s{0,3}<\/?(?:table|thead|tbody|tr|td|th|div|pre|section|article|figure|details|dl|ul|ol|blockquote|iframe|p|h[1-6]|hr)\b/i;
+const DELIMITER_ROW_RE = /^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$/;
+
+export function parseBlocks(markdown: string): MdBlock[] {
+  return blocksOf(markdown.replace(/\r\n?/g, "\n").split("\n"));
+}
+
+function blocksOf(lines: string[]): MdBlock[] {
+  const blocks: MdBlock[] = [];
+  let i = 0;
+
+  while (i < lines.length) {
+    const line = lines[i];
+    if (!line.trim()) {
+      i++;
+      continue;
+    }
+
+    const fence = line.match(FENCE_RE);
+    if (fence) {
+      const indent = fence[1].length;
+      const code: string[] = [];
+      for (i++; i < lines.length; i++) {
+        const close = lines[i].match(/^\s{0,3}(`{3,}|~{3,})\s*$/);
+        if (close && close[1][0] === fence[2][0] && close[1].length >= fence[2].length) {
+          i++;
+          break;
+        }
+        code.push(lines[i].replace(new RegExp(`^ {0,${indent}}`), ""));
+      }
+      blocks.push({ kind: "code", lang: fence[3], code: code.join("\n") });
+      continue;
+    }
+
+    const atx = line.match(ATX_RE);
+    if (atx) {
+      blocks.push({ kind: "heading", level: atx[1].length, content: parseInline(atx[2] || "") });
+      i++;
+      continue;
+    }
+
+    if (RULE_RE.test(line)) {
+      blocks.push({ kind: "rule" });
+      i++;
+      continue;
+    }
+
+    if (/^\s{0,3}>/.test(line)) {
+      const quoted: string[] = [];
+      for (; i < lines.length && /^\s{0,3}>/.test(lines[i]); i++) quoted.push(lines[i].replace(/^\s{0,3}> ?/, ""));
+      blocks.push({ kind: "quote", blocks: blocksOf(quoted) });
+      continue;
+    }
+
+    if (line.includes("|") && i + 1 < lines.length && DELIMITER_ROW_RE.test(lines[i + 1]) && lines[i + 1].includes("-")) {
+      const align = splitCells(lines[i + 1]).map((cell): TableAlign => {
+        const c = cell.trim();
+        if (c.startsWith(":") && c.endsWith(":")) return "center";
+        if (c.endsWith(":")) return "right";
+        if (c.startsWith(":")) return "left";
+        return null;
+      });
+      const header = splitCells(line).map(parseInline);
+      const rows: Inline[][][] = [];
+      for (i += 2; i < lines.length && lines[i].trim() && lines[i].includes("|"); i++) {
+        rows.push(splitCells(lines[i]).map(parseInline));
+      }
+      blocks.push({ kind: "table", align, header, rows });
+      continue;
+    }
+
+    const item = line.match(LIST_RE);
+    if (item) {
+      const list = parseList(lines, i);
+      blocks.push(list.block);
+      i = list.next;
+      continue;
+    }
+
+    if (HTML_BLOCK_RE.test(line)) {
+      const html: string[] = [];
+      for (; i < lines.length && lines[i].trim(); i++) html.push(lines[i]);
+      blocks.push({ kind: "html", html: html.join("\n") });
+      continue;
+    }
+
+    const paragraph: string[] = [];
+    for (; i < lines.length && lines[i].trim(); i++) {
+      if (paragraph.length && startsBlock(lines[i])) break;
+      const setext = paragraph.length && lines[i].match(/^\s{0,3}(=+|-+)\s*$/);
+      if (setext) {
+        blocks.push({ kind: "heading", level: setext[1][0] === "=" ? 1 : 2, content: parseInline(paragraph.join("\n")) });
+        paragraph.length = 0;
+        i++;
+        break;
+      }
+      paragraph.push(lines[i]);
+    }
+    if (paragraph.length) blocks.push({ kind: "paragraph", content: parseInline(paragraph.join("\n")) });
+  }
+
+  return blocks;
+}
+
+function startsBlock(line: string): boolean {
+  return FENCE_RE.test(line) || ATX_RE.test(line) || /^\s{0,3}>/.test(line) || HTML_BLOCK_RE.test(line)
+    || (LIST_RE.test(line) && !/^\s*\d{2,}[.)]/.test(line)) || (RULE_RE.test(line) && !/^\s*-+\s*$/.test(line));
+}
+
+/**
+ * Collects one list: items start at the list's marker column, and everything
+ * indented past it (or separated only by blank lines) belongs to the item.
+ */
+function parseList(lines: string[], start: number): { block: MdBlock; next: number } {
+  const first = lines[start].match(LIST_RE)!;
+  const markerIndent = first[1].length;
+  const ordered = /\d/.test(first[2]);
+  const items: ListItem[] = [];
+  let i = start;
+
+  while (i < lines.length) {
+    const m = lines[i].match(LIST_RE);
+    if (!m || m[1].length !== markerIndent || /\d/.test(m[2]) !== ordered) break;
+    const contentIndent = markerIndent + m[2].length + Math.max(1, Math.min(m[3].length, 4));
+    const body = [lines[i].slice(Math.min(lines[i].length, markerIndent + m[2].length + m[3].length))];
+
+    for (i++; i < lines.length; i++) {
+      const line = lines[i];
+      if (!line.trim()) {
+        // A blank line continues the item only if indented content follows.
+        const next = lines.slice(i + 1).find((l) => l.trim());
+        if (next === undefined || indentOf(next) < contentIndent) break;
+        body.push("");
+        continue;
+      }
+      const indent = indentOf(line);
+      if (indent >= contentIndent) body.push(line.slice(contentIndent));
+      else if (LIST_RE.test(line)) {
+        if (indent <= markerIndent) break;
+        body.push(line.trimStart()); // a nested list indented less than the content
+      } else if (body[body.length - 1] && !startsBlock(line)) body.push(line.trim()); // lazy continuation
+      else break;
+    }
+
+    const entry: ListItem = { blocks: [] };
+    const task = body[0].match(/^\[([ xX])\]\s+/);
+    if (task) {
+      entry.task = task[1] !== " ";
+      body[0] = body[0].slice(task[0].length);
+    }
+    entry.blocks = blocksOf(body);
+    items.push(entry);
+
+    // Blank lines between items of the same list are allowed.
+    while (i < lines.length && !lines[i].trim()) {
+      const next = lines.slice(i).find((l) => l.trim());
+      const nm = next?.match(LIST_RE);
+      if (!nm || nm[1].length !== markerIndent || /\d/.test(nm[2]) !== ordered) break;
+      i++;
+    }
+  }
+
+  return { block: { kind: "list", ordered, start: ordered ? parseInt(first[2], 10) : 1, items }, next: i };
+}
+
+function indentOf(line: string): number {
+  return line.match(/^\s*/)![0].replace(/\t/g, "    ").length;
+}
+
+function splitCells(line: string): string[] {
+  const trimmed = line.trim().replace(/^\|/, "").replace(/(?<!\\)\|$/, "");
+  const cells: string[] = [];
+  let current = "";
+  for (let i = 0; i < trimmed.length; i++) {
+    if (trimmed[i] === "\\" && trimmed[i + 1] === "|") {
+      current += "|";
+      i++;
+    } else if (trimmed[i] === "|") {
+      cells.push(current.trim());
+      current = "";
+    } else {
+      current += trimmed[i];
+    }
+  }
+  cells.push(current.trim());
+  return cells;
+}
+
+const DELIMITERS: Record<string, "strong" | "em" | "strike" | "sup"> = {
+  "**": "strong", "__": "strong", "*": "em", "_": "em", "~~": "strike", "^": "sup",
+};
+
+/** Parses inline markdown: emphasis, code spans, links, images, breaks and inline HTML. */
+export function parseInline(text: string): Inline[] {
+  const out: Inline[] = [];
+  let buffer = "";
+  const flush = () => {
+    if (buffer) out.push({ kind: "text", text: buffer });
+    buffer = "";
+  };
+
+  for (let i = 0; i < text.length; ) {
+    const ch = text[i];
+    const rest = text.slice(i);
+
+    if (ch === "\\" && i + 1 < text.length) {
+      if (text[i + 1] === "\n") {
+        flush();
+        out.push({ kind: "break" });
+      } else if (/[!-\/:-@\[-`{-~]/.test(text[i + 1])) {
+        buffer += text[i + 1];
+      } else {
+        buffer += "\\" + text[i + 1];
+      }
+      i += 2;
+      continue;
+    }
+
+    if (ch === "\n") {
+      if (/ {2,}$/.test(buffer)) {
+        buffer = buffer.replace(/ +$/, "");
+        flush();
+        out.push({ kind: "break" });
+      } else {
+        buffer = buffer.replace(/ +$/, "") + " ";
+      }
+      i++;
+      while (text[i] === " ") i++;
+      continue;
+    }
+
+    if (ch === "`") {
+      const run = rest.match(/^`+/)![0];
+      const close = text.indexOf(run, i + run.length);
+      if (close > 0 && text[close + run.length] !== "`") {
+        flush();
+        let code = text.slice(i + run.length, close).replace(/\n/g, " ");
+        if (/^ .* $/.test(code) && code.trim()) code = code.slice(1, -1);
+        out.push({ kind: "code", text: code });
+        i = close + run.length;
+        continue;
+      }
+      buffer += run;
+      i += run.length;
+      continue;
+    }
+
+    if (ch === "!" && text[i + 1] === "[") {
+      const link = matchLink(text, i + 1);
+      if (link) {
+        flush();
+        const image: Inline = { kind: "image", src: link.href, alt: plainText(parseInline(link.label)) };
+        if (link.title) image.title = link.title;
+        out.push(image);
+        i = link.end;
+        continue;
+      }
+    }
+
+    if (ch === "[") {
+      const link = matchLink(text, i);
+      if (link) {
+        flush();
+        const node: Inline = { kind: "link", href: link.href, children: parseInline(link.label) };
+        if (link.title) node.title = link.title;
+        out.push(node);
+        i = link.end;
+        continue;
+      }
+    }
+
+    if (ch === "<") {
+      const auto = rest.match(/^<((?:https?|ftp|mailto):[^\s<>]+)>/i);
+      if (auto) {
+        flush();
+        out.push({ kind: "link", href: auto[1], children: [{ kind: "text", text: auto[1].replace(/^mailto:/i, "") }] });
+        i += auto[0].length;
+        continue;
+      }
+      if (/^<br\s*\/?>/i.test(rest)) {
+        flush();
+        out.push({ kind: "break" });
+        i += rest.match(/^<br\s*\/?>/i)![0].length;
+        continue;
+      }
+      const tag = rest.match(/^<\/?[a-z][a-z0-9-]*(?:\s[^<>]*)?\/?>/i);
+      if (tag) {
+        flush();
+        out.push({ kind: "html", html: tag[0] });
+        i += tag[0].length;
+        continue;
+      }
+    }
+
+    const delimiter = rest.startsWith("**") ? "**" : rest.startsWith("__") ? "__" : rest.startsWith("~~") ? "~~" : "*_^".includes(ch) ? ch : "";
+    if (delimiter) {
+      const close = findClosing(text, i, delimiter);
+      if (close > 0) {
+        flush();
+        out.push({ kind: DELIMITERS[delimiter], children: parseInline(text.slice(i + delimiter.length, close)) });
+        i = close + delimiter.length;
+        continue;
+      }
+      buffer += delimiter;
+      i += delimiter.length;
+      continue;
+    }
+
+    buffer += ch;
+    i++;
+  }
+  flush();
+  return out;
+}
+
+/** `[label](href "title")` starting at `open`; brackets and parentheses may nest one level. */
+function matchLink(text: string, open: number): { label: string; href: string; title?: string; end: number } | null {
+  let depth = 0;
+  let close = -1;
+  for (let j = open; j < text.length; j++) {
+    if (text[j] === "\\") {
+      j++;
+      continue;
+    }
+    if (text[j] === "[") depth++;
+    else if (text[j] === "]" && --depth === 0) {
+      close = j;
+      break;
+    }
+  }
+  if (close < 0 || text[close + 1] !== "(") return null;
+
+  const m = text.slice(close + 1).match(/^\(\s*(<[^>]*>|(?:[^\s()\\]|\\.|\([^\s()]*\))*)(?:\s+("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'))?\s*\)/);
+  if (!m) return null;
+  const link: { label: string; href: string; title?: string; end: number } = {
+    label: text.slice(open + 1, close),
+    href: m[1].replace(/^<|>$/g, "").replace(/\\(.)/g, "$1"),
+    end: close + 1 + m[0].length,
+  };
+  if (m[2]) link.title = m[2].slice(1, -1).replace(/\\(.)/g, "$1");
+  return link;
+}
+
+/**
+ * Closing delimiter for emphasis opened at `start`: the opener must be
+ * followed by non-space and the closer preceded by non-space; "_" does not
+ * open or close inside a word.
+ */
+function findClosing(text: string, start: number, delimiter: string): number {
+  const after = text[start + delimiter.length];
+  if (!after || /\s/.test(after)) return -1;
+  if (delimiter[0] === "_" && /[\p{L}\p{N}]/u.test(text[start - 1] || "")) return -1;
+
+  for (let j = start + delimiter.length + 1; j <= text.length - delimiter.length; j++) {
+    if (text[j] === "\\") {
+      j++;
+      continue;
+    }
+    if (text[j] === "`") {
+      const run = text.slice(j).match(/^`+/)![0];
+      const end = text.indexOf(run, j + run.length);
+      if (end > 0) j = end + run.length - 1;
+      continue;
+    }
+    if (!text.startsWith(delimiter, j) || /\s/.test(text[j - 1])) continue;
+    // "*" must not close on the first half of a "**".
+    if (delimiter.length === 1 && text[j + 1] === delimiter && delimiter !== "^") {
+      j++;
+      continue;
+    }
+    if (delimiter[0] === "_" && /[\p{L}\p{N}]/u.test(text[j + delimiter.length] || "")) continue;
+    if (delimiter === "^" && /\s/.test(text.slice(start + 1, j))) return -1;
+    return j;
+  }
+  return -1;
+}
+
+/** Concatenated text of inline nodes, with markup dropped. */
+export function plainText(nodes: Inline[]): string {
+  return nodes
+    .map((node) => {
+      switch (node.kind) {
+        case "text":
+        case "code":
+          return node.text;
+        case "image":
+          return node.alt;
+        case "break":
+          return "\n";
+        case "html":
+          return "";
+        default:
+          return plainText(node.children);
+      }
+    })
+    .join("");
+}
@@ -0,0 +1,214 @@
+import { plainHeadingText } from './outline';
+import { countTokens, TokenEncoding } from './tokenizer';
+
+export interface ChunkOptions {
+  /** Upper bound on chunk length in characters (default 2000). */
+  maxChars?: number;
+  /** Upper bound on chunk length in tokens; applied in addition to maxChars. */
+  maxTokens?: number;
+  /** Encoding used for maxTokens and the per-chunk token counts (default cl100k_base). */
+  encoding?: TokenEncoding;
+  /** Characters of trailing context repeated at the start of a continuation chunk (default 200). */
+  overlap?: number;
+}
+
+export interface MarkdownChunk {
+  index: number;
+  content: string;
+  /** Titles of the enclosing headings, outermost first. */
+  headings: string[];
+  chars: number;
+  tokens: number;
+}
+
+type BlockKind = "heading" | "code" | "table" | "text";
+
+interface Block {
+  kind: BlockKind;
+  text: string;
+  level?: number;
+}
+
+/** Rough characters-per-token ratio, used only to size the overlap reserve. */
+const CHARS_PER_TOKEN = 4;
+
+/**
+ * Splits markdown into overlapping chunks for embedding. Chunks break at
+ * headings where possible, fenced code blocks are never split, and oversized
+ * tables are split by rows with the header repeated so each piece is still a
+ * valid table. Every chunk carries its heading breadcrumb.
+ */
+export function chunkMarkdown(markdown: string | null | undefined, options: ChunkOptions = {}): MarkdownChunk[] {
+  if (!markdown || !markdown.trim()) return [];
+
+  const maxChars = Math.max(100, options.maxChars ?? 2000);
+  const maxTokens = options.maxTokens;
+  const tokensOf = (text: string) => countTokens(text, options.encoding).tokens;
+  const overlap = Math.max(0, Math.min(options.overlap ?? 200, Math.floor(maxChars / 2)));
+  const fits = (text: string, reserve = 0) =>
+    text.length + reserve <= maxChars && (!maxTokens || tokensOf(text) + Math.ceil(reserve / CHARS_PER_TOKEN) <= maxTokens);
+  // Pieces of a split block leave room for the overlap carried into their chunk.
+  const fitsPiece = (text: string) => fits(text, overlap ? overlap + 2 : 0);
+
+  const chunks: MarkdownChunk[] = [];
+  const breadcrumb: string[] = [];
+  let current: string[] = [];
+  let currentHeadings: string[] = [];
+
+  const flush = () => {
+    const content = current.join("\n\n").trim();
+    if (content) {
+      chunks.push({ index: chunks.length, content, headings: [...currentHeadings], chars: content.length, tokens: tokensOf(content) });
+    }
+    current = [];
+  };
+
+  const tailFor = (text: string): string => {
+    if (!overlap) return "";
+    if (text.length <= overlap) return text;
+    const tail = text.slice(-overlap);
+    const sentence = tail.search(/(?<=[.!?。！？]\s)/);
+    const boundary = sentence >= 0 ? sentence : tail.search(/\s/);
+    return boundary >= 0 ? tail.slice(boundary).trim() : tail;
+  };
+
+  const append = (piece: string, kind: BlockKind) => {
+    const candidate = [...current, piece].join("\n\n");
+    if (current.length === 1 && isHeadingLine(current[0]) && !fits(candidate)) {
+      // A heading alone makes a useless chunk; the breadcrumb already has it.
+      current = [];
+    }
+    if (current.length && !fits(candidate)) {
+      const last = current[current.length - 1];
+      const lastIsText = !/^(?:`{3,}|~{3,}|\|)/.test(last);
+      flush();
+      const carried = lastIsText && kind === "text" ? tailFor(last) : "";
+      if (carried && fits(`${carried}\n\n${piece}`)) current.push(carried);
+    }
+    current.push(piece);
+  };
+
+  for (const block of parseBlocks(markdown)) {
+    if (block.kind === "heading") {
+      const level = block.level ?? 1;
+      breadcrumb.length = Math.min(breadcrumb.length, level - 1);
+      breadcrumb[level - 1] = plainHeadingText(block.text.replace(/^#{1,6}\s+/, ""));
+      flush();
+      currentHeadings = breadcrumb.filter(Boolean);
+      current.push(block.text);
+      continue;
+    }
+
+    for (const piece of splitOversized(block, fitsPiece)) append(piece, block.kind);
+  }
+  flush();
+
+  return chunks;
+}
+
+function isHeadingLine(text: string): boolean {
+  return /^#{1,6}\s/.test(text);
+}
+
+function parseBlocks(markdown: string): Block[] {
+  const blocks: Block[] = [];
+  const lines = markdown.split("\n");
+  let buffer: string[] = [];
+
+  const pushText = () => {
+    const text = buffer.join("\n").trim();
+    if (text) blocks.push({ kind: "text", text });
+    buffer = [];
+  };
+
+  for (let i = 0; i < lines.length; i++) {
+    const line = lines[i];
+
+    const fence = line.match(/^\s{0,3}(`{3,}|~{3,})/);
+    if (fence) {
+      pushText();
+      const code = [line];
+      while (++i < lines.length) {
+        code.push(lines[i]);
+        const close = lines[i].match(/^\s{0,3}(`{3,}|~{3,})\s*$/);
+        if (close && close[1][0] === fence[1][0] && close[1].length >= fence[1].length) break;
+      }
+      blocks.push({ kind: "code", text: code.join("\n") });
+      continue;
+    }
+
+    const heading = line.match(/^\s{0,3}(#{1,6})\s+\S/);
+    if (heading) {
+      pushText();
+      blocks.push({ kind: "heading", text: line.trim(), level: heading[1].length });
+      continue;
+    }
+
+    if (/^\s*\|/.test(line)) {
+      pushText();
+      const rows = [line];
+      while (i + 1 < lines.length && /^\s*\|/.test(lines[i + 1])) rows.push(lines[++i]);
+      blocks.push({ kind: "table", text: rows.join("\n") });
+      continue;
+    }
+
+    if (!line.trim()) pushText();
+    else buffer.push(line);
+  }
+  pushText();
+
+  return blocks;
+}
+
+/**
+ * Breaks a block that cannot fit in one chunk: tables by rows (repeating the
+ * header and delimiter rows), text by sentences and then words. Code blocks
+ * are returned whole even when oversized.
+ */
+function splitOversized(block: Block, fits: (text: string) => boolean): string[] {
+  if (fits(block.text) || block.kind === "code") return [block.text];
+
+  if (block.kind === "table") {
+    const rows = block.text.split("\n");
+    const hasHeader = rows.length > 2 && /^\s*\|?\s*:?-{3,}/.test(rows[1]);
+    const header = hasHeader ? rows.slice(0, 2) : [];
+    return packPieces(rows.slice(header.length), "\n", fits, header.join("\n"));
+  }
+
+  const sentences = block.text.match(/[^.!?。！？]+(?:[.!?。！？]+|$)\s*/g) || [block.text];
+  const units = sentences.flatMap((sentence) => (fits(sentence) ? [sentence.trim()] : hardWrap(sentence, fits)));
+  return packPieces(units, " ", fits, "");
+}
+
+function packPieces(units: string[], joiner: string, fits: (text: string) => boolean, prefix: string): string[] {
+  const pieces: string[] = [];
+  let current: string[] = [];
+  const render = (parts: string[]) => (prefix ? [prefix, ...parts] : parts).join(joiner);
+
+  for (const unit of units) {
+    if (current.length && !fits(render([...current, unit]))) {
+      pieces.push(render(current));
+      current = [];
+    }
+    current.push(unit);
+  }
+  if (current.length) pieces.push(render(current));
+  return pieces;
+}
+
+function hardWrap(text: string, fits: (text: string) => boolean): string[] {
+  const words = text.trim().split(/\s+/);
+  const lines: string[] = [];
+  let line = "";
+  for (const word of words) {
+    const next = line ? `${line} ${word}` : word;
+    if (line && !fits(next)) {
+      lines.push(line);
+      line = word;
+    } else {
+      line = next;
+    }
+  }
+  if (line) lines.push(line);
+  return lines;
+}
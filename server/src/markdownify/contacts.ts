@@ -0,0 +1,234 @@
+import * as cheerio from 'cheerio';
+import { URL } from 'url';
+import { documentBaseUrl, resolveUrl, safeDecode } from './urls';
+
+export interface PhoneNumber {
+  /** The number as written on the page. */
+  raw: string;
+  /** E.164 form ("+4930123456"), when the country is known or can be assumed. */
+  e164?: string;
+  /** ISO 3166 region the calling code belongs to, when it is unambiguous. */
+  country?: string;
+}
+
+export interface SocialProfile {
+  network: string;
+  url: string;
+  /** Account name taken from the URL path, without the "@". */
+  handle?: string;
+}
+
+export interface ContactInfo {
+  emails: string[];
+  phones: PhoneNumber[];
+  social: SocialProfile[];
+}
+
+export interface ContactOptions {
+  /** ISO 3166 region assumed for national-format numbers ("030 123456"). */
+  defaultCountry?: string;
+}
+
+/** Calling codes by region; shared codes (+1, +7) are not mapped back to a region. */
+const CALLING_CODES: Record<string, string> = {
+  US: "1", CA: "1", GB: "44", IE: "353", DE: "49", AT: "43", CH: "41", FR: "33", BE: "32", NL: "31",
+  LU: "352", ES: "34", PT: "351", IT: "39", PL: "48", CZ: "420", SK: "421", HU: "36", RO: "40", GR: "30",
+  SE: "46", NO: "47", DK: "45", FI: "358", IS: "354", RU: "7", UA: "380", TR: "90", IL: "972", AE: "971",
+  SA: "966", EG: "20", ZA: "27", NG: "234", KE: "254", IN: "91", PK: "92", BD: "880", CN: "86", HK: "852",
+  TW: "886", JP: "81", KR: "82", SG: "65", MY: "60", ID: "62", TH: "66", VN: "84", PH: "63", AU: "61",
+  NZ: "64", BR: "55", AR: "54", MX: "52", CL: "56", CO: "57", PE: "51",
+};
+const REGION_BY_CODE: Record<string, string> = {};
+for (const [region, code] of Object.entries(CALLING_CODES)) {
+  if (code !== "1" && code !== "7") REGION_BY_CODE[code] = region;
+}
+
+const SOCIAL_NETWORKS: { network: string; host: RegExp; profile: RegExp }[] = [
+  { network: "twitter", host: /^(?:mobile\.)?(?:twitter|x)\.com$/, profile: /^\/@?([A-Za-z0-9_]{1,15})\/?$/ },
+  { network: "facebook", host: /^(?:m\.|business\.)?facebook\.com$|^fb\.com$/, profile: /^\/(?!sharer|share|dialog|plugins|tr\b)([A-Za-z0-9.\-]+)\/?$/ },
+  { network: "instagram", host: /^instagram\.com$/, profile: /^\/(?!p\/|reel\/|explore\/)([A-Za-z0-9_.]+)\/?$/ },
+  { network: "linkedin", host: /^(?:[a-z]{2}\.)?linkedin\.com$/, profile: /^\/(?:in|company|school)\/([^/]+)\/?$/ },
+  { network: "youtube", host: /^(?:m\.)?youtube\.com$/, profile: /^\/(?:@([^/]+)|(?:c|user|channel)\/([^/]+))\/?$/ },
+  { network: "tiktok", host: /^tiktok\.com$/, profile: /^\/@([^/]+)\/?$/ },
+  { network: "github", host: /^github\.com$/, profile: /^\/(?!sponsors\/|orgs\/)([A-Za-z0-9\-]+)\/?$/ },
+  { network: "pinterest", host: /^(?:[a-z]{2}\.)?pinterest\.[a-z.]+$/, profile: /^\/(?!pin\/)([A-Za-z0-9_]+)\/?$/ },
+  { network: "threads", host: /^threads\.(?:net|com)$/, profile: /^\/@([^/]+)\/?$/ },
+  { network: "bluesky", host: /^bsky\.app$/, profile: /^\/profile\/([^/]+)\/?$/ },
+  { network: "telegram", host: /^(?:t\.me|telegram\.me)$/, profile: /^\/(?!share\/)([A-Za-z0-9_]{5,})\/?$/ },
+  { network: "whatsapp", host: /^wa\.me$/, profile: /^\/(\d{6,15})\/?$/ },
+];
+
+const EMAIL_RE = /[A-Za-z0-9._%+\-]+@(?:[A-Za-z0-9\-]+\.)+[A-Za-z]{2,24}/g;
+/**
+ * "name [at] example [dot] com", "name(at)example.com", "name AT example DOT
+ * com". Bare " at " / " dot " only count in their shouted form, since the
+ * lowercase words occur in ordinary prose.
+ */
+const OBFUSCATED_EMAIL_RE =
+  /([A-Za-z0-9._%+\-]+)(?:\s*[[({<]\s*(?:at|@)\s*[\])}>]\s*|\sAT\s)((?:[A-Za-z0-9\-]+(?:\s*[[({<]\s*(?:dot|\.)\s*[\])}>]\s*|\sDOT\s|\.))+[A-Za-z]{2,24})\b/g;
+const OBFUSCATED_DOT_RE = /\s*[[({<]\s*(?:dot|\.)\s*[\])}>]\s*|\sDOT\s/g;
+/** Spaces but not line breaks: a number never continues into the next cell or line. */
+const PHONE_RE = /(?:\+|\b00)?\(?\d[\d \t\u00a0().\-\/]{5,22}\d\b/g;
+const PHONE_LABEL_RE = /(?:tel|phone|call|fax|mobile|mob|cell|hotline|telefon|téléphone|teléfono|☎|📞)[^\d+]{0,12}$/i;
+/** Files and example domains that look like addresses but aren't. */
+const NOT_AN_EMAIL_RE = /\.(?:png|jpe?g|gif|webp|svg|css|js)$|@(?:example\.(?:com|org|net)|sentry\.io|2x\b)/i;
+
+/**
+ * Finds emails (mailto: links, plain text, "[at]"-obfuscated forms and
+ * Cloudflare-protected addresses), phone numbers (tel: links and labelled or
+ * internationally written numbers in text, normalised to E.164 where
+ * possible) and social profile links. Share buttons and post permalinks are
+ * not profiles and are skipped. Results are deduplicated in document order.
+ */
+export function extractContacts(
+  html: string | null | undefined,
+  baseUrl?: string | null,
+  options: ContactOptions = {}
+): ContactInfo {
+  const result: ContactInfo = { emails: [], phones: [], social: [] };
+  if (!html) return result;
+
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+  const emails = new Set<string>();
+  const phones = new Map<string, PhoneNumber>();
+  const profiles = new Map<string, SocialProfile>();
+
+  const addEmail = (value: string) => {
+    const email = value.trim().replace(/^mailto:/i, "").replace(/[.,;:]+$/, "").toLowerCase();
+    if (!/^[^@\s]+@[^@\s]+\.[a-z]{2,}$/.test(email) || NOT_AN_EMAIL_RE.test(email)) return;
+    emails.add(email);
+  };
+  const addPhone = (raw: string) => {
+    const phone = normalizePhone(raw, options.defaultCountry);
+    if (!phone) return;
+    const key = phone.e164 ?? phone.raw.replace(/\D/g, "");
+    if (!phones.has(key)) phones.set(key, phone);
+  };
+
+  $("[data-cfemail]").each((_i, el) => {
+    const decoded = decodeCloudflareEmail($(el).attr("data-cfemail") || "");
+    if (decoded) addEmail(decoded);
+  });
+
+  $("a[href]").each((_i, el) => {
+    const href = ($(el).attr("href") || "").trim();
+    if (/^mailto:/i.test(href)) {
+      for (const address of safeDecode(href.slice(7).split("?")[0]).split(",")) addEmail(address);
+      return;
+    }
+    if (/^(?:tel|callto):/i.test(href)) {
+      addPhone(safeDecode(href.replace(/^(?:tel|callto):/i, "")));
+      return;
+    }
+    const profile = socialProfile(resolveUrl(href, base));
+    if (profile && !profiles.has(profile.url)) profiles.set(profile.url, profile);
+  });
+
+  $("script, style, noscript, template").remove();
+  const text = pageText($);
+
+  for (const m of text.matchAll(EMAIL_RE)) addEmail(m[0]);
+  for (const m of text.matchAll(OBFUSCATED_EMAIL_RE)) {
+    addEmail(`${m[1]}@${m[2].replace(OBFUSCATED_DOT_RE, ".")}`);
+  }
+
+  for (const m of text.matchAll(PHONE_RE)) {
+    const raw = m[0].trim();
+    const international = /^(?:\+|00)/.test(raw);
+    const labelled = PHONE_LABEL_RE.test(text.slice(Math.max(0, (m.index ?? 0) - 24), m.index));
+    const nanp = /^\(\d{3}\)\s?\d{3}[-.\s]\d{4}$/.test(raw);
+    if (international || labelled || nanp) addPhone(raw);
+  }
+
+  result.emails = [...emails];
+  result.phones = [...phones.values()];
+  result.social = [...profiles.values()];
+  return result;
+}
+
+/** Block-like elements that separate text visually without any whitespace in the markup. */
+const TEXT_BREAK_SELECTOR =
+  "p, div, section, article, header, footer, address, li, dd, dt, td, th, tr, caption, h1, h2, h3, h4, h5, h6, blockquote, pre";
+
+/**
+ * The page's text with a line break wherever rendering would put one, so
+ * that adjacent cells ("<td>+49 30 1234</td><td>5678</td>") don't run
+ * together into one number or address.
+ */
+function pageText($: cheerio.CheerioAPI): string {
+  const $root = $("body").length ? $("body") : $.root();
+  $root.find("br").replaceWith("\n");
+  $root.find(TEXT_BREAK_SELECTOR).each((_i, el) => {
+    $(el).prepend("\n").append("\n");
+  });
+  return $root.text();
+}
+
+/**
+ * Normalises a phone number to E.164. "+" and "00" prefixes carry their own
+ * calling code; a national number needs `defaultCountry`, and loses its trunk
+ * "0" on the way (except in Italy, where it is part of the number).
+ */
+function normalizePhone(raw: string, defaultCountry?: string): PhoneNumber | null {
+  const cleaned = raw.replace(/\s*(?:ext\.?|x|#)\s*\d+$/i, "").trim();
+  // Dates and ranges such as "2024-05-01" or "1990-2010" are not phone numbers.
+  if (/^\d{4}[-/.]\d{1,2}[-/.]\d{1,2}$|^\d{4}\s?[-–]\s?\d{4}$/.test(cleaned)) return null;
+  let digits = cleaned.replace(/\(0\)/, "").replace(/\D/g, "");
+  if (digits.length < 7 || digits.length > 15) return null;
+
+  const phone: PhoneNumber = { raw: raw.trim() };
+  let international = false;
+  if (cleaned.startsWith("+")) international = true;
+  else if (cleaned.startsWith("00")) {
+    digits = digits.slice(2);
+    international = true;
+  }
+
+  if (international) {
+    phone.e164 = `+${digits}`;
+    const code = [3, 2, 1].map((n) => digits.slice(0, n)).find((prefix) => REGION_BY_CODE[prefix]);
+    if (code) phone.country = REGION_BY_CODE[code];
+    return phone;
+  }
+
+  const region = defaultCountry?.toUpperCase();
+  const code = region ? CALLING_CODES[region] : undefined;
+  if (!code) return phone;
+  if (code === "1" && digits.length === 11 && digits.startsWith("1")) digits = digits.slice(1);
+  else if (region !== "IT") digits = digits.replace(/^0/, "");
+  phone.e164 = `+${code}${digits}`;
+  phone.country = region;
+  return phone;
+}
+
+function socialProfile(url: string): SocialProfile | null {
+  let parsed: URL;
+  try {
+    parsed = new URL(url);
+  } catch {
+    return null;
+  }
+  if (!/^https?:$/.test(parsed.protocol)) return null;
+  const host = parsed.hostname.toLowerCase().replace(/^www\./, "");
+
+  for (const { network, host: hostRe, profile } of SOCIAL_NETWORKS) {
+    if (!hostRe.test(host)) continue;
+    const m = parsed.pathname.match(profile);
+    if (!m) return null;
+    const handle = m.slice(1).find(Boolean);
+    const clean: SocialProfile = { network, url: `https://${host}${parsed.pathname.replace(/\/+$/, "")}` };
+    if (handle) clean.handle = safeDecode(handle);
+    return clean;
+  }
+  return null;
+}
+
+/** Cloudflare's email obfuscation: hex bytes XORed with the first byte. */
+function decodeCloudflareEmail(hex: string): string | null {
+  if (!/^(?:[0-9a-f]{2}){2,}$/i.test(hex)) return null;
+  const key = parseInt(hex.slice(0, 2), 16);
+  let out = "";
+  for (let i = 2; i < hex.length; i += 2) out += String.fromCharCode(parseInt(hex.slice(i, i + 2), 16) ^ key);
+  return out;
+}
@@ -0,0 +1,236 @@
+import * as cheerio from 'cheerio';
+import { extractStructuredData } from './structured-data';
+
+export interface ArticleDates {
+  /** ISO-8601 publish timestamp. */
+  published?: string;
+  /** ISO-8601 last-modified timestamp. */
+  modified?: string;
+}
+
+export interface DateOptions {
+  /** Reference time for relative dates like "3 days ago" (default: now). */
+  now?: Date;
+}
+
+const PUBLISHED_META = [
+  "article:published_time", "og:published_time", "datepublished", "pubdate", "publishdate", "publish-date",
+  "publication_date", "date", "dc.date", "dc.date.issued", "dcterms.issued", "dcterms.created",
+  "sailthru.date", "parsely-pub-date", "citation_publication_date",
+];
+const MODIFIED_META = [
+  "article:modified_time", "og:updated_time", "datemodified", "last-modified", "dcterms.modified", "dc.date.modified",
+];
+
+/** Elements that usually hold the byline date on article pages. */
+const BYLINE_SELECTOR = [
+  '[class*="date" i]', '[class*="posted" i]', '[class*="published" i]', '[class*="byline" i]',
+  '[class*="timestamp" i]', '[id*="date" i]', '[itemprop="datePublished"]',
+].join(",");
+
+const MONTHS: Record<string, number> = {
+  jan: 0, january: 0, feb: 1, february: 1, mar: 2, march: 2, apr: 3, april: 3, may: 4, jun: 5, june: 5,
+  jul: 6, july: 6, aug: 7, august: 7, sep: 8, sept: 8, september: 8, oct: 9, october: 9, nov: 10,
+  november: 10, dec: 11, december: 11,
+  // German, French and Spanish names that differ from the English ones.
+  januar: 0, februar: 1, märz: 2, mai: 4, juni: 5, juli: 6, oktober: 9, dezember: 11,
+  janvier: 0, février: 1, mars: 2, avril: 3, juin: 5, juillet: 6, août: 7, septembre: 8, octobre: 9,
+  novembre: 10, décembre: 11, enero: 0, febrero: 1, marzo: 2, abril: 3, mayo: 4, junio: 5, julio: 6,
+  agosto: 7, septiembre: 8, octubre: 9, noviembre: 10, diciembre: 11,
+};
+const MONTH_NAME = Object.keys(MONTHS).sort((a, b) => b.length - a.length).join("|");
+
+const UNITS: Record<string, "s" | "min" | "h" | "d" | "w" | "mo" | "y"> = {
+  second: "s", sec: "s", s: "s", minute: "min", min: "min", m: "min", hour: "h", hr: "h", h: "h",
+  day: "d", d: "d", week: "w", wk: "w", w: "w", month: "mo", mo: "mo", year: "y", yr: "y", y: "y",
+  "秒": "s", "分": "min", "分钟": "min", "小时": "h", "時間": "h", "天": "d", "日": "d", "周": "w", "週間": "w",
+  "个月": "mo", "ヶ月": "mo", "か月": "mo", "年": "y",
+};
+const UNIT_MS = { s: 1e3, min: 6e4, h: 36e5, d: 864e5, w: 6048e5 };
+
+const ISO_RE = /(\d{4})-(\d{2})-(\d{2})(?:[T\s](\d{2}):(\d{2})(?::(\d{2})(?:\.(\d+))?)?\s*(Z|[+-]\d{2}:?\d{2})?)?/i;
+const CJK_RE = /(\d{4})\s*[年년]\s*(\d{1,2})\s*[月월]\s*(\d{1,2})\s*[日일]?(?:\s*(\d{1,2})\s*[:時시]\s*(\d{2}))?/;
+const YMD_RE = /\b(\d{4})[/.](\d{1,2})[/.](\d{1,2})\b/;
+const MDY_RE = new RegExp(`\\b(${MONTH_NAME})\\.?\\s+(\\d{1,2})(?:st|nd|rd|th)?,?\\s+(\\d{4})\\b`, "iu");
+const DMY_RE = new RegExp(`\\b(\\d{1,2})(?:st|nd|rd|th|\\.)?\\s+(?:de\\s+)?(${MONTH_NAME})\\.?,?\\s+(?:de\\s+)?(\\d{4})\\b`, "iu");
+const TIME_RE = /\b(\d{1,2}):(\d{2})(?::(\d{2}))?\s*([ap]\.?m\.?)?(?:\s*(UTC|GMT|Z|[+-]\d{2}:?\d{2}))?/i;
+const RELATIVE_RE = /\b(\d+|an?|one)\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?|days?|weeks?|wks?|months?|mos?|years?|yrs?|[smhdwy])\s+ago\b/i;
+const CJK_RELATIVE_RE = /(\d+)\s*(分钟|小时|時間|週間|个月|ヶ月|か月|秒|分|天|日|周|年)前/;
+const URL_DATE_RE = /\/((?:19|20)\d{2})[/-](0[1-9]|1[0-2])[/-](0[1-9]|[12]\d|3[01])(?:\/|$)/;
+
+/**
+ * Finds an article's publish and last-modified dates. Sources are tried in
+ * order of trust: JSON-LD/microdata, meta tags, <time> elements, then dates
+ * written out in the byline ("May 1, 2024", "2024年5月1日", "3 days ago")
+ * and finally a /2024/05/01/ segment in the page URL.
+ */
+export function extractDates(
+  html: string | null | undefined,
+  baseUrl?: string | null,
+  options: DateOptions = {}
+): ArticleDates {
+  const result: ArticleDates = {};
+  if (!html) return result;
+
+  const now = options.now ?? new Date();
+  const $ = cheerio.load(html);
+
+  let published: string | undefined;
+  let modified: string | undefined;
+  for (const item of extractStructuredData(html, baseUrl)) {
+    published = published ?? firstDate(item.data.datePublished ?? item.data.dateCreated, now);
+    modified = modified ?? firstDate(item.data.dateModified, now);
+  }
+
+  const meta = (names: string[]) => {
+    for (const name of names) {
+      const value = $(`meta[property="${name}" i], meta[name="${name}" i], meta[itemprop="${name}" i], meta[http-equiv="${name}" i]`)
+        .first()
+        .attr("content");
+      const date = parseDate(value, { now });
+      if (date) return date;
+    }
+    return undefined;
+  };
+  published = published ?? meta(PUBLISHED_META);
+  modified = modified ?? meta(MODIFIED_META);
+
+  if (!published) {
+    // <time pubdate> and itemprop beat the first <time> on the page, which may be a comment's.
+    const times = $("time[datetime]").toArray();
+    const preferred = times.find((el) => $(el).is('[pubdate], [itemprop="datePublished"]')) ?? times.find((el) => $(el).closest("article, header").length) ?? times[0];
+    if (preferred) published = parseDate($(preferred).attr("datetime"), { now }) ?? parseDate($(preferred).text(), { now });
+  }
+
+  if (!published) {
+    $(BYLINE_SELECTOR).each((_i, el) => {
+      const text = $(el).text().replace(/\s+/g, " ").trim();
+      if (!text || text.length > 200) return;
+      published = parseDate(text, { now });
+      if (published) return false;
+    });
+  }
+
+  if (!published && baseUrl) {
+    const m = baseUrl.match(URL_DATE_RE);
+    if (m) published = utc(+m[1], +m[2] - 1, +m[3]);
+  }
+
+  if (published) result.published = published;
+  if (modified) result.modified = modified;
+  return result;
+}
+
+/**
+ * Parses one date string into an ISO-8601 timestamp: ISO and RFC 2822 forms,
+ * written-out English/German/French/Spanish dates, CJK dates, year-first
+ * numeric dates and relative phrases. Day/month-ambiguous forms such as
+ * "01/05/2024" are rejected rather than guessed. Dates without a zone are
+ * taken as UTC.
+ */
+export function parseDate(text: string | null | undefined, options: DateOptions = {}): string | undefined {
+  if (!text || !text.trim()) return undefined;
+  const value = text.trim();
+  const now = options.now ?? new Date();
+
+  const iso = value.match(ISO_RE);
+  if (iso) {
+    const [, y, mo, d, h = "0", mi = "0", s = "0", frac = "0", zone] = iso;
+    const ms = calendar(+y, +mo - 1, +d, +h, +mi, +s, Math.round(Number(`0.${frac}`) * 1000));
+    return plausible(ms - zoneOffset(zone), now);
+  }
+
+  const cjk = value.match(CJK_RE);
+  if (cjk) return plausible(calendar(+cjk[1], +cjk[2] - 1, +cjk[3], +(cjk[4] ?? 0), +(cjk[5] ?? 0)), now);
+
+  const ymd = value.match(YMD_RE);
+  if (ymd) return plausible(calendar(+ymd[1], +ymd[2] - 1, +ymd[3]), now);
+
+  const relative = parseRelative(value, now);
+  if (relative) return relative;
+
+  const mdy = value.match(MDY_RE);
+  const written = mdy ?? value.match(DMY_RE);
+  if (written) {
+    const [month, day, year] = mdy ? [mdy[1], mdy[2], mdy[3]] : [written[2], written[1], written[3]];
+    let ms = calendar(+year, MONTHS[month.toLowerCase()], +day);
+    // A time of day may follow: "May 1, 2024 at 10:30 am".
+    const time = value.slice((written.index ?? 0) + written[0].length).match(TIME_RE);
+    if (time) {
+      let hours = +time[1] % (time[4] ? 12 : 24);
+      if (time[4] && /^p/i.test(time[4])) hours += 12;
+      ms += hours * UNIT_MS.h + +time[2] * UNIT_MS.min + +(time[3] ?? 0) * UNIT_MS.s - zoneOffset(time[5]);
+    }
+    return plausible(ms, now);
+  }
+
+  // RFC 2822 ("Wed, 01 May 2024 10:00:00 GMT") and whatever else Date understands.
+  if (/\d{4}/.test(value) && /[a-z]/i.test(value)) {
+    const ms = Date.parse(value);
+    if (!Number.isNaN(ms)) return plausible(ms, now);
+  }
+  return undefined;
+}
+
+function parseRelative(value: string, now: Date): string | undefined {
+  if (/\b(just now|moments? ago)\b|刚刚|たった今/i.test(value)) return now.toISOString();
+  if (/\btoday\b|今天|今日/i.test(value)) return utc(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate());
+  if (/\byesterday\b|昨天|昨日/i.test(value)) return utc(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate() - 1);
+
+  let amount: number;
+  let unit: string;
+  const en = value.match(RELATIVE_RE);
+  const zh = en ? null : value.match(CJK_RELATIVE_RE);
+  if (en) {
+    amount = /^\d+$/.test(en[1]) ? +en[1] : 1;
+    const word = en[2].toLowerCase();
+    unit = UNITS[word] ?? UNITS[word.replace(/s$/, "")];
+  } else if (zh) {
+    amount = +zh[1];
+    unit = UNITS[zh[2]];
+  } else {
+    return undefined;
+  }
+
+  const date = new Date(now.getTime());
+  if (unit === "mo") date.setUTCMonth(date.getUTCMonth() - amount);
+  else if (unit === "y") date.setUTCFullYear(date.getUTCFullYear() - amount);
+  else date.setTime(date.getTime() - amount * UNIT_MS[unit as keyof typeof UNIT_MS]);
+  return date.toISOString();
+}
+
+function firstDate(value: unknown, now: Date): string | undefined {
+  const list = Array.isArray(value) ? value : [value];
+  for (const v of list) {
+    const date = typeof v === "string" ? parseDate(v, { now }) : undefined;
+    if (date) return date;
+  }
+  return undefined;
+}
+
+function zoneOffset(zone: string | undefined): number {
+  if (!zone || /^(z|utc|gmt)$/i.test(zone)) return 0;
+  const m = zone.match(/^([+-])(\d{2}):?(\d{2})$/);
+  if (!m) return 0;
+  return (m[1] === "-" ? -1 : 1) * (+m[2] * UNIT_MS.h + +m[3] * UNIT_MS.min);
+}
+
+function utc(year: number, month: number, day: number): string {
+  return new Date(Date.UTC(year, month, day)).toISOString();
+}
+
+/** Date.UTC that returns NaN instead of rolling "February 30" over into March. */
+function calendar(year: number, month: number, day: number, hours = 0, minutes = 0, seconds = 0, ms = 0): number {
+  const value = Date.UTC(year, month, day, hours, minutes, seconds, ms);
+  const date = new Date(value);
+  if (date.getUTCMonth() !== month || date.getUTCDate() !== day || hours > 23 || minutes > 59 || seconds > 60) return NaN;
+  return value;
+}
+
+/** Rejects nonsense such as month 13 or a "publish date" years in the future. */
+function plausible(ms: number, now: Date): string | undefined {
+  if (!Number.isFinite(ms)) return undefined;
+  const date = new Date(ms);
+  if (date.getUTCFullYear() < 1900 || ms > now.getTime() + UNIT_MS.d) return undefined;
+  return date.toISOString();
+}
@@ -0,0 +1,269 @@
+import { plainHeadingText } from './outline';
+
+export type BlockChangeType = "added" | "removed" | "changed";
+
+export interface BlockChange {
+  type: BlockChangeType;
+  /** Breadcrumb of the section the block sits in, outermost first. */
+  headings: string[];
+  /** Block text in the old document (removed and changed blocks). */
+  before?: string;
+  /** Block text in the new document (added and changed blocks). */
+  after?: string;
+}
+
+export interface MarkdownDiff {
+  changes: BlockChange[];
+  /** Line-level unified diff of the two documents, empty when they match. */
+  unified: string;
+  stats: { added: number; removed: number; changed: number };
+}
+
+export interface DiffOptions {
+  /** Lines of context around each unified-diff hunk (default 3). */
+  context?: number;
+  /** Word-overlap ratio at which a removed/added pair counts as one changed block (default 0.5). */
+  similarity?: number;
+}
+
+interface Block {
+  text: string;
+  /** Whitespace-normalised text used for equality. */
+  key: string;
+  headings: string[];
+}
+
+type Op = { kind: "equal" | "delete" | "insert"; a: number; b: number };
+
+/**
+ * Compares two markdown documents block by block — paragraphs, list runs,
+ * tables, code blocks, headings — so a monitor robot can report "the Pricing
+ * section changed" instead of a wall of line noise. Re-wrapped or re-spaced
+ * text is not a change. A unified line diff is included for display.
+ */
+export function diffMarkdown(
+  oldMarkdown: string | null | undefined,
+  newMarkdown: string | null | undefined,
+  options: DiffOptions = {}
+): MarkdownDiff {
+  const oldBlocks = splitBlocks(oldMarkdown || "");
+  const newBlocks = splitBlocks(newMarkdown || "");
+  const similarity = options.similarity ?? 0.5;
+
+  const changes: BlockChange[] = [];
+  const ops = diffSequences(oldBlocks.map((b) => b.key), newBlocks.map((b) => b.key));
+
+  for (let i = 0; i < ops.length; ) {
+    if (ops[i].kind === "equal") {
+      i++;
+      continue;
+    }
+    // Collect one run of deletions and insertions and pair similar blocks up as edits.
+    const removed: Block[] = [];
+    const added: Block[] = [];
+    for (; i < ops.length && ops[i].kind !== "equal"; i++) {
+      if (ops[i].kind === "delete") removed.push(oldBlocks[ops[i].a]);
+      else added.push(newBlocks[ops[i].b]);
+    }
+
+    let next = 0;
+    for (const before of removed) {
+      const match = added.findIndex((after, j) => j >= next && wordSimilarity(before.key, after.key) >= similarity);
+      if (match < 0) {
+        changes.push({ type: "removed", headings: before.headings, before: before.text });
+        continue;
+      }
+      for (const after of added.slice(next, match)) changes.push({ type: "added", headings: after.headings, after: after.text });
+      const after = added[match];
+      changes.push({ type: "changed", headings: after.headings, before: before.text, after: after.text });
+      next = match + 1;
+    }
+    for (const after of added.slice(next)) changes.push({ type: "added", headings: after.headings, after: after.text });
+  }
+
+  const stats = { added: 0, removed: 0, changed: 0 };
+  for (const change of changes) stats[change.type]++;
+
+  return { changes, unified: unifiedDiff(oldMarkdown || "", newMarkdown || "", options.context ?? 3), stats };
+}
+
+function splitBlocks(markdown: string): Block[] {
+  const blocks: Block[] = [];
+  const breadcrumb: string[] = [];
+  const lines = markdown.replace(/\r\n?/g, "\n").split("\n");
+  let buffer: string[] = [];
+
+  const push = (text: string, isCode = false) => {
+    const trimmed = text.replace(/^\n+|\s+$/g, "");
+    if (!trimmed) return;
+    const key = isCode ? trimmed : trimmed.replace(/\s+/g, " ").trim();
+    blocks.push({ text: trimmed, key, headings: breadcrumb.filter(Boolean) });
+  };
+  const flush = () => {
+    push(buffer.join("\n"));
+    buffer = [];
+  };
+
+  for (let i = 0; i < lines.length; i++) {
+    const line = lines[i];
+
+    const fence = line.match(/^\s{0,3}(`{3,}|~{3,})/);
+    if (fence) {
+      flush();
+      const code = [line];
+      while (++i < lines.length) {
+        code.push(lines[i]);
+        const close = lines[i].match(/^\s{0,3}(`{3,}|~{3,})\s*$/);
+        if (close && close[1][0] === fence[1][0] && close[1].length >= fence[1].length) break;
+      }
+      push(code.join("\n"), true);
+      continue;
+    }
+
+    const heading = line.match(/^\s{0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$/);
+    if (heading) {
+      flush();
+      const level = heading[1].length;
+      breadcrumb.length = Math.min(breadcrumb.length, level - 1);
+      breadcrumb[level - 1] = plainHeadingText(heading[2]);
+      push(line);
+      continue;
+    }
+
+    if (!line.trim()) flush();
+    else buffer.push(line);
+  }
+  flush();
+
+  return blocks;
+}
+
+function wordSimilarity(a: string, b: string): number {
+  const wa = new Set(a.toLowerCase().match(/[\p{L}\p{N}]+/gu) || []);
+  const wb = new Set(b.toLowerCase().match(/[\p{L}\p{N}]+/gu) || []);
+  if (!wa.size && !wb.size) return 1;
+  let shared = 0;
+  for (const w of wa) if (wb.has(w)) shared++;
+  return shared / Math.max(wa.size, wb.size);
+}
+
+/**
+ * Myers' O(ND) shortest edit script. Common prefix and suffix are trimmed
+ * first, and only the live diagonal band is kept per step, so memory grows
+ * with the size of the change rather than the size of the documents.
+ */
+function diffSequences(a: string[], b: string[]): Op[] {
+  let start = 0;
+  while (start < a.length && start < b.length && a[start] === b[start]) start++;
+  let endA = a.length;
+  let endB = b.length;
+  while (endA > start && endB > start && a[endA - 1] === b[endB - 1]) {
+    endA--;
+    endB--;
+  }
+
+  const ops: Op[] = [];
+  for (let i = 0; i < start; i++) ops.push({ kind: "equal", a: i, b: i });
+  ops.push(...myers(a.slice(start, endA), b.slice(start, endB)).map((op) => ({ ...op, a: op.a + start, b: op.b + start })));
+  for (let i = 0; endA + i < a.length; i++) ops.push({ kind: "equal", a: endA + i, b: endB + i });
+  return ops;
+}
+
+function myers(a: string[], b: string[]): Op[] {
+  const n = a.length;
+  const m = b.length;
+  if (!n) return b.map((_v, j) => ({ kind: "insert", a: 0, b: j }));
+  if (!m) return a.map((_v, i) => ({ kind: "delete", a: i, b: 0 }));
+
+  const max = n + m;
+  const offset = max + 1;
+  const v = new Int32Array(2 * max + 3);
+  const trace: Int32Array[] = [];
+
+  let done = false;
+  for (let d = 0; d <= max && !done; d++) {
+    trace.push(v.slice(offset - d - 1, offset + d + 2));
+    for (let k = -d; k <= d; k += 2) {
+      let x = k === -d || (k !== d && v[offset + k - 1] < v[offset + k + 1]) ? v[offset + k + 1] : v[offset + k - 1] + 1;
+      let y = x - k;
+      while (x < n && y < m && a[x] === b[y]) {
+        x++;
+        y++;
+      }
+      v[offset + k] = x;
+      if (x >= n && y >= m) {
+        done = true;
+        break;
+      }
+    }
+  }
+
+  const ops: Op[] = [];
+  let x = n;
+  let y = m;
+  for (let d = trace.length - 1; d >= 0; d--) {
+    // trace[d] holds v[-d-1 .. d+1] as it was before step d.
+    const at = (k: number) => trace[d][k + d + 1];
+    const k = x - y;
+    const prevK = k === -d || (k !== d && at(k - 1) < at(k + 1)) ? k + 1 : k - 1;
+    const prevX = d === 0 ? 0 : at(prevK);
+    const prevY = prevX - prevK;
+    while (x > prevX && y > prevY) {
+      ops.push({ kind: "equal", a: --x, b: --y });
+    }
+    if (d === 0) break;
+    if (x === prevX) ops.push({ kind: "insert", a: x, b: --y });
+    else ops.push({ kind: "delete", a: --x, b: y });
+  }
+  return ops.reverse();
+}
+
+/**
+ * Standard unified diff. Hunk headers carry the nearest preceding heading,
+ * the way git shows the enclosing function, so hunks are easy to place.
+ */
+function unifiedDiff(oldText: string, newText: string, context: number): string {
+  const a = oldText.replace(/\r\n?/g, "\n").split("\n");
+  const b = newText.replace(/\r\n?/g, "\n").split("\n");
+  const ops = diffSequences(a, b);
+  if (ops.every((op) => op.kind === "equal")) return "";
+
+  const out = ["--- a", "+++ b"];
+  let i = 0;
+  while (i < ops.length) {
+    while (i < ops.length && ops[i].kind === "equal") i++;
+    if (i >= ops.length) break;
+
+    // Extend the hunk while the gap between changes is within twice the context.
+    const hunkStart = Math.max(0, i - context);
+    let end = i;
+    for (let j = i; j < ops.length; j++) {
+      if (ops[j].kind !== "equal") end = j;
+      else if (j - end > context * 2) break;
+    }
+    const hunkEnd = Math.min(ops.length, end + context + 1);
+    const hunk = ops.slice(hunkStart, hunkEnd);
+
+    const first = hunk[0];
+    const oldCount = hunk.filter((op) => op.kind !== "insert").length;
+    const newCount = hunk.filter((op) => op.kind !== "delete").length;
+    const oldStart = oldCount ? first.a + 1 : first.a;
+    const newStart = newCount ? first.b + 1 : first.b;
+    const section = nearestHeading(a, first.a);
+    out.push(`@@ -${oldStart},${oldCount} +${newStart},${newCount} @@${section ? ` ${section}` : ""}`);
+    for (const op of hunk) {
+      if (op.kind === "equal") out.push(` ${a[op.a]}`);
+      else if (op.kind === "delete") out.push(`-${a[op.a]}`);
+      else out.push(`+${b[op.b]}`);
+    }
+    i = hunkEnd;
+  }
+  return out.join("\n") + "\n";
+}
+
+function nearestHeading(lines: string[], before: number): string {
+  for (let i = Math.min(before, lines.length) - 1; i >= 0; i--) {
+    if (/^\s{0,3}#{1,6}\s+\S/.test(lines[i])) return lines[i].trim();
+  }
+  return "";
+}
@@ -0,0 +1,357 @@
+import * as cheerio from 'cheerio';
+import * as path from 'path';
+import JSZip from 'jszip';
+import { ConversionError, ConversionErrorCode } from './errors';
+import { DEFAULT_MEMORY_LIMIT_BYTES } from './limits';
+import { convertFragment, MarkdownOptions } from './markdown';
+import { createZipReader } from './zip';
+
+export interface DocxImage {
+  /** Relationship id the document body references the image by. */
+  id: string;
+  /** Path inside the .docx archive, e.g. "word/media/image1.png"; also the markdown src. */
+  path: string;
+  contentType: string;
+  alt?: string;
+  /** Display size in CSS pixels, from the drawing extent. */
+  width?: number;
+  height?: number;
+  /** Raw bytes, only when `includeImageData` is set. */
+  data?: Buffer;
+}
+
+export interface DocxConversionResult {
+  markdown: string;
+  title?: string;
+  author?: string;
+  images: DocxImage[];
+}
+
+export interface DocxConversionOptions extends MarkdownOptions {
+  includeImageData?: boolean;
+}
+
+interface DocxContext {
+  $: cheerio.CheerioAPI;
+  headingLevels: Map<string, number>;
+  quoteStyles: Set<string>;
+  listFormats: Map<string, string[]>;
+  rels: Map<string, { target: string; external: boolean }>;
+  images: Map<string, DocxImage>;
+}
+
+/** English Metric Units per CSS pixel (914400 per inch, 96 px per inch). */
+const EMU_PER_PX = 9525;
+
+const IMAGE_TYPES: Record<string, string> = {
+  ".png": "image/png", ".jpg": "image/jpeg", ".jpeg": "image/jpeg", ".gif": "image/gif",
+  ".bmp": "image/bmp", ".tif": "image/tiff", ".tiff": "image/tiff", ".svg": "image/svg+xml",
+  ".emf": "image/emf", ".wmf": "image/wmf", ".webp": "image/webp",
+};
+
+/**
+ * Converts a .docx file to markdown by mapping WordprocessingML onto plain
+ * semantic HTML — heading styles to <hN>, numbering to <ul>/<ol>, tables,
+ * hyperlinks, bold/italic/strike runs — and running that through the same
+ * converter as web pages, so escaping and options behave identically. A file
+ * whose parts unpack past the limits of createZipReader fails with INPUT_TOO_LARGE.
+ */
+export async function convertDocxToMarkdown(
+  buffer: Buffer,
+  options: DocxConversionOptions = {}
+): Promise<DocxConversionResult> {
+  let zip: JSZip;
+  try {
+    zip = await JSZip.loadAsync(buffer);
+  } catch (err) {
+    throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not a Word document: the file is not a zip archive", { cause: err });
+  }
+  // Unpacked before any conversion checks maxMemoryBytes, so the archive is held to it here.
+  const entries = createZipReader(zip, options.maxMemoryBytes ?? DEFAULT_MEMORY_LIMIT_BYTES);
+  const read = entries.text;
+
+  const documentXml = await read("word/document.xml");
+  if (!documentXml) throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not a Word document: word/document.xml is missing");
+
+  const $ = cheerio.load(documentXml, { xml: true });
+  const ctx: DocxContext = {
+    $,
+    ...parseStyles(await read("word/styles.xml")),
+    listFormats: parseNumbering(await read("word/numbering.xml")),
+    rels: parseRels(await read("word/_rels/document.xml.rels")),
+    images: new Map(),
+  };
+
+  const body = $("w\\:body").first();
+  const html = renderBlocks(ctx, body.children().toArray());
+  const { includeImageData, ...markdownOptions } = options;
+  const markdown = await convertFragment(`<body>${html}</body>`, "body", null, markdownOptions);
+
+  const images = [...ctx.images.values()];
+  if (includeImageData) {
+    for (const image of images) {
+      const data = await entries.bytes(image.path);
+      if (data) image.data = data;
+    }
+  }
+
+  const result: DocxConversionResult = { markdown, images };
+  const core = cheerio.load(await read("docProps/core.xml"), { xml: true });
+  const title = core("dc\\:title").first().text().trim();
+  const author = core("dc\\:creator").first().text().trim();
+  if (title) result.title = title;
+  if (author) result.author = author;
+  return result;
+}
+
+/**
+ * Heading levels come from each style's outline level, or failing that its
+ * name ("heading 2", "Title"); custom styles based on a heading inherit it.
+ */
+function parseStyles(xml: string): { headingLevels: Map<string, number>; quoteStyles: Set<string> } {
+  const headingLevels = new Map<string, number>();
+  const quoteStyles = new Set<string>();
+  if (!xml) return { headingLevels, quoteStyles };
+
+  const $ = cheerio.load(xml, { xml: true });
+  const basedOn = new Map<string, string>();
+  $('w\\:style[w\\:type="paragraph"]').each((_i, el) => {
+    const $style = $(el);
+    const id = $style.attr("w:styleId") || "";
+    const name = ($style.children("w\\:name").attr("w:val") || "").toLowerCase();
+    const outline = $style.find("w\\:pPr > w\\:outlineLvl").attr("w:val");
+    const parent = $style.children("w\\:basedOn").attr("w:val");
+    if (parent) basedOn.set(id, parent);
+
+    const named = name.match(/^heading\s*(\d)$/);
+    const level = outlineHeading(outline);
+    if (level) headingLevels.set(id, level);
+    else if (named) headingLevels.set(id, Math.min(6, Number(named[1])));
+    else if (name === "title") headingLevels.set(id, 1);
+    if (/quote/.test(name)) quoteStyles.add(id);
+  });
+
+  for (const [id] of basedOn) {
+    let cursor: string | undefined = id;
+    for (let depth = 0; cursor && depth < 10 && !headingLevels.has(id); depth++) {
+      cursor = basedOn.get(cursor);
+      if (cursor && headingLevels.has(cursor)) headingLevels.set(id, headingLevels.get(cursor)!);
+    }
+  }
+  return { headingLevels, quoteStyles };
+}
+
+/** Outline levels are 0-based; 9 means body text. */
+function outlineHeading(value: string | undefined): number | undefined {
+  const level = Number(value);
+  return value !== undefined && level >= 0 && level < 6 ? level + 1 : undefined;
+}
+
+/** numId -> number format per indent level ("bullet", "decimal", ...). */
+function parseNumbering(xml: string): Map<string, string[]> {
+  const formats = new Map<string, string[]>();
+  if (!xml) return formats;
+
+  const $ = cheerio.load(xml, { xml: true });
+  const abstract = new Map<string, string[]>();
+  $("w\\:abstractNum").each((_i, el) => {
+    const levels: string[] = [];
+    $(el).children("w\\:lvl").each((_j, lvl) => {
+      levels[Number($(lvl).attr("w:ilvl") || 0)] = $(lvl).children("w\\:numFmt").attr("w:val") || "decimal";
+    });
+    abstract.set($(el).attr("w:abstractNumId") || "", levels);
+  });
+  $("w\\:num").each((_i, el) => {
+    const levels = abstract.get($(el).children("w\\:abstractNumId").attr("w:val") || "");
+    if (levels) formats.set($(el).attr("w:numId") || "", levels);
+  });
+  return formats;
+}
+
+function parseRels(xml: string): Map<string, { target: string; external: boolean }> {
+  const rels = new Map<string, { target: string; external: boolean }>();
+  if (!xml) return rels;
+  const $ = cheerio.load(xml, { xml: true });
+  $("Relationship").each((_i, el) => {
+    const $rel = $(el);
+    rels.set($rel.attr("Id") || "", { target: $rel.attr("Target") || "", external: $rel.attr("TargetMode") === "External" });
+  });
+  return rels;
+}
+
+function renderBlocks(ctx: DocxContext, nodes: any[]): string {
+  const { $ } = ctx;
+  const out: string[] = [];
+  // Open lists as a stack of [tag, level]; Word has no list container, only numbered paragraphs.
+  const lists: Array<{ tag: "ul" | "ol"; level: number }> = [];
+  const closeLists = (toLevel: number) => {
+    while (lists.length && lists[lists.length - 1].level >= toLevel) out.push(`</li></${lists.pop()!.tag}>`);
+  };
+
+  for (const node of nodes) {
+    if (node.name === "w:sdt") {
+      closeLists(0);
+      out.push(renderBlocks(ctx, $(node).children("w\\:sdtContent").children().toArray()));
+      continue;
+    }
+    if (node.name === "w:tbl") {
+      closeLists(0);
+      out.push(renderTable(ctx, node));
+      continue;
+    }
+    if (node.name !== "w:p") continue;
+
+    const $p = $(node);
+    const styleId = $p.find("w\\:pPr > w\\:pStyle").attr("w:val") || "";
+    const numId = $p.find("w\\:pPr > w\\:numPr > w\\:numId").attr("w:val");
+    const ilvl = Number($p.find("w\\:pPr > w\\:numPr > w\\:ilvl").attr("w:val") || 0);
+    const content = renderRuns(ctx, node);
+    const heading = ctx.headingLevels.get(styleId) ?? outlineHeading($p.find("w\\:pPr > w\\:outlineLvl").attr("w:val"));
+
+    if (numId && numId !== "0" && !heading) {
+      const tag = (ctx.listFormats.get(numId)?.[ilvl] ?? "bullet") === "bullet" ? "ul" : "ol";
+      closeLists(ilvl + 1);
+      const top = lists[lists.length - 1];
+      if (top && top.level === ilvl && top.tag === tag) {
+        out.push("</li><li>");
+      } else {
+        if (top && top.level === ilvl) closeLists(ilvl);
+        out.push(`<${tag}><li>`);
+        lists.push({ tag, level: ilvl });
+      }
+      out.push(content);
+      continue;
+    }
+
+    closeLists(0);
+    if (!content.trim()) continue;
+    if (heading) out.push(`<h${heading}>${content}</h${heading}>`);
+    else if (ctx.quoteStyles.has(styleId)) out.push(`<blockquote><p>${content}</p></blockquote>`);
+    else out.push(`<p>${content}</p>`);
+  }
+  closeLists(0);
+  return out.join("\n");
+}
+
+function renderRuns(ctx: DocxContext, paragraph: any): string {
+  const { $ } = ctx;
+  let html = "";
+
+  const visit = (node: any) => {
+    for (const child of $(node).children().toArray() as any[]) {
+      if (child.name === "w:hyperlink") {
+        const $link = $(child);
+        const rel = ctx.rels.get($link.attr("r:id") || "");
+        const anchor = $link.attr("w:anchor");
+        const target = rel?.target || (anchor ? `#${anchor}` : undefined);
+        if (target) {
+          const inner = captureRuns(() => visit(child));
+          html += `<a href="${escapeAttr(target)}">${inner}</a>`;
+        } else {
+          visit(child);
+        }
+      } else if (child.name === "w:r") {
+        html += renderRun(ctx, child);
+      } else if (["w:ins", "w:smartTag", "w:fldSimple", "w:customXml"].includes(child.name)) {
+        // Tracked insertions and field wrappers hold ordinary runs; deletions (w:del) are skipped.
+        visit(child);
+      }
+    }
+  };
+
+  const captureRuns = (fn: () => void): string => {
+    const saved = html;
+    html = "";
+    fn();
+    const inner = html;
+    html = saved;
+    return inner;
+  };
+
+  visit(paragraph);
+  return html;
+}
+
+function renderRun(ctx: DocxContext, run: any): string {
+  const { $ } = ctx;
+  const $run = $(run);
+  const $props = $run.children("w\\:rPr");
+  const on = (tag: string) => {
+    const $flag = $props.children(tag);
+    return $flag.length > 0 && !["0", "false"].includes($flag.attr("w:val") || "");
+  };
+
+  let text = "";
+  for (const child of $run.children().toArray() as any[]) {
+    if (child.name === "w:t") text += escapeHtml($(child).text());
+    else if (child.name === "w:tab") text += " ";
+    else if (child.name === "w:br" || child.name === "w:cr") text += "<br>";
+    else if (child.name === "w:drawing" || child.name === "w:pict") text += renderImage(ctx, child);
+  }
+  if (!text) return "";
+
+  const vertAlign = $props.children("w\\:vertAlign").attr("w:val");
+  if (vertAlign === "superscript") text = `<sup>${text}</sup>`;
+  if (vertAlign === "subscript") text = `<sub>${text}</sub>`;
+  if (on("w\\:strike") || on("w\\:dstrike")) text = `<del>${text}</del>`;
+  if (on("w\\:i")) text = `<em>${text}</em>`;
+  if (on("w\\:b")) text = `<strong>${text}</strong>`;
+  return text;
+}
+
+function renderImage(ctx: DocxContext, drawing: any): string {
+  const { $ } = ctx;
+  const $drawing = $(drawing);
+  const id = $drawing.find("a\\:blip").attr("r:embed") || $drawing.find("v\\:imagedata").attr("r:id") || "";
+  const rel = ctx.rels.get(id);
+  if (!rel || rel.external) return "";
+
+  let image = ctx.images.get(id);
+  if (!image) {
+    const $docPr = $drawing.find("wp\\:docPr");
+    const $extent = $drawing.find("wp\\:extent");
+    image = {
+      id,
+      // Targets are relative to word/ unless absolute within the package.
+      path: rel.target.startsWith("/") ? rel.target.slice(1) : path.posix.normalize(path.posix.join("word", rel.target)),
+      contentType: IMAGE_TYPES[path.extname(rel.target).toLowerCase()] || "application/octet-stream",
+    };
+    const alt = ($docPr.attr("descr") || $docPr.attr("title") || "").trim();
+    if (alt) image.alt = alt;
+    const cx = Number($extent.attr("cx"));
+    const cy = Number($extent.attr("cy"));
+    if (cx > 0) image.width = Math.round(cx / EMU_PER_PX);
+    if (cy > 0) image.height = Math.round(cy / EMU_PER_PX);
+    ctx.images.set(id, image);
+  }
+  return `<img src="${escapeAttr(image.path)}" alt="${escapeAttr(image.alt || "")}">`;
+}
+
+/**
+ * Horizontally merged cells (gridSpan) become colspans; vertically merged
+ * continuation cells are left empty, which is how a markdown table shows them.
+ */
+function renderTable(ctx: DocxContext, table: any): string {
+  const { $ } = ctx;
+  const rows = $(table).children("w\\:tr").toArray().map((tr, i) => {
+    const tag = i === 0 ? "th" : "td";
+    const cells = $(tr).children("w\\:tc").toArray().map((tc) => {
+      const $tc = $(tc);
+      const span = Number($tc.find("w\\:tcPr > w\\:gridSpan").attr("w:val") || 1);
+      const vMerge = $tc.find("w\\:tcPr > w\\:vMerge");
+      const continued = vMerge.length > 0 && vMerge.attr("w:val") !== "restart";
+      const inner = continued ? "" : renderBlocks(ctx, $tc.children().toArray());
+      return `<${tag}${span > 1 ? ` colspan="${span}"` : ""}>${inner}</${tag}>`;
+    });
+    return `<tr>${cells.join("")}</tr>`;
+  });
+  return rows.length ? `<table>${rows.join("\n")}</table>` : "";
+}
+
+function escapeHtml(text: string): string {
+  return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+}
+
+function escapeAttr(text: string): string {
+  return escapeHtml(text).replace(/"/g, "&quot;");
+}
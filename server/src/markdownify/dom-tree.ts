@@ -0,0 +1,69 @@
+import { loadCleanDocument } from './markdown';
+import { resolveUrl } from './urls';
+
+export interface DomTreeNode {
+  tag: string;
+  attrs?: Record<string, string>;
+  /** Present on text nodes and on elements whose only content is text. */
+  text?: string;
+  children?: DomTreeNode[];
+}
+
+/**
+ * Attributes that carry meaning for structure-dependent features. Styling,
+ * event handlers and framework data-* noise are deliberately dropped.
+ */
+const SIGNIFICANT_ATTRS = new Set([
+  "id", "class", "href", "src", "alt", "title", "name", "type", "value", "role",
+  "aria-label", "lang", "dir", "colspan", "rowspan", "datetime", "content",
+  "itemprop", "itemscope", "itemtype", "rel", "srcset", "action", "method",
+]);
+
+const URL_ATTRS = new Set(["href", "src", "action"]);
+
+/**
+ * Returns a simplified JSON tree of the cleaned main content of a page — the
+ * same content the markdown converter sees — so callers that need structure
+ * don't have to re-parse the HTML themselves.
+ */
+export function extractDomTree(html: string | null | undefined, baseUrl?: string | null): DomTreeNode | null {
+  if (!html) return null;
+
+  const { $content } = loadCleanDocument(html);
+  const root = $content.get(0);
+  return root ? toTreeNode(root, baseUrl ?? null) : null;
+}
+
+function toTreeNode(el: any, baseUrl: string | null): DomTreeNode {
+  const node: DomTreeNode = { tag: el.name.toLowerCase() };
+
+  const attrs: Record<string, string> = {};
+  for (const [name, value] of Object.entries<string>(el.attribs || {})) {
+    const key = name.toLowerCase();
+    if (!SIGNIFICANT_ATTRS.has(key)) continue;
+    attrs[key] = URL_ATTRS.has(key) ? resolveUrl(value, baseUrl) : value;
+  }
+  if (Object.keys(attrs).length > 0) node.attrs = attrs;
+
+  const children: DomTreeNode[] = [];
+  for (const child of el.children || []) {
+    if (child.type === "text") {
+      const text = collapseWhitespace(child.data || "");
+      if (text) children.push({ tag: "#text", text });
+    } else if (child.type === "tag") {
+      children.push(toTreeNode(child, baseUrl));
+    }
+  }
+
+  if (children.length === 1 && children[0].tag === "#text") {
+    node.text = children[0].text;
+  } else if (children.length > 0) {
+    node.children = children;
+  }
+
+  return node;
+}
+
+function collapseWhitespace(text: string): string {
+  return text.replace(/\s+/g, " ").trim();
+}
@@ -0,0 +1,81 @@
+/**
+ * What happens to <img> tags that only draw an emoji (Twemoji, WordPress,
+ * GitHub, Facebook, Noto image sets).
+ * - unicode: the emoji character itself
+ * - shortcode: a :shortcode: where one is known (GitHub/Slack names), else the character
+ * - image: left as an image like any other
+ */
+export type EmojiMode = "unicode" | "shortcode" | "image";
+
+/** A string made only of emoji: pictographs, flags, keycaps and their joiners/modifiers. */
+const EMOJI_ONLY_RE = /^(?:\p{Extended_Pictographic}|\p{Regional_Indicator}|[#*0-9]\uFE0F?\u20E3|[\u200D\uFE0F\u{1F3FB}-\u{1F3FF}\u{E0020}-\u{E007F}])+$/u;
+
+/** Codepoints in a file name, e.g. "1f600.png", "1f468-200d-1f469.svg", "emoji_u1f600.png". */
+const CODEPOINT_FILE_RE = /(?:^|\/)(?:emoji_u)?([0-9a-f]{2,6}(?:[-_][0-9a-f]{2,6})*)\.(?:png|svg|gif|webp)(?:[?#]|$)/i;
+
+/** Hosts and paths of the emoji image sets, for images whose class doesn't say "emoji". */
+const EMOJI_PATH_RE = /twemoji|\/emoji\/|\/emojis?[\/._-]|emoji\.php|s\.w\.org\/images\/core\/emoji|noto-emoji|joypixels|emojione/i;
+
+const EMOJI_CLASS_RE = /\b(?:emoji|emoticon|wp-smiley|twemoji)\b/i;
+
+/** GitHub/Slack names for the most common emoji; anything else keeps its character. */
+const SHORTCODES: Record<string, string> = {
+  "😀": "grinning", "😃": "smiley", "😄": "smile", "😁": "grin", "😆": "laughing", "😅": "sweat_smile",
+  "🤣": "rofl", "😂": "joy", "🙂": "slightly_smiling_face", "🙃": "upside_down_face", "😉": "wink",
+  "😊": "blush", "😇": "innocent", "🥰": "smiling_face_with_three_hearts", "😍": "heart_eyes",
+  "🤩": "star_struck", "😘": "kissing_heart", "😋": "yum", "😛": "stuck_out_tongue", "😜": "stuck_out_tongue_winking_eye",
+  "🤪": "zany_face", "🤔": "thinking", "🤐": "zipper_mouth_face", "🤨": "raised_eyebrow", "😐": "neutral_face",
+  "😑": "expressionless", "😶": "no_mouth", "😏": "smirk", "😒": "unamused", "🙄": "roll_eyes",
+  "😬": "grimacing", "😌": "relieved", "😔": "pensive", "😪": "sleepy", "😴": "sleeping", "😷": "mask",
+  "🤒": "face_with_thermometer", "🤢": "nauseated_face", "🤮": "vomiting_face", "🥵": "hot_face", "🥶": "cold_face",
+  "😵": "dizzy_face", "🤯": "exploding_head", "🤠": "cowboy_hat_face", "🥳": "partying_face", "😎": "sunglasses",
+  "🤓": "nerd_face", "😕": "confused", "😟": "worried", "🙁": "slightly_frowning_face", "😮": "open_mouth",
+  "😲": "astonished", "😳": "flushed", "🥺": "pleading_face", "😢": "cry", "😭": "sob", "😱": "scream",
+  "😞": "disappointed", "😓": "sweat", "😩": "weary", "😫": "tired_face", "😤": "triumph", "😡": "rage",
+  "😠": "angry", "🤬": "cursing_face", "😈": "smiling_imp", "💀": "skull", "💩": "hankey", "🤡": "clown_face",
+  "👻": "ghost", "👽": "alien", "🤖": "robot", "😺": "smiley_cat", "🙈": "see_no_evil",
+  "❤": "heart", "🧡": "orange_heart", "💛": "yellow_heart", "💚": "green_heart", "💙": "blue_heart",
+  "💜": "purple_heart", "🖤": "black_heart", "💔": "broken_heart", "💕": "two_hearts", "💯": "100",
+  "💥": "boom", "💫": "dizzy", "💬": "speech_balloon", "💤": "zzz", "👋": "wave", "✋": "hand",
+  "👌": "ok_hand", "✌": "v", "🤞": "crossed_fingers", "👈": "point_left", "👉": "point_right",
+  "👆": "point_up_2", "👇": "point_down", "👍": "+1", "👎": "-1", "✊": "fist", "👊": "facepunch",
+  "👏": "clap", "🙌": "raised_hands", "👐": "open_hands", "🙏": "pray", "💪": "muscle", "👀": "eyes",
+  "🎉": "tada", "🎊": "confetti_ball", "🎁": "gift", "🏆": "trophy", "🔥": "fire", "✨": "sparkles",
+  "⭐": "star", "🌟": "star2", "⚡": "zap", "☀": "sunny", "🌈": "rainbow", "❄": "snowflake",
+  "☕": "coffee", "🍕": "pizza", "🍺": "beer", "🚀": "rocket", "✈": "airplane", "🚗": "car",
+  "⏰": "alarm_clock", "⌛": "hourglass", "📅": "date", "📌": "pushpin", "📎": "paperclip", "🔗": "link",
+  "🔒": "lock", "🔑": "key", "💡": "bulb", "📈": "chart_with_upwards_trend", "📉": "chart_with_downwards_trend",
+  "📝": "memo", "📣": "mega", "📢": "loudspeaker", "🔔": "bell", "💰": "moneybag", "💸": "money_with_wings",
+  "✅": "white_check_mark", "✔": "heavy_check_mark", "❌": "x", "❎": "negative_squared_cross_mark",
+  "⚠": "warning", "🚫": "no_entry_sign", "⛔": "no_entry", "❓": "question", "❗": "exclamation",
+  "➡": "arrow_right", "⬅": "arrow_left", "⬆": "arrow_up", "⬇": "arrow_down", "🔴": "red_circle",
+  "🟢": "green_circle", "🔵": "large_blue_circle", "🆕": "new", "🆓": "free", "🆗": "ok", "🔝": "top",
+};
+
+/**
+ * Returns the emoji an image draws, or undefined for ordinary images. The
+ * alt text is trusted when it is nothing but emoji (WordPress and Twemoji
+ * put the character there); otherwise the codepoints are read from a file
+ * name like "1f600.png", but only for images that look like emoji by class
+ * or by path, so "/photos/2023.png" stays an image.
+ */
+export function emojiFromImage(src: string, alt: string, className = ""): string | undefined {
+  const text = alt.trim();
+  if (text && EMOJI_ONLY_RE.test(text)) return text;
+  if (!EMOJI_CLASS_RE.test(className) && !EMOJI_PATH_RE.test(src)) return undefined;
+
+  const file = src.match(CODEPOINT_FILE_RE);
+  if (!file) return undefined;
+  try {
+    const emoji = String.fromCodePoint(...file[1].split(/[-_]/).map((hex) => parseInt(hex, 16)));
+    return EMOJI_ONLY_RE.test(emoji) ? emoji : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/** ":name:" for an emoji with a known shortcode; variation selectors are ignored when looking it up. */
+export function emojiShortcode(emoji: string): string | undefined {
+  const name = SHORTCODES[emoji.replace(/\uFE0F/g, "")];
+  return name ? `:${name}:` : undefined;
+}
@@ -0,0 +1,26 @@
+import { ConversionError, ConversionErrorCode } from './errors';
+
+/**
+ * Decodes an HTML or XML document the way a browser picks its encoding: a
+ * byte order mark wins, then the charset the transport declared (an HTTP
+ * Content-Type), then the XML declaration or a meta charset within the
+ * first 1024 bytes, then UTF-8. EPUB requires UTF-8 or UTF-16 and pages
+ * mostly use UTF-8, but older documents declare legacy encodings and those
+ * are honoured.
+ */
+export function decodeDocument(bytes: Uint8Array, name: string, charset?: string): string {
+  const head = new TextDecoder("latin1").decode(bytes.subarray(0, 1024));
+  const declared =
+    head.match(/^\s*<\?xml[^>]*\bencoding\s*=\s*["']([\w.:-]+)["']/i)?.[1] ||
+    head.match(/<meta[^>]+charset\s*=\s*["']?([\w.:-]+)/i)?.[1];
+  const bom =
+    bytes[0] === 0xef && bytes[1] === 0xbb && bytes[2] === 0xbf ? "utf-8" :
+    bytes[0] === 0xff && bytes[1] === 0xfe ? "utf-16le" :
+    bytes[0] === 0xfe && bytes[1] === 0xff ? "utf-16be" : undefined;
+  const label = bom || charset || declared || "utf-8";
+  try {
+    return new TextDecoder(label).decode(bytes);
+  } catch (err) {
+    throw new ConversionError(ConversionErrorCode.UNSUPPORTED_ENCODING, `${name} declares an unsupported encoding "${label}"`, { cause: err });
+  }
+}
@@ -0,0 +1,293 @@
+import * as cheerio from 'cheerio';
+import * as path from 'path';
+import JSZip from 'jszip';
+import { decodeDocument } from './encoding';
+import { ConversionError, ConversionErrorCode } from './errors';
+import { DEFAULT_MEMORY_LIMIT_BYTES } from './limits';
+import { convertFragment, MarkdownOptions, renderOutput } from './markdown';
+import { extractOutline, insertTableOfContents, plainHeadingText } from './outline';
+import { createZipReader } from './zip';
+
+export interface EpubChapter {
+  /** Path of the XHTML document inside the archive, e.g. "OEBPS/ch01.xhtml". */
+  path: string;
+  /** Label from the book's navigation document or NCX, if it lists the chapter. */
+  title?: string;
+}
+
+export interface EpubImage {
+  /** Path inside the archive; also the markdown src. */
+  path: string;
+  contentType: string;
+  alt?: string;
+  /** Raw bytes, only when `includeImageData` is set. */
+  data?: Buffer;
+}
+
+export interface EpubConversionResult {
+  markdown: string;
+  title?: string;
+  author?: string;
+  language?: string;
+  /** Spine documents in reading order, as converted. */
+  chapters: EpubChapter[];
+  images: EpubImage[];
+}
+
+export interface EpubConversionOptions extends MarkdownOptions {
+  includeImageData?: boolean;
+}
+
+interface ManifestItem {
+  path: string;
+  mediaType: string;
+  properties: string[];
+}
+
+interface LinkTarget {
+  path: string;
+  fragment: string;
+}
+
+const XHTML_TYPES = new Set(["application/xhtml+xml", "text/html"]);
+
+const VOID_ELEMENTS = "area|base|br|col|embed|hr|img|input|link|meta|param|source|track|wbr";
+
+/** XHTML's "<a id="x"/>" is an unclosed start tag to an HTML parser, so non-void empty elements are expanded first. */
+const SELF_CLOSING_RE = new RegExp(`<(?!(?:${VOID_ELEMENTS})[\\s/>])([a-zA-Z][\\w:-]*)(\\s[^<>]*?)?\\s*\\/>`, "g");
+
+const LINK_PLACEHOLDER_RE = /\]\(#epub-link-(\d+)\)/g;
+
+/**
+ * Converts an EPUB (2 or 3) to a single markdown document. The spine's XHTML
+ * documents go through the same converter as web pages, one chapter at a
+ * time, and are stitched together in reading order under the book title with
+ * a generated table of contents. Links between chapters are rewritten to the
+ * heading anchors of the stitched document and image sources to archive
+ * paths. Options apply as for web pages, except that the table of contents
+ * is on unless `toc` is explicitly false (default depth 2). An archive whose
+ * entries unpack past the limits of createZipReader fails with INPUT_TOO_LARGE.
+ */
+export async function convertEpubToMarkdown(
+  buffer: Buffer,
+  options: EpubConversionOptions = {}
+): Promise<EpubConversionResult> {
+  let zip: JSZip;
+  try {
+    zip = await JSZip.loadAsync(buffer);
+  } catch (err) {
+    throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not an EPUB: the file is not a zip archive", { cause: err });
+  }
+  // Unpacked before any conversion checks maxMemoryBytes, so the archive is held to it here.
+  const entries = createZipReader(zip, options.maxMemoryBytes ?? DEFAULT_MEMORY_LIMIT_BYTES);
+  const read = entries.text;
+
+  const container = await read("META-INF/container.xml");
+  if (!container) throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not an EPUB: META-INF/container.xml is missing");
+  const opfPath = cheerio.load(container, { xml: true })("rootfile").first().attr("full-path") || "";
+  const opfXml = opfPath ? await read(opfPath) : "";
+  if (!opfXml) throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not an EPUB: the package document is missing");
+
+  const opf = cheerio.load(opfXml, { xml: true });
+  const manifest = new Map<string, ManifestItem>();
+  opf("manifest > item").each((_i, el) => {
+    const href = opf(el).attr("href");
+    if (!href) return;
+    manifest.set(opf(el).attr("id") || href, {
+      path: resolvePath(opfPath, href),
+      mediaType: (opf(el).attr("media-type") || "").toLowerCase(),
+      properties: (opf(el).attr("properties") || "").split(/\s+/).filter(Boolean),
+    });
+  });
+
+  // The book's own navigation document is replaced by the generated table of contents.
+  const spine = opf("spine > itemref")
+    .toArray()
+    .filter((el) => opf(el).attr("linear") !== "no")
+    .map((el) => manifest.get(opf(el).attr("idref") || ""))
+    .filter((item): item is ManifestItem => !!item && XHTML_TYPES.has(item.mediaType) && !item.properties.includes("nav"));
+
+  const encrypted = await encryptedPaths(await read("META-INF/encryption.xml"));
+  if (spine.some((item) => encrypted.has(item.path))) {
+    throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "EPUB content is encrypted (DRM) and cannot be converted");
+  }
+
+  const labels = await navigationLabels(opf, manifest, read);
+  const spinePaths = new Set(spine.map((item) => item.path));
+  const mediaTypes = new Map([...manifest.values()].map((item) => [item.path, item.mediaType]));
+  const images = new Map<string, EpubImage>();
+  const targets: LinkTarget[] = [];
+  const headingIds = new Map<string, string>();
+
+  const { includeImageData, ...markdownOptions } = options;
+  const chapterOptions: MarkdownOptions = { ...markdownOptions, toc: false, outputFormat: "markdown" };
+  const chapters: EpubChapter[] = [];
+  const parts: { path: string; markdown: string }[] = [];
+
+  for (const item of spine) {
+    const bytes = await entries.bytes(item.path);
+    if (!bytes) continue;
+    const xhtml = decodeDocument(bytes, item.path);
+    if (!xhtml) continue;
+    const $ = cheerio.load(xhtml.replace(SELF_CLOSING_RE, "<$1$2></$1>"));
+
+    // Cover pages often wrap the image in an SVG, which the converter drops.
+    $("svg").each((_i, el) => {
+      const href = $(el).find("image").first().attr("xlink:href") || $(el).find("image").first().attr("href");
+      if (href) $(el).replaceWith($("<img>").attr("src", href).attr("alt", $(el).attr("aria-label") || ""));
+    });
+
+    $("img[src]").each((_i, el) => {
+      const src = $(el).attr("src")!;
+      if (isExternal(src)) return;
+      const resolved = resolvePath(item.path, src);
+      $(el).attr("src", resolved);
+      if (!images.has(resolved)) {
+        const image: EpubImage = { path: resolved, contentType: mediaTypes.get(resolved) || "application/octet-stream" };
+        const alt = ($(el).attr("alt") || "").trim();
+        if (alt) image.alt = alt;
+        images.set(resolved, image);
+      }
+    });
+
+    $("a[href]").each((_i, el) => {
+      const href = $(el).attr("href")!;
+      if (isExternal(href)) return;
+      const [file, fragment = ""] = href.split("#");
+      const target = { path: file ? resolvePath(item.path, file) : item.path, fragment: decodeFragment(fragment) };
+      if (!spinePaths.has(target.path)) return;
+      $(el).attr("href", `#epub-link-${targets.push(target) - 1}`);
+    });
+
+    $("[id]").each((_i, el) => {
+      const $heading = $(el).is("h1,h2,h3,h4,h5,h6") ? $(el) : $(el).find("h1,h2,h3,h4,h5,h6").first();
+      const text = $heading.text().replace(/\s+/g, " ").trim();
+      if (text) headingIds.set(`${item.path}#${$(el).attr("id")}`, text);
+    });
+
+    let markdown = await convertFragment($.html(), "body", null, chapterOptions);
+    const title = labels.get(item.path);
+    if (title && !extractOutline(markdown).length) markdown = markdown ? `# ${title}\n\n${markdown}` : "";
+    if (!markdown) continue;
+    chapters.push(title ? { path: item.path, title } : { path: item.path });
+    parts.push({ path: item.path, markdown });
+  }
+
+  const title = opf("metadata dc\\:title").first().text().trim();
+  const author = opf("metadata dc\\:creator").first().text().trim();
+  const language = opf("metadata dc\\:language").first().text().trim();
+
+  const firstHeading = extractOutline(parts[0]?.markdown)[0];
+  const heading = title && !(firstHeading?.offset === 0 && firstHeading.text === plainHeadingText(title)) ? `# ${title}` : "";
+  let markdown = stitch(heading, parts, targets, headingIds);
+  if (options.toc !== false) markdown = insertTableOfContents(markdown, options.tocDepth ?? 2);
+
+  const imageList = [...images.values()];
+  if (includeImageData) {
+    for (const image of imageList) {
+      const data = await entries.bytes(image.path);
+      if (data) image.data = data;
+    }
+  }
+
+  const result: EpubConversionResult = { markdown: renderOutput(markdown, options), chapters, images: imageList };
+  if (title) result.title = title;
+  if (author) result.author = author;
+  if (language) result.language = language;
+  return result;
+}
+
+/**
+ * Joins the chapters and points each cross-chapter link at a heading of the
+ * joined document: the heading carrying the link's fragment id when there is
+ * one, otherwise the first heading of the target chapter.
+ */
+function stitch(heading: string, parts: { path: string; markdown: string }[], targets: LinkTarget[], headingIds: Map<string, string>): string {
+  const sections = heading ? [heading] : [];
+  const ranges = new Map<string, { start: number; end: number }>();
+  let offset = heading ? Buffer.byteLength(heading + "\n\n", "utf8") : 0;
+  for (const part of parts) {
+    const length = Buffer.byteLength(part.markdown, "utf8");
+    ranges.set(part.path, { start: offset, end: offset + length });
+    offset += length + 2;
+    sections.push(part.markdown);
+  }
+
+  const markdown = sections.join("\n\n");
+  const outline = extractOutline(markdown);
+  return markdown.replace(LINK_PLACEHOLDER_RE, (_m, index: string) => {
+    const target = targets[Number(index)];
+    const range = ranges.get(target.path);
+    if (!range) return "](#)";
+    const inChapter = outline.filter((h) => h.offset >= range.start && h.offset < range.end);
+    const text = headingIds.get(`${target.path}#${target.fragment}`);
+    const entry =
+      (text && inChapter.find((h) => h.text === text)) ||
+      inChapter[0] ||
+      outline.filter((h) => h.offset < range.start).pop();
+    return `](#${entry ? entry.anchor : ""})`;
+  });
+}
+
+/** Chapter labels by document path, from the EPUB 3 navigation document or, failing that, the EPUB 2 NCX. */
+async function navigationLabels(
+  opf: cheerio.CheerioAPI,
+  manifest: Map<string, ManifestItem>,
+  read: (name: string) => Promise<string>
+): Promise<Map<string, string>> {
+  const labels = new Map<string, string>();
+  const add = (base: string, href: string | undefined, label: string) => {
+    const text = label.replace(/\s+/g, " ").trim();
+    if (!href || !text || isExternal(href)) return;
+    const file = resolvePath(base, href.split("#")[0]);
+    if (!labels.has(file)) labels.set(file, text);
+  };
+
+  const nav = [...manifest.values()].find((item) => item.properties.includes("nav"));
+  if (nav) {
+    const $ = cheerio.load((await read(nav.path)).replace(SELF_CLOSING_RE, "<$1$2></$1>"));
+    const $toc = $('nav[epub\\:type~="toc"]').first();
+    ($toc.length ? $toc : $("nav").first()).find("a[href]").each((_i, el) => add(nav.path, $(el).attr("href"), $(el).text()));
+  }
+  if (labels.size) return labels;
+
+  const ncx = manifest.get(opf("spine").attr("toc") || "") || [...manifest.values()].find((item) => item.mediaType === "application/x-dtbncx+xml");
+  if (ncx) {
+    const $ = cheerio.load(await read(ncx.path), { xml: true });
+    $("navPoint").each((_i, el) => add(ncx.path, $(el).children("content").attr("src"), $(el).children("navLabel").text()));
+  }
+  return labels;
+}
+
+/** Archive paths of encrypted resources; font obfuscation also lists fonts here, which is harmless. */
+async function encryptedPaths(xml: string): Promise<Set<string>> {
+  const paths = new Set<string>();
+  if (!xml) return paths;
+  const $ = cheerio.load(xml, { xml: true });
+  $("CipherReference, enc\\:CipherReference").each((_i, el) => {
+    const uri = $(el).attr("URI");
+    if (uri) paths.add(resolvePath("", uri));
+  });
+  return paths;
+}
+
+/** Resolves an href found in the document at `from` to a path inside the archive. */
+function resolvePath(from: string, href: string): string {
+  let decoded = href;
+  try {
+    decoded = decodeURIComponent(href);
+  } catch {}
+  return path.posix.normalize(path.posix.join(path.posix.dirname(from), decoded)).replace(/^(\.\.\/)+|^\//, "");
+}
+
+function decodeFragment(fragment: string): string {
+  try {
+    return decodeURIComponent(fragment);
+  } catch {
+    return fragment;
+  }
+}
+
+function isExternal(href: string): boolean {
+  return /^[a-z][a-z0-9+.-]*:/i.test(href) || href.startsWith("//");
+}
@@ -0,0 +1,50 @@
+/**
+ * Stable failure codes for the markdownify exports. The numbers are part of
+ * the contract: callers store and compare them, so existing values never
+ * change and new codes are appended.
+ * @enum {number}
+ */
+export enum ConversionErrorCode {
+  /** The input is not a document of the expected kind or could not be read. */
+  PARSE_FAILED = 1,
+  /** The input would need more memory than the conversion may use. */
+  INPUT_TOO_LARGE = 2,
+  /** The conversion ran past its time limit. */
+  TIMEOUT = 3,
+  /** An option, selector or expression supplied by the caller is invalid. */
+  INVALID_OPTIONS = 4,
+  /** An unexpected failure inside the converter was caught and reported. */
+  PANIC_RECOVERED = 5,
+  /** The input declares a character encoding that cannot be decoded. */
+  UNSUPPORTED_ENCODING = 6,
+  /** The page could not be fetched: a network failure or an HTTP error status. */
+  FETCH_FAILED = 7,
+}
+
+/** Serializable form of a failure, for envelopes and logs. */
+export interface ConversionErrorInfo {
+  code: ConversionErrorCode;
+  /** The code's name, e.g. "TIMEOUT". */
+  name: string;
+  message: string;
+}
+
+export class ConversionError extends Error {
+  readonly code: ConversionErrorCode;
+
+  constructor(code: ConversionErrorCode, message: string, options?: { cause?: unknown }) {
+    super(message, options);
+    this.name = "ConversionError";
+    this.code = code;
+  }
+}
+
+/** The code of any thrown value; anything that isn't a ConversionError counts as PANIC_RECOVERED. */
+export function conversionErrorCode(err: unknown): ConversionErrorCode {
+  return err instanceof ConversionError ? err.code : ConversionErrorCode.PANIC_RECOVERED;
+}
+
+export function conversionErrorInfo(err: unknown): ConversionErrorInfo {
+  const code = conversionErrorCode(err);
+  return { code, name: ConversionErrorCode[code], message: err instanceof Error ? err.message : String(err) };
+}
@@ -0,0 +1,92 @@
+import * as cheerio from 'cheerio';
+import { convertFragment, MarkdownOptions } from './markdown';
+import { resolveUrl } from './urls';
+
+export interface FeedItem {
+  title: string;
+  link: string;
+  /** ISO-8601 publish (or, failing that, update) date. */
+  date?: string;
+  author?: string;
+  id?: string;
+  markdown: string;
+}
+
+function toIsoDate(value: string | undefined): string | undefined {
+  if (!value?.trim()) return undefined;
+  const date = new Date(value.trim());
+  return Number.isNaN(date.getTime()) ? undefined : date.toISOString();
+}
+
+/**
+ * Parses an RSS 2.0, RSS 1.0 (RDF) or Atom feed and converts each entry's
+ * HTML content to markdown, preferring full content (content:encoded, Atom
+ * <content>) over summaries. Relative links in entries resolve against the
+ * entry link, then the feed URL.
+ */
+export async function convertFeed(
+  xml: string | null | undefined,
+  feedUrl?: string | null,
+  options: MarkdownOptions = {}
+): Promise<FeedItem[]> {
+  if (!xml || !xml.trim()) return [];
+
+  const $ = cheerio.load(xml, { xml: true });
+  const isAtom = $("feed").length > 0;
+  const entries = isAtom ? $("feed > entry").toArray() : $("item").toArray();
+  const items: FeedItem[] = [];
+
+  for (const entry of entries) {
+    const $entry = $(entry);
+    const text = (selector: string) => $entry.children(selector).first().text().trim();
+
+    let link = "";
+    let body = "";
+    let isText = false;
+    let date: string | undefined;
+    let author: string | undefined;
+
+    if (isAtom) {
+      const $alternate = $entry.children('link[rel="alternate"], link:not([rel])').first();
+      link = ($alternate.attr("href") || $entry.children("link").first().attr("href") || "").trim();
+
+      const $content = $entry.children("content").length
+        ? $entry.children("content").first()
+        : $entry.children("summary").first();
+      const type = ($content.attr("type") || "text").toLowerCase();
+      if (type === "xhtml") body = $content.children().first().html() || $content.html() || "";
+      else body = $content.text();
+      isText = type === "text";
+
+      date = toIsoDate(text("published")) || toIsoDate(text("updated"));
+      author = $entry.children("author").children("name").first().text().trim() || undefined;
+    } else {
+      link = text("link") || ($entry.children("guid").attr("isPermaLink") !== "false" ? text("guid") : "");
+      // Namespaced tags need escaping in selectors: content:encoded, dc:date, dc:creator.
+      body = text("content\\:encoded") || text("description");
+      date = toIsoDate(text("pubDate")) || toIsoDate(text("dc\\:date"));
+      author = text("dc\\:creator") || text("author") || undefined;
+    }
+
+    const base = link ? resolveUrl(link, feedUrl) : feedUrl ?? null;
+    const html = isText ? `<p>${escapeHtml(body).replace(/\n{2,}/g, "</p><p>")}</p>` : body;
+    const markdown = html.trim() ? await convertFragment(`<body>${html}</body>`, "body", base, options) : "";
+
+    const item: FeedItem = {
+      title: text("title").replace(/\s+/g, " "),
+      link: link ? resolveUrl(link, feedUrl) : "",
+      markdown,
+    };
+    if (date) item.date = date;
+    if (author) item.author = author;
+    const id = text(isAtom ? "id" : "guid");
+    if (id) item.id = id;
+    items.push(item);
+  }
+
+  return items;
+}
+
+function escapeHtml(text: string): string {
+  return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+}
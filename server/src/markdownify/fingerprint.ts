@@ -0,0 +1,67 @@
+import { createHash } from 'crypto';
+import { markdownToPlainText } from './text';
+
+/** Words per shingle; 3 keeps single-word edits from flipping many bits. */
+const SHINGLE_SIZE = 3;
+
+/** Default Hamming distance at or below which two fingerprints count as near-duplicates. */
+export const NEAR_DUPLICATE_DISTANCE = 3;
+
+/**
+ * The 64 feature bits of a shingle, as [low, high] 32-bit words. SimHash needs
+ * every bit to be independent of the others. Two differently seeded FNV-1a
+ * hashes are not: they differ only by their start value, so their low bits
+ * agree or disagree on every input. The first 8 bytes of SHA-1 mix fully.
+ */
+function featureBits(shingle: string): [number, number] {
+  const digest = createHash("sha1").update(shingle).digest();
+  return [digest.readUInt32BE(4), digest.readUInt32BE(0)];
+}
+
+/**
+ * 64-bit SimHash of the visible text of a markdown document, as 16 hex digits.
+ * Similar documents yield fingerprints a small Hamming distance apart, so
+ * scheduled robots can tell "effectively unchanged" without storing and
+ * diffing full markdown. Case, punctuation and markup are ignored.
+ */
+export function computeSimHash(markdown: string | null | undefined): string {
+  const words = markdownToPlainText(markdown).toLowerCase().match(/[\p{L}\p{N}]+/gu) || [];
+  const weights = new Array<number>(64).fill(0);
+
+  const shingles = words.length < SHINGLE_SIZE
+    ? (words.length ? [words.join(" ")] : [])
+    : words.slice(0, words.length - SHINGLE_SIZE + 1).map((_w, i) => words.slice(i, i + SHINGLE_SIZE).join(" "));
+
+  for (const shingle of shingles) {
+    const halves = featureBits(shingle);
+    for (let bit = 0; bit < 64; bit++) {
+      const set = (halves[bit >> 5] >>> (bit & 31)) & 1;
+      weights[bit] += set ? 1 : -1;
+    }
+  }
+
+  let hi = 0;
+  let lo = 0;
+  for (let bit = 0; bit < 32; bit++) {
+    if (weights[bit] > 0) lo |= 1 << bit;
+    if (weights[bit + 32] > 0) hi |= 1 << bit;
+  }
+  return (hi >>> 0).toString(16).padStart(8, "0") + (lo >>> 0).toString(16).padStart(8, "0");
+}
+
+/** Number of differing bits between two fingerprints from computeSimHash. */
+export function hammingDistance(a: string, b: string): number {
+  let distance = 0;
+  for (let i = 0; i < 16; i += 8) {
+    let x = (parseInt(a.slice(i, i + 8), 16) ^ parseInt(b.slice(i, i + 8), 16)) >>> 0;
+    while (x) {
+      x &= x - 1;
+      distance++;
+    }
+  }
+  return distance;
+}
+
+export function isNearDuplicate(a: string, b: string, maxDistance = NEAR_DUPLICATE_DISTANCE): boolean {
+  return hammingDistance(a, b) <= maxDistance;
+}
@@ -0,0 +1,265 @@
+export interface FormatOptions {
+  /** Marker for unordered list items (default "-"). */
+  bullet?: "-" | "*" | "+";
+  /** "incrementing" renumbers ordered lists 1, 2, 3…; "ones" writes every item as 1. (default "incrementing"). */
+  orderedList?: "incrementing" | "ones";
+  /** Code fence character (default "`"); fences grow to stay longer than any run inside the code. */
+  fence?: "`" | "~";
+  /** Pad table cells so columns line up (default true). */
+  alignTables?: boolean;
+}
+
+type Block = { kind: "heading" | "fence" | "table" | "rule" | "text"; lines: string[] };
+
+const FENCE_OPEN = /^(\s*)(`{3,}|~{3,})(.*)$/;
+const THEMATIC_BREAK = /^\s{0,3}([-*_])(?:\s*\1){2,}\s*$/;
+const ATX = /^\s{0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$/;
+const BULLET_ITEM = /^(\s*)[-*+](\s+)(?=\S)/;
+const ORDERED_ITEM = /^(\s*)(\d{1,9})[.)](\s+)(?=\S)/;
+
+/**
+ * Pretty-prints markdown to one canonical style: ATX headings with one blank
+ * line around them, a single bullet marker, renumbered ordered lists, uniform
+ * fences and thematic breaks, aligned tables, no trailing whitespace and a
+ * single final newline. Formatting its own output changes nothing, so files
+ * that robots append to stay diff-friendly.
+ */
+export function formatMarkdown(markdown: string | null | undefined, options: FormatOptions = {}): string {
+  if (!markdown || !markdown.trim()) return "";
+
+  const blocks = parseBlocks(markdown.replace(/\r\n?/g, "\n").split("\n"));
+  const rendered = blocks.map((block) => renderBlock(block, options)).filter((text) => text !== "");
+  return `${rendered.join("\n\n")}\n`;
+}
+
+function parseBlocks(lines: string[]): Block[] {
+  const blocks: Block[] = [];
+  let text: string[] = [];
+
+  const pushText = () => {
+    while (text.length && !text[text.length - 1].trim()) text.pop();
+    if (text.length) blocks.push({ kind: "text", lines: text });
+    text = [];
+  };
+
+  for (let i = 0; i < lines.length; i++) {
+    const line = lines[i].replace(/\s+$/, (ws) => (ws.length >= 2 && lines[i].trim() && /^ +$/.test(ws) ? "  " : ""));
+
+    const fence = line.match(FENCE_OPEN);
+    if (fence && !(fence[2][0] === "`" && fence[3].includes("`"))) {
+      const body = [line];
+      const indent = fence[1].length;
+      while (++i < lines.length) {
+        body.push(lines[i]);
+        const close = lines[i].match(/^\s*(`{3,}|~{3,})\s*$/);
+        if (close && close[1][0] === fence[2][0] && close[1].length >= fence[2].length) break;
+      }
+      // Fences nested in list items stay attached to their item.
+      if (indent > 0 && text.length) text.push(...body);
+      else {
+        pushText();
+        blocks.push({ kind: "fence", lines: body });
+      }
+      continue;
+    }
+
+    if (!line.trim()) {
+      // Blank lines inside a list are significant (loose lists); elsewhere they end the paragraph.
+      const next = lines.slice(i + 1).find((l) => l.trim());
+      if (text.length && next && /^\s+\S/.test(next) && isListLine(text)) {
+        if (text[text.length - 1].trim()) text.push("");
+        continue;
+      }
+      pushText();
+      continue;
+    }
+
+    // Setext heading: a text line underlined with = or -, directly after a paragraph line.
+    const underline = lines[i + 1]?.match(/^\s{0,3}(=+|-+)\s*$/);
+    if (underline && text.length === 0 && !BULLET_ITEM.test(line) && !ORDERED_ITEM.test(line) && !/^\s*[>|]/.test(line) && !ATX.test(line)) {
+      pushText();
+      blocks.push({ kind: "heading", lines: [`${underline[1][0] === "=" ? "#" : "##"} ${line.trim()}`] });
+      i++;
+      continue;
+    }
+
+    if (ATX.test(line)) {
+      pushText();
+      blocks.push({ kind: "heading", lines: [line] });
+      continue;
+    }
+
+    if (THEMATIC_BREAK.test(line) && !(text.length && /^\s*-+\s*$/.test(line))) {
+      pushText();
+      blocks.push({ kind: "rule", lines: [line] });
+      continue;
+    }
+
+    if (/^\s{0,3}\|/.test(line) && isDelimiterRow(lines[i + 1] ?? "")) {
+      pushText();
+      const rows = [line];
+      while (i + 1 < lines.length && /^\s{0,3}\|/.test(lines[i + 1])) rows.push(lines[++i]);
+      blocks.push({ kind: "table", lines: rows });
+      continue;
+    }
+
+    text.push(line);
+  }
+  pushText();
+
+  return blocks;
+}
+
+function isListLine(text: string[]): boolean {
+  return text.some((l) => BULLET_ITEM.test(l) || ORDERED_ITEM.test(l));
+}
+
+function isDelimiterRow(line: string): boolean {
+  return /^\s*\|?\s*:?-{1,}:?\s*(?:\|\s*:?-{1,}:?\s*)*\|?\s*$/.test(line) && line.includes("-");
+}
+
+function renderBlock(block: Block, options: FormatOptions): string {
+  switch (block.kind) {
+    case "heading": {
+      const m = block.lines[0].match(ATX)!;
+      const content = (m[2] || "").trim();
+      return content ? `${m[1]} ${content}` : m[1];
+    }
+    case "rule":
+      return "---";
+    case "fence":
+      return renderFence(block.lines, options.fence ?? "`");
+    case "table":
+      return renderTable(block.lines, options.alignTables ?? true);
+    default:
+      return renderText(block.lines, options);
+  }
+}
+
+function renderFence(lines: string[], char: "`" | "~"): string {
+  const open = lines[0].match(FENCE_OPEN)!;
+  const [, indent, marker, info] = open;
+  const closed = lines.length > 1 && /^\s*(`{3,}|~{3,})\s*$/.test(lines[lines.length - 1]);
+  const body = lines.slice(1, closed ? -1 : undefined);
+
+  // Backtick fences may not carry backticks in their info string.
+  const fenceChar = char === "`" && info.includes("`") ? "~" : char;
+  const runs = body.flatMap((l) => l.match(fenceChar === "`" ? /`+/g : /~+/g) || []);
+  const fence = fenceChar.repeat(Math.max(3, marker.length, ...runs.map((r) => r.length + 1)));
+  return [`${indent}${fence}${info.trim() ? info.trim() : ""}`, ...body, `${indent}${fence}`].join("\n");
+}
+
+function renderText(lines: string[], options: FormatOptions): string {
+  const bullet = options.bullet ?? "-";
+  const counters = new Map<number, number>();
+  let fence: string | null = null;
+
+  return lines
+    .map((line) => {
+      // Code fenced inside a list item is left verbatim.
+      const marker = line.match(/^\s*(`{3,}|~{3,})/)?.[1];
+      if (fence) {
+        if (marker && marker[0] === fence[0] && marker.length >= fence.length) fence = null;
+        return line;
+      }
+      if (marker) {
+        fence = marker;
+        return line;
+      }
+
+      const ordered = line.match(ORDERED_ITEM);
+      if (ordered) {
+        const indent = ordered[1].length;
+        for (const depth of counters.keys()) if (depth > indent) counters.delete(depth);
+        const n = options.orderedList === "ones" ? 1 : counters.has(indent) ? counters.get(indent)! + 1 : Number(ordered[2]);
+        counters.set(indent, n);
+        return `${ordered[1]}${n}. ${line.slice(ordered[0].length)}`;
+      }
+      const item = line.match(BULLET_ITEM);
+      if (item) {
+        const indent = item[1].length;
+        for (const depth of counters.keys()) if (depth >= indent) counters.delete(depth);
+        return `${item[1]}${bullet} ${line.slice(item[0].length)}`;
+      }
+      if (line.trim() && !/^\s/.test(line)) counters.clear();
+      return line;
+    })
+    .join("\n");
+}
+
+function renderTable(lines: string[], align: boolean): string {
+  const rows = lines.map(splitRow);
+  const columns = Math.max(...rows.map((r) => r.length));
+  const alignments = rows[1].map((cell) => {
+    const c = cell.trim();
+    return c.startsWith(":") && c.endsWith(":") ? "center" : c.endsWith(":") ? "right" : c.startsWith(":") ? "left" : "none";
+  });
+  for (const row of rows) while (row.length < columns) row.push("");
+
+  const widths = Array.from({ length: columns }, (_v, c) =>
+    align ? Math.max(3, ...rows.map((r, i) => (i === 1 ? 3 : displayWidth(r[c])))) : 3
+  );
+
+  return rows
+    .map((row, i) => {
+      const cells = row.map((cell, c) => {
+        const width = widths[c];
+        if (i === 1) {
+          const a = alignments[c] ?? "none";
+          const dashes = "-".repeat(Math.max(1, width - (a === "center" ? 2 : a === "none" ? 0 : 1)));
+          return a === "center" ? `:${dashes}:` : a === "left" ? `:${dashes}` : a === "right" ? `${dashes}:` : dashes;
+        }
+        if (!align) return cell;
+        const pad = " ".repeat(Math.max(0, width - displayWidth(cell)));
+        return alignments[c] === "right" ? pad + cell : alignments[c] === "center" ? centre(cell, pad.length) : cell + pad;
+      });
+      return `| ${cells.join(" | ")} |`;
+    })
+    .join("\n");
+}
+
+function centre(cell: string, padding: number): string {
+  const left = Math.floor(padding / 2);
+  return " ".repeat(left) + cell + " ".repeat(padding - left);
+}
+
+/** Splits a table row on unescaped pipes outside code spans. */
+function splitRow(line: string): string[] {
+  const cells: string[] = [];
+  let current = "";
+  let inCode = false;
+  const body = line.trim().replace(/^\|/, "").replace(/(?<!\\)\|$/, "");
+  for (let i = 0; i < body.length; i++) {
+    const ch = body[i];
+    if (ch === "\\" && i + 1 < body.length) {
+      current += ch + body[++i];
+      continue;
+    }
+    if (ch === "`") inCode = !inCode;
+    if (ch === "|" && !inCode) {
+      cells.push(current.trim());
+      current = "";
+      continue;
+    }
+    current += ch;
+  }
+  cells.push(current.trim());
+  return cells;
+}
+
+/** Monospace width: East Asian wide and fullwidth characters take two columns. */
+export function displayWidth(text: string): number {
+  let width = 0;
+  for (const ch of text) {
+    const code = ch.codePointAt(0)!;
+    if (/\p{M}/u.test(ch) || code === 0x200d || (code >= 0xfe00 && code <= 0xfe0f)) continue;
+    const wide =
+      (code >= 0x1100 && code <= 0x115f) || (code >= 0x2e80 && code <= 0xa4cf) ||
+      (code >= 0xac00 && code <= 0xd7a3) || (code >= 0xf900 && code <= 0xfaff) ||
+      (code >= 0xfe30 && code <= 0xfe4f) || (code >= 0xff00 && code <= 0xff60) ||
+      (code >= 0xffe0 && code <= 0xffe6) || (code >= 0x1f300 && code <= 0x1faff) ||
+      (code >= 0x20000 && code <= 0x3fffd);
+    width += wide ? 2 : 1;
+  }
+  return width;
+}
@@ -0,0 +1,272 @@
+import * as cheerio from 'cheerio';
+import { cssPath, cssString } from './list-patterns';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export interface FormFieldOption {
+  value: string;
+  label: string;
+  selected?: boolean;
+}
+
+export interface FormField {
+  /** Submitted name; absent for unnamed controls, which browsers don't submit. */
+  name?: string;
+  /** Input type ("text", "email", "checkbox", ...), or "select" / "textarea". */
+  type: string;
+  label?: string;
+  required: boolean;
+  /** Current value; for checkbox and radio groups, the checked value(s). */
+  value?: string | string[];
+  placeholder?: string;
+  /** Choices of a select, radio group or checkbox group. */
+  options?: FormFieldOption[];
+  multiple?: boolean;
+  disabled?: boolean;
+  pattern?: string;
+  min?: string;
+  max?: string;
+  maxLength?: number;
+  autocomplete?: string;
+  /** Selector to fill the field in a robot step. */
+  selector: string;
+}
+
+export interface FormInfo {
+  /** Resolved submission URL; the page itself when the form has no action. */
+  action: string | null;
+  method: "get" | "post" | "dialog";
+  enctype?: string;
+  name?: string;
+  id?: string;
+  selector: string;
+  fields: FormField[];
+  /** JSON Schema (draft 2020-12) describing the submitted data. */
+  schema: Record<string, any>;
+}
+
+const CONTROL_SELECTOR = "input, select, textarea";
+/** Buttons are how a form is sent, not data the robot needs to provide. */
+const SKIPPED_INPUT_TYPES = new Set(["submit", "button", "reset", "image"]);
+
+/**
+ * Lists every <form> with its action, method and fields: name, type, label,
+ * required flag, constraints and the options of selects, radio groups and
+ * checkbox groups. Controls outside the form that point at it with form="id"
+ * are included. Each form carries a JSON Schema of the data it submits, so a
+ * robot builder can pre-populate form-filling steps.
+ */
+export function extractForms(html: string | null | undefined, baseUrl?: string | null): FormInfo[] {
+  if (!html) return [];
+
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+
+  return $("form").toArray().map((form: any) => {
+    const $form = $(form);
+    const id = $form.attr("id")?.trim();
+    const action = $form.attr("action")?.trim();
+    const method = ($form.attr("method") || "get").trim().toLowerCase();
+
+    const info: FormInfo = {
+      action: action ? resolveUrl(action, base) : base,
+      method: method === "post" || method === "dialog" ? method : "get",
+      selector: cssPath($, form),
+      fields: [],
+      schema: {},
+    };
+    const enctype = $form.attr("enctype")?.trim();
+    if (enctype) info.enctype = enctype.toLowerCase();
+    const name = $form.attr("name")?.trim();
+    if (name) info.name = name;
+    if (id) info.id = id;
+
+    const controls = $form.find(CONTROL_SELECTOR).toArray().filter((el: any) => !$(el).attr("form") || $(el).attr("form") === id);
+    if (id) {
+      const associated = $("[form]").filter((_i, el: any) => el.attribs.form === id).filter(CONTROL_SELECTOR);
+      controls.push(...associated.toArray().filter((el: any) => !$form.has(el).length));
+    }
+
+    info.fields = collectFields($, controls, info.selector);
+    info.schema = toJsonSchema(info.fields);
+    return info;
+  });
+}
+
+function collectFields($: cheerio.CheerioAPI, controls: any[], formSelector: string): FormField[] {
+  const fields: FormField[] = [];
+  const groups = new Map<string, FormField>();
+
+  for (const el of controls) {
+    const $el = $(el);
+    const tag = el.name;
+    const type = tag === "input" ? ($el.attr("type") || "text").trim().toLowerCase() : tag;
+    if (SKIPPED_INPUT_TYPES.has(type)) continue;
+
+    const name = $el.attr("name")?.trim();
+    const required = $el.is("[required]") || $el.attr("aria-required") === "true";
+
+    // Radios sharing a name, and checkboxes sharing a name, are one field with options.
+    if ((type === "radio" || type === "checkbox") && name) {
+      const key = `${type}:${name}`;
+      const option: FormFieldOption = { value: $el.attr("value") ?? "on", label: labelFor($, el) || $el.attr("value") || "" };
+      const checked = $el.is("[checked]");
+      if (checked) option.selected = true;
+
+      let group = groups.get(key);
+      if (!group) {
+        group = { name, type, required, options: [], selector: `${formSelector} [name=${cssString(name)}]` };
+        const legend = $el.closest("fieldset").children("legend").first().text().replace(/\s+/g, " ").trim();
+        if (legend) group.label = legend;
+        groups.set(key, group);
+        fields.push(group);
+      }
+      group.required = group.required || required;
+      group.options!.push(option);
+      if (checked) group.value = type === "radio" ? option.value : [...((group.value as string[]) || []), option.value];
+      continue;
+    }
+
+    const field: FormField = { type, required, selector: cssPath($, el) };
+    if (name) field.name = name;
+    const label = labelFor($, el);
+    if (label) field.label = label;
+
+    if (tag === "select") {
+      field.options = $el.find("option").toArray().map((opt: any) => {
+        const $opt = $(opt);
+        const text = $opt.text().replace(/\s+/g, " ").trim();
+        const option: FormFieldOption = { value: $opt.attr("value") ?? text, label: $opt.attr("label")?.trim() || text };
+        if ($opt.is("[selected]")) option.selected = true;
+        return option;
+      });
+      if ($el.is("[multiple]")) field.multiple = true;
+      const selected = field.options.filter((o) => o.selected).map((o) => o.value);
+      if (field.multiple) field.value = selected;
+      else if (selected.length || field.options.length) field.value = selected[selected.length - 1] ?? field.options[0].value;
+    } else if (tag === "textarea") {
+      const text = $el.text();
+      if (text) field.value = text;
+    } else if (type === "checkbox") {
+      if ($el.is("[checked]")) field.value = $el.attr("value") ?? "on";
+    } else {
+      const value = $el.attr("value");
+      if (value !== undefined && value !== "") field.value = value;
+      if ($el.is("[multiple]")) field.multiple = true;
+    }
+
+    const placeholder = $el.attr("placeholder")?.trim();
+    if (placeholder) field.placeholder = placeholder;
+    if ($el.is("[disabled]") || $el.closest("fieldset[disabled]").length) field.disabled = true;
+    for (const attr of ["pattern", "min", "max", "autocomplete"] as const) {
+      const value = $el.attr(attr)?.trim();
+      if (value) field[attr] = value;
+    }
+    const maxLength = parseInt($el.attr("maxlength") || "", 10);
+    if (maxLength >= 0) field.maxLength = maxLength;
+
+    fields.push(field);
+  }
+
+  // A lone named checkbox is an on/off switch, not a one-option group.
+  for (const group of groups.values()) {
+    if (group.type !== "checkbox" || group.options!.length !== 1) continue;
+    const [option] = group.options!;
+    if (!group.label && option.label) group.label = option.label;
+    delete group.options;
+    if (option.selected) group.value = option.value;
+  }
+  return fields;
+}
+
+/** Accessible label: <label for>, a wrapping <label>, aria-labelledby, aria-label, then title. */
+function labelFor($: cheerio.CheerioAPI, el: any): string | undefined {
+  const $el = $(el);
+  const clean = (s: string | undefined) => s?.replace(/\s+/g, " ").trim() || undefined;
+  const textWithoutControls = ($label: cheerio.Cheerio<any>) => {
+    const $copy = $label.clone();
+    $copy.find("input, select, textarea, button").remove();
+    return clean($copy.text());
+  };
+
+  const id = $el.attr("id");
+  if (id) {
+    const $for = $("label").filter((_i, l) => $(l).attr("for") === id).first();
+    if ($for.length) return textWithoutControls($for);
+  }
+  const $wrapping = $el.closest("label");
+  if ($wrapping.length) {
+    const text = textWithoutControls($wrapping);
+    if (text) return text;
+  }
+  const labelledBy = $el.attr("aria-labelledby");
+  if (labelledBy) {
+    const refs = labelledBy.split(/\s+/).filter(Boolean);
+    const text = clean(refs.map((ref) => $("[id]").filter((_i, other: any) => other.attribs.id === ref).text()).join(" "));
+    if (text) return text;
+  }
+  return clean($el.attr("aria-label")) ?? clean($el.attr("title"));
+}
+
+function toJsonSchema(fields: FormField[]): Record<string, any> {
+  const properties: Record<string, any> = {};
+  const required: string[] = [];
+
+  for (const field of fields) {
+    if (!field.name || field.disabled) continue;
+    const property = fieldSchema(field);
+    if (field.label) property.title = field.label;
+    if (field.value !== undefined && !(Array.isArray(field.value) && !field.value.length)) property.default = field.value;
+
+    // Repeated names ("tags[]") submit every value, so the property becomes an array.
+    const existing = properties[field.name];
+    properties[field.name] = existing ? { type: "array", items: existing.type === "array" ? existing.items : existing } : property;
+    if (field.required && !required.includes(field.name)) required.push(field.name);
+  }
+
+  const schema: Record<string, any> = { $schema: "https://json-schema.org/draft/2020-12/schema", type: "object", properties };
+  if (required.length) schema.required = required;
+  return schema;
+}
+
+function fieldSchema(field: FormField): Record<string, any> {
+  const values = field.options?.map((o) => o.value);
+  if (field.type === "checkbox") {
+    return field.options ? { type: "array", items: { type: "string", enum: values }, uniqueItems: true } : { type: "boolean" };
+  }
+  if (field.type === "radio") return { type: "string", enum: values };
+  if (field.type === "select") {
+    return field.multiple ? { type: "array", items: { type: "string", enum: values }, uniqueItems: true } : { type: "string", enum: values };
+  }
+
+  const schema: Record<string, any> = { type: "string" };
+  switch (field.type) {
+    case "number":
+    case "range":
+      schema.type = "number";
+      if (field.min !== undefined && !Number.isNaN(Number(field.min))) schema.minimum = Number(field.min);
+      if (field.max !== undefined && !Number.isNaN(Number(field.max))) schema.maximum = Number(field.max);
+      return schema;
+    case "email":
+      schema.format = "email";
+      break;
+    case "url":
+      schema.format = "uri";
+      break;
+    case "date":
+      schema.format = "date";
+      break;
+    case "time":
+      schema.format = "time";
+      break;
+    case "datetime-local":
+      schema.format = "date-time";
+      break;
+    case "file":
+      schema.contentMediaType = "application/octet-stream";
+      break;
+  }
+  if (field.pattern) schema.pattern = `^(?:${field.pattern})$`;
+  if (field.maxLength !== undefined) schema.maxLength = field.maxLength;
+  if (field.required && field.type !== "hidden") schema.minLength = 1;
+  return field.multiple ? { type: "array", items: schema } : schema;
+}
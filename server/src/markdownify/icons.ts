@@ -0,0 +1,125 @@
+import * as cheerio from 'cheerio';
+import { URL } from 'url';
+import { extractStructuredData } from './structured-data';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export type SiteIconKind = "icon" | "apple-touch-icon" | "mask-icon" | "tile" | "logo" | "fallback";
+
+export interface SiteIcon {
+  url: string;
+  kind: SiteIconKind;
+  /** Declared width in pixels; absent when unknown or "any" (SVG). */
+  width?: number;
+  height?: number;
+  /** MIME type from the link's type attribute or the file extension. */
+  type?: string;
+}
+
+export interface IconOptions {
+  /** Pixel size the icon will be shown at; the ranking prefers icons at least this big (default 64). */
+  size?: number;
+}
+
+const TYPE_BY_EXTENSION: Record<string, string> = {
+  ico: "image/x-icon", png: "image/png", svg: "image/svg+xml", gif: "image/gif", jpg: "image/jpeg",
+  jpeg: "image/jpeg", webp: "image/webp", avif: "image/avif",
+};
+
+/**
+ * Lists a site's icons, best first: <link rel="icon">, apple-touch-icon,
+ * mask-icon, the Windows tile image, og:logo and the schema.org Organization
+ * logo, plus /favicon.ico as the conventional fallback. Icons are ranked by
+ * how well they fit `size`: scalable SVGs and square icons at least that big
+ * first, then the largest smaller ones; wide logos and monochrome mask icons
+ * come last.
+ */
+export function extractIcons(html: string | null | undefined, baseUrl?: string | null, options: IconOptions = {}): SiteIcon[] {
+  const $ = cheerio.load(html || "");
+  const base = documentBaseUrl($, baseUrl);
+  const icons: SiteIcon[] = [];
+  const seen = new Set<string>();
+
+  const add = (href: string | undefined, kind: SiteIconKind, sizes?: string, type?: string) => {
+    if (!href?.trim() || /^javascript:/i.test(href.trim())) return;
+    const url = resolveUrl(href, base);
+    if (!/^(?:https?:|data:image\/)/i.test(url) || seen.has(url)) return;
+    seen.add(url);
+
+    const icon: SiteIcon = { url, kind };
+    // "sizes" may list several ("16x16 32x32"); the largest is what the file can offer.
+    const dimensions = (sizes || "").toLowerCase().match(/\d+x\d+/g)?.map((s) => s.split("x").map(Number));
+    if (dimensions?.length) {
+      const [width, height] = dimensions.reduce((a, b) => (b[0] * b[1] > a[0] * a[1] ? b : a));
+      icon.width = width;
+      icon.height = height;
+    } else if (kind === "apple-touch-icon") {
+      // iOS uses 180x180 when none is declared, and sites size the file accordingly.
+      icon.width = icon.height = 180;
+    }
+    const mime = type?.trim().toLowerCase() || mimeFromUrl(url);
+    if (mime) icon.type = mime;
+    icons.push(icon);
+  };
+
+  $("link[rel][href]").each((_i, el) => {
+    const $el = $(el);
+    const rel = ($el.attr("rel") || "").toLowerCase().split(/\s+/);
+    const href = $el.attr("href");
+    if (rel.includes("apple-touch-icon") || rel.includes("apple-touch-icon-precomposed")) add(href, "apple-touch-icon", $el.attr("sizes"), $el.attr("type"));
+    else if (rel.includes("mask-icon")) add(href, "mask-icon", undefined, $el.attr("type") || "image/svg+xml");
+    else if (rel.includes("icon") || rel.includes("fluid-icon")) add(href, "icon", $el.attr("sizes"), $el.attr("type"));
+  });
+
+  add($('meta[name="msapplication-TileImage" i]').attr("content"), "tile", "144x144");
+  add($('meta[property="og:logo" i], meta[name="og:logo" i]').attr("content"), "logo");
+  for (const item of extractStructuredData(html, base)) {
+    if (!item.types.some((t) => /^(?:Organization|Corporation|LocalBusiness|NewsMediaOrganization|WebSite)$/.test(t))) continue;
+    const logo = Array.isArray(item.data.logo) ? item.data.logo[0] : item.data.logo;
+    const href = typeof logo === "string" ? logo : logo?.url ?? logo?.contentUrl;
+    if (typeof href === "string") add(href, "logo", logo?.width && logo?.height ? `${parseInt(logo.width, 10)}x${parseInt(logo.height, 10)}` : undefined);
+  }
+
+  if (base && /^https?:/i.test(base)) {
+    try {
+      add(new URL("/favicon.ico", base).toString(), "fallback");
+    } catch {}
+  }
+
+  const size = options.size ?? 64;
+  return icons
+    .map((icon, index) => ({ icon, index, score: score(icon, size) }))
+    .sort((a, b) => b.score - a.score || a.index - b.index)
+    .map((entry) => entry.icon);
+}
+
+/** The best icon for `size`, or null when the page and its base URL offer none. */
+export function extractIcon(html: string | null | undefined, baseUrl?: string | null, options: IconOptions = {}): SiteIcon | null {
+  return extractIcons(html, baseUrl, options)[0] ?? null;
+}
+
+function score(icon: SiteIcon, size: number): number {
+  if (icon.kind === "mask-icon") return 0;
+  if (icon.kind === "fallback") return 1;
+
+  let value: number;
+  if (icon.type === "image/svg+xml") {
+    value = 90;
+  } else if (icon.width && icon.height) {
+    const edge = Math.min(icon.width, icon.height);
+    // At least `size`: smaller overshoot is better. Below it: bigger is better.
+    value = edge >= size ? 80 - Math.min(30, Math.log2(edge / size) * 10) : 40 * (edge / size);
+    if (icon.width !== icon.height) value -= 20;
+  } else {
+    // Undeclared size: an .ico is usually 16-32px, anything else is a guess.
+    value = icon.type === "image/x-icon" ? 40 * Math.min(1, 32 / size) : 30;
+  }
+  if (icon.kind === "logo") value -= 25;
+  return Math.max(2, value);
+}
+
+function mimeFromUrl(url: string): string | undefined {
+  const data = url.match(/^data:(image\/[\w.+-]+)/i);
+  if (data) return data[1].toLowerCase();
+  const ext = url.split(/[?#]/)[0].match(/\.([a-z0-9]+)$/i)?.[1]?.toLowerCase();
+  return ext ? TYPE_BY_EXTENSION[ext] : undefined;
+}
@@ -0,0 +1,85 @@
+import * as cheerio from 'cheerio';
+import { normalizeAmp } from './amp';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export interface ImageInfo {
+  url: string;
+  alt: string;
+  title?: string;
+  width?: number;
+  height?: number;
+  /** True when the real source sits in a lazy-loading attribute or loading="lazy" is set. */
+  lazy: boolean;
+  srcset?: string[];
+}
+
+/** Attributes lazy-loading libraries (lazysizes, lozad, WordPress, …) park the real URL in. */
+const LAZY_SRC_ATTRS = ["data-src", "data-lazy-src", "data-original", "data-lazy", "data-url", "data-echo"];
+const LAZY_SRCSET_ATTRS = ["data-srcset", "data-lazy-srcset"];
+
+/** Tiny inline GIFs/SVGs and spacer files used as placeholders until the real image loads. */
+function isPlaceholderSrc(src: string): boolean {
+  return !src || src.startsWith("data:") || /(?:^|\/)(?:blank|spacer|pixel|transparent|placeholder)\.(?:gif|png|svg)(?:$|\?)/i.test(src);
+}
+
+function parseDimension(value: string | undefined): number | undefined {
+  if (!value) return undefined;
+  const n = parseInt(value, 10);
+  return Number.isFinite(n) && n > 0 ? n : undefined;
+}
+
+function parseSrcset(srcset: string, base: string | null): string[] {
+  return srcset
+    .split(/,\s+(?=\S)/)
+    .map((candidate) => candidate.trim().split(/\s+/)[0])
+    .filter(Boolean)
+    .map((url) => resolveUrl(url, base));
+}
+
+/**
+ * Lists every <img> in the page with its resolved URL, alt text and declared
+ * dimensions. Lazy-loaded images are reported with the URL of the real image
+ * rather than the placeholder in src, and AMP's amp-img is read like <img>.
+ * Images with no usable source are skipped.
+ */
+export function extractImages(html: string | null | undefined, baseUrl?: string | null): ImageInfo[] {
+  if (!html) return [];
+
+  const $ = cheerio.load(html);
+  normalizeAmp($);
+  const base = documentBaseUrl($, baseUrl);
+  const images: ImageInfo[] = [];
+
+  $("img").each((_i, el) => {
+    const $el = $(el);
+    const src = ($el.attr("src") || "").trim();
+    const lazySrc = LAZY_SRC_ATTRS.map((a) => $el.attr(a)?.trim()).find(Boolean);
+    const lazySrcset = LAZY_SRCSET_ATTRS.map((a) => $el.attr(a)?.trim()).find(Boolean);
+    const srcset = lazySrcset || $el.attr("srcset")?.trim() || $el.closest("picture").find("source[srcset]").attr("srcset")?.trim();
+
+    let url = "";
+    if (lazySrc && isPlaceholderSrc(src)) url = lazySrc;
+    else if (!isPlaceholderSrc(src) || (src.startsWith("data:") && !srcset)) url = src;
+    const candidates = srcset ? parseSrcset(srcset, base) : [];
+    if (!url && candidates.length) url = candidates[candidates.length - 1];
+    if (!url) return;
+
+    const image: ImageInfo = {
+      url: url.startsWith("data:") ? url : resolveUrl(url, base),
+      alt: ($el.attr("alt") || "").trim(),
+      lazy: ($el.attr("loading") || "").toLowerCase() === "lazy" || Boolean(lazySrc || lazySrcset) || /\blazy/i.test($el.attr("class") || ""),
+    };
+
+    const title = $el.attr("title")?.trim();
+    if (title) image.title = title;
+    const width = parseDimension($el.attr("width") || $el.attr("data-width"));
+    const height = parseDimension($el.attr("height") || $el.attr("data-height"));
+    if (width) image.width = width;
+    if (height) image.height = height;
+    if (candidates.length) image.srcset = candidates;
+
+    images.push(image);
+  });
+
+  return images;
+}
@@ -0,0 +1,43 @@
+/**
+ * Character trigram profiles for detectLanguage: per language, the 300 most
+ * frequent trigrams, most frequent first, separated by "|". Generated from
+ * the translated gettext message catalogs (LC_MESSAGES/*.mo, without the
+ * iso-codes and keyboard-layout ones) shipped with a Debian system; English
+ * from the catalogs' source strings. Arabic, Persian and Urdu have few
+ * translations there and were supplemented with sample prose. Text was
+ * lowercased and split into runs of letters, and each run counted with a
+ * space at either end, so " th" is "th" starting a word.
+ */
+export const LATIN_PROFILES: Record<string, string> = {
+  en: "ed | th|ing|ng | in|the| re| to| co|he |le |to |or |ile| no|on |es |ion|not|ot |er | fi|is |tio| fo|for|fil|ent|nd |te | is|in |ect| pa| of| se| a |se |of |ate|re | pr|and|nt | an|it | us|ati| ca|rea| de|ted|ter| un|con|me | st|ame| di|ge | li|ry | ex|com|st |use|val|th |ut | ma| wi|ble|ver| be| ch|tin|al |ess|nam|res|ail|sta|can|ith| ar|ali|age|rec|abl|et |lin|all| on|id |tor|ly |ve |wit|ack|ts | op|as |ead|ch |err|led|an | su|ire|en |at | or| ke| fa|cat| al|ne |key|ist| do|ce |ll |ers|lid|ld |cha|ine| lo|pro|int|omm|rin| me|dat|ns |out| na|mat| en| gi|fai| er|rro| wh|ive|pec|ror|ad |pac|ser|han|ann|ory| si|pti|ign|nno|be |ons|ica|ort|thi|dir|che|ont| ha|inv|nte|sio|no |ste| va| tr| sh|men|nva|red| mo|de |set| sp|his|ey |opt|ifi| ne|are| fr|les|orm|cte|pre|sin|put|ase|om |rom|emo|you| yo|ct |git|nge| so|por| as|rit|ang|rem|ss |ins| by|rt |fro|cou|ssi|rs |cre|ren|ove|ces|spe|str|loc|oul|uld|ow |eci|tch|ure|pri|cti|par| cr|cto|ore|act|exp|ult|man| wa|rd | da|fie|mit| ou|eat|wor|tri|din|tur|sig| ad|rma|ck | sy|pat|arg|ere|end|equ|enc|ain|lic|ran|rac|whi|ber|mod| up|mes|oun|one|est| bu|ume|lis| ba|ite|our| ve|low|ay |add|ind|rat|rsi|omp|ple|her| ta|ach|llo|ou |tes|nat|ass|ord|cka|ope",
+  de: "en |er |ich|ein| de|der|cht|sch|den|ht |ung|ie | be| ni|te |nic| au|nde|ver|che|es | un| di| da| ei|die|in |gen|ben|ier|ert| we| ve|rde|ten|on |ist|nte|zei| in|ate|ine|dat| an| vo|it |ers|rt | si|wer| ge|nge| zu|ch |ere|st |end|ng |ter|ren|nen|tei|eic|ion|ehl|aus|feh| er|ent|ste| ko|ige| fe| is|sse|ne |erd| fü|chl|hen|eit|sie|nd |mit|für|ür |auf|bei|tio| wi|von|und|ber|le |ann|nn |ell|ese|sta| ke|kei|ei |ebe| pa|des|kan|et |len|ges|men|geb|hle|abe|tig| sc|nnt|sen|im |rte|rei| re|kon|de | mi|ang|ern|ge |wen|lle|sel| st| ze|ler|hre|rd |erw|erz|run|and| ka|wir| en|rze|üss|lti| al| se|uf |her|zu |ame|em |ode|das|lte|ird|gül|ült|lüs|tze|hlü|as |ind|eru|chn| pr|for|eim|um |ati|nam|lis|ege|ies|el | ab|usg|tel|rst|ls |eil|lic|gab| od|unt| na|esc|onn| le|ach| ar|all|chr|ite|tzt|ngü|ket|rwe|vor|lt |re |se |nis|ile|he |nut|übe|enn|ger|utz|pti|ur |etz|akt| gi|nt |alt|us | op| üb|fer|ass|one|ner|zen| me|hni|enu|omm|als| um| nu| co|opt|git|tie|eig|geg|war|ort|me |ing|tet|ien|lge|nst|set|art|änd|pro|age|ens|be |ign|isc| ak|gef|is | ha|gt |spe|ene|its|ete|wur| wu|hal|mme|wei|anz|fun|zt |nze| im|rie|uch|ake|orm|rsc|urd| so|tte|mer|ser| bi|at |kom|ngs|pak|les|gel|mat|ins|tes|lie|ess|erf|efe|hl |sge",
+  fr: " de|de |es |le |er |ion| le|on |tio|re |ur | co|ent| pa|nt | la|ne |la | in|ns |les|fic| un|our| d | l |eur|que|ich|te | en| no|ati| po|ble|ier|chi|pas| re|est| dé|men| es| fi|as |con|st |lis|res|tre|che|cti|des|pou|un |ect|ue |hie|dan|ans|et | ré|ssi| su|du | li|ire|com| à |ibl| da|uti|ant|rs | se| du|en |ess|par|ge | pr|onn|ée | im|ts |pos|til|ili|ons| n |age| au|mpo|eme|it |ign| ut|imp| so|val|une| ch|ist|se |ont|nte|rre|ver|sib|iqu|ers|ter|ise| ma|cha|oss| ne|ce |ten|sio|ali|ec |nom|omm|ifi|us |ut | op|str|ide| ex|nde|ser|and| av| tr|me |lle| mo| va| ou|ert| pe|non|tte|ar |ure|ort| a | et| qu|ave| ar|err|aut|is |rée|act| éc| do|ntr| ce| si|té | ve| sy|ran|rti|per|cor|cat|nti| fo|ou | lo|inc|sta|nco|ées|ive| er|rec|ale|sup|sse|vec|man|ffi|tur|ez |end|sec|ite|pro|ins|ir | di|nce|ica|omp|déf|for|att|ie |ode|pti|ouv|upp|anc| af|reu| ca|aff|abl|oir|ill|ren|êtr| êt|her|lid|orm|ind|orr|dre|int|au |isa|tif|fin|at |ssa|teu|ous|air|nst|opt|tie|tro|lig|gne| ét|mat| ta|éch|om |ini|arg|pre|por|ces|rou|ate|és |nne|tan| at|mme|mod|pri|ére|tra|rép|aqu|peu|son|pe |tai|enc|sur|rma| ap|ara|uet|ien|leu|he |épe|rer|sag|sat|al | pl|reg| ac|tes|sym|adr|rai|cte|ett|ste|pér|egi|ère|cod| cl|gis|mbo|tiv",
+  es: " de|de |do | no|el | se| co|no |os |ón |es | el|ión| es| en| la|ar |se |la |ent|con| re|ció|ado|en |ra | in| pa| un|te |or |est|par|nte|da |as |to |al |ro |ara|fic|tra|ica|aci|ero|com|que| pu|ta |ido|str|un |sta|des|ada|er |era|per| ca| fi|ion|men| pr|rec|na | lo|cio| si| di|ede|ist|cci| al|ndo|ien|res|ida|lid|ntr|on | ar|pue|esp|and|ued|del|lo |nto|re |che|los|ect|ivo|rad|por| a | op|nes|one|esc| qu|cad|ue |arc|her| po|ont|ter|rio|io |enc|ich|car|den|ali|ene|ecc|ten|una|mit|ble|vo |bre|pro|dos|tro|err|spe|rch| fa| ex| ha| so|ifi|áli|nci|vál| us|dir|rma|ma |tos|omb|mbr|chi|ori|hiv|nom|ina|sió| y | va| er| ti|tor|ire|ste|pre|act|fal|ver|cia|cto|sec|it |ura|omp|ran|ir |iza|tar|all|reg|le |cac|tad|po |rro|rar|stá|las| mo|pci|ror|liz| o |for| ma|rea|tes|ce | su| ac| ta|mo |orm|tiv|opc|ona| ob|ato|cer|ant|int|ser| fu|olo|qui|ite|ere|abl|ama|lic| ve|inv| pe|ia |ari|nst|dor|cid|ea |ins|ctu| lí|eci|ual|git|ca | me|mie|nta|egi|ici|ete|tie|so |nvá|val|les|ces|eta|nea|nal|ece|ndi|cla|usa|tá |arg|min|end|rac|emp|ne |mer|pos| li|ema|uet|bol|mpo|in |ave|inc|nco|sin|cam|amb|ers|ope|erm|ve |ros| cl| sa| tr|rta|lav|nti|rmi|ecu|ace|lec|cri|gis|alo|scr|iva|ras|mbi|tip|lor|pec|ini|dad| gi|ner|cre|deb",
+  pt: " de|de |ão |do | co|os | pa|da |ra |ado| se|ent|ção|ar | a | o |es |as |par|não| in| nã|ara|com|ro |em | es| re|te |nte|fic|con|to |er | um| no|or | do| po|ada| fo|ica|men|açã| li| ar|tra| fi|ta | pr|um |ido|sta|ter|eir| ca|est|ma |dos|iro|pos|ivo|rad|el | ex|ont|qui|vel| em|che|for| é | en|res| da|ist|vo |ich|que|hei|des|ndo|ou |ver|por|al |íve|rqu| di|arq|io |esp|ntr|ess|eci| fa|and|uiv| qu|nto|ome| us|rio|oss|mpo|ões| e |ida| te| ou| ma|me |são|sív|ia |no | op|ssí| im|om |ha |spe|ifi|cad|lin|alh|pro|iza|lid| ao|nom|liz|se |era|imp|man|ina|ser|esc|ao |çõe|ir |pre|uma| si|err| ta| su|so |ura|mo | ve|tad|ini|po |rma|ue |ste| er|tes|ali|orm|dad|is |fin|per|fal| me|car|rro|efi|omp| va|áli|tem|na |vál|def|nha|rec| mo|cia|loc|str|inv|opç|ria|inh|ces|ho |ere| al|tar| pe|ári| ap|int| sa|lo | ne|nvá|tiv| as|oi |foi|ten|cri|ode|usa|ame|end|pri|dor|ve |oca|lho|ote|ros|pac|act|lha|óri|pec|dir|ade|ion|ort|nde|das|upo|tam|val|ume|re |aco| os|nta|ema|ual|ama|lic|ret|ais|alo|nci|ita|cot|lis|ire|alt| at| lo|rar|ora|ero|til|ran|ers| so|ili|ca |nal|pod|tór|rem| na|ant|ect|cif|sso|sem|cio|tos|enc|cid|erm|scr|mer|ída|nho| nú|mit|roc|tro|omo|ece|caç|arg|aíd|ito|núm|ecu| tr|cha|rmi|pon|saí|oma|tip| b ",
+  it: "to | di|re |le | co| no|ion|di |on |ne | de|non|zio|ile| in|one|ent|ta | ri|la |ato|il |con|del| il|te |ti |nte|per| fi|sta|pos| un|ell|are|er | pe|mpo|bil|men|ssi|azi| im| es|fil|ica|un |ess|imp| è |el | se| la|ibi|com|ali|chi| st| ne| pr|oss|lla|est|ett|lo | da|sib| so|ere| re| al| l |tat|ore|che|fic|ifi|nti|ll |ver|no |ati|in |ome|do | ch|so |val| va|all|me |ter|ni | le|ten|ata|oni| su| pa|tto|ra |ro |na |li |att|it |io |ire|nto|seg| i |sci| si|cor|err|tor|ina|nel|ita|cat|ura|ese|sio|tte|ma |ono|ost|pre| mo|tro|izz| sc|zza|rat|ont| tr|ggi|rma|ric|da | us| er|he | ca|ito|ame|ve | a |and|eri|for|rim|nom| qu|car| ma|mod|ca |agg|se |str|rro|pro|tra|acc|ndi| me|ran|za | op|lid|po |ist|ser|egu| gi|int|tti|cit|una| sp| ve|ror|hia|rec|usc| e | ar|liz|por|tes|ce |dir|man|llo| el|rea|usa|ius| po|ste|que|ero|uto|iav|ri |ei |ari|ndo|sto|min|ale| nu|ich|git|anc|ia | o |ris| vi|si |mer|ppo|ry |gge|enz|orm|sa |res|ave|ili|spe|lle|riu|sse|ini|era|dei|ind|gui|sso| fo|ice|ort|ora|ass|ime|ele|mit|ori|ene|pri|pac|dif|eci| at|ory|cri|spo|gio|odi|gli|dal|lit|cch|opz|ual|rsi|olo|cif|pzi| pu|rit|nat|ers| ap|sti|rig| lo| cr| ag|co |ant|ume|ut |loc|son|rta|ues|lic|omp|ido|dat|pec|orr| ut|scr|sol|upp|ivi",
+  nl: "en |et |de |an | ge| de|ver|sta| va|een|van| be|and| in|nie| ve| ni|nde| he|er |iet|is | is| op|est|aar|bes|tan|ere|ken|oor|ing|te |den|ege| ee|het| on| vo|ie |tie|gel|der|gen| al|or |in |rde|nge| te|ren|ten|ord|aan|uit|nd |sch|erd|ste|voo|eer|ers|rd |eld| me|gev|geb|ng | ma| wo|wor|ven| to|lle|ls |rui|naa|eve|cht|ar |dig|ebr| st| ka|bru|kan|uik|eke|el |voe|men|gee|met| ui|len| aa| re| en|ige| pa|ent|als|ter|ard|es |ati| na| wa|ond|ge |kt |nen| bi| co|end| di|ele|ach|erw|eli| of|at |waa|oer|kke|of |it |ijd|st |lij|all|wij|ldi|pak|dt |al |ont|le |akk|geg|opt|rdt|slu| do|nt |ket|bij|tal|reg|out|ong|op |nst|ens|aat|con| da|tek|fou|tel|one| om|pro| zi| ko|pti| ar|ij |ind|ike|eze|ove|ges|lee|chi|map|pen|wer| pr|ijn|ake|am |zij|taa|ree|aam|ut | fo|tte| le|ijk|sie|jde|maa|jn |lin| ov|ell|ig | mi|nte|toe|rwi|erk|daa|ap |ang| mo| we|ist|om | af|rei|ppe|dat|gro|re | sy|kop|ins|ns |ld |nda|ies|tee|ton|rij|oeg|esc|ht |wac|on |laa|gin|mis|ker|hte|die| er|ngs|itv|tvo|ert|vol|ite| gr|ze |evo|ukt|luk|eel|nds|din|aal|isl|tro|tij| u |pel|eid|doo|che|ume|ke |rt |dit| zo|chr|ron|ett|erv|com|ik | sc|mak|eri|oep|rsi|ame|ale|rs |hee|ieu|arg|euw|del|ene|dez|rsc| el|aak|nvo|nta|rst|kel|rte|lui|erg|ede|ijz",
+  sv: " in|en |er |för|nte| fö|te |ing|int|era|ör |et |ter|ar |de |ra | an|tt |nde| de|änd| st|ill|ng |nin|ll |an | ti|ta |ler|til| en|vän|är |ade|ion|om | me| i |and| av|sta|ver|fil| ko|lle|att| fi| är| ka|med|tio|nda|kti| sk| at|anv|nvä|rad| ut|ste| re|tig|gen|rin|av |ed |ell|den|kan|yck| so|on |var| vi|fel|tal|ad |eri|nge|nd |som|ata|es | om| fe|des| på| va|ist|und|ett|der|as |ig | lä|det|på |el |tan|na |ch |at |kom|ekt|nam|ent|lti|ort|ska|cke| oc|ngs|gt |ser|tta|ile|och|men|ilt|mma| el|amn| mi|nt |all|nga|ati|dat|akt|igt| se|nst|str|ara|nta|ga | ar|gil|cka|skr|lag|ers| et|mat|rt |upp|inn| sa|la | ta|kri|st |kat|ela| ha|dar|re |ren|eck|stä| fl| fr|riv|log| sy| pr|sa |kon|gar|lis| gi| pa|ogi|äll|kad|änt| og|agg|lla|for|lig|pro| vä|mer|tor|ärd|are|öve|ang|ant|kun|end|len|ka |mn |omm|ns |tad|id |ons| be|rat|tar|frå|man|ner|orm|lut|rer|mis|ins| ku| ny|rde|tet|uta|al |or |ind|slu|one|lyc|ket| än| öv|il |fla|rar|rma|iss|rån|ive|ån |vis| up|äng|ndr|har|ssl|it |vär|mme|ran|alo|sly|kal|del|ut | al|reg|fin|sto|ens| ma| må|tat|ess| bo| gr|kt | ra|ätt| ve|sym|kni|iv |git|isa|rd |vid|da |ign|sek|bor|ras| du|che|nne|ken|sök|per| si|amm|ark|mbo|tiv|bol|täl|ast|sam|ymb|stö|kän|gra|ts |sig|dra|in |nna",
+  da: "er |et |en |kke|ke |for|ikk| ik| fo|til|ere|nde|ing| de| ti|il |or |de | af|der| in|ter|ler| er|lle|es |ed |ver|fil|ne | me|ind|re | fi| en| st| i |end|ng |af |den| ud| ka|te |sta|ret|tte|ive|ger|ent|bru| ko|ste|rug|at |an | br|gen|nge|ede|kan|and|nte|med|ang|se |ion|und|ers|dig|skr|om |els|lse|det|tal|ell|og | ve|nin| sk|le | so| at|mme|nne|kri|lin|rin|al |men|lig| op| an|ig |ejl|fej|ker|kun|eri| fe| un| el|yld| og|del|ata| ku| re|ldi|som|gyl|gle|tio|gt | li|dat|tet|giv| vi|avn|rer|nav|ile|uge|ern|ati|on |vis|ge |riv| et|ngs|all|kom|jl |el | pa|ren| ma| ug|ken| fr|str|ugy|eks| pr| ad|ndt|vær|kal|ved|st | på|des|ven| sy|dt |nøg|øgl|res|ser|ngi|på |pro|ska|is |ett|vet|unn| be|ort|igt| nø|ove|iv |kon| hv|val| ar|len|man|lde|jer|fra|ige| se|mer|ill|pak|dre|akk| væ| al| si| bl|mat|nst|dsk|ra |age|inj|nje|afs|kat|ner|var|orm|pe | mi|rne|rst|nd |rel|sti|tan| læ|ug |ndr|ist|vn | fl|ar | ge|fin|ens|ppe|ske|log|rt |omm|sel|stø|alg|lut| te|amm|hed|sni| sa|lag|ert|red|slu|ve |rsk|rdi|rma|egn|lok|lem| ha|ble|uds|tre| ov|teg|sen|ont| da|dst|int| he| om|pre|sse|tem|lt |opr|sym|nt |tat|elt| ek|ign|rte|sam|old|ude|eli|ode|kti|vne|ift|tid|ore|ark|hol|ons|ide|typ|ess|tor|ype|one|nda|ard| gr|met|fte",
+  no: "er |kke|en |et |ke |ikk|for| ik|ing|il |te | fo| er|til| ti|or |ler|ter| av| en| in|re | me|lle|ng |ver| de| st|bru|ruk|av | br|tte|ed | i |om |ent|rte| ut|fil| fi|es |ig |ere|de | ve|ste|ett| sk|opp| å | ko| va|alg|val|all|ell| so|sta|nde|dig|ert|end|inn|nne| op|art|tt |nge|som|ne |med|der|ker|and|ldi| kl|og |lar| og|kla|skr| på|rt |nte|lin|på |vis|eil|fei|rer|det|den|men| el| fe|kri|yld|gyl| si| et|rin|tal|ser|kel|ll |uke|dat|is |mme|gen| le|nøk| li| se|sjo|jon|se |tet| ma| ug|ugy| ka| hv| nø|ata|avn|len|riv|nav|ppe|le |økk|nt | vi|el |an |kan|ger| pa| du|ge |var|dre|gt |man| pr|jen|kom|ner|lde|lgt|ign| re| un|nda|res|du |ndr|utt|und|eks|uk |ar |iv |are|ers| la| fr| mi|vn |lig|ist|lag|ren|on |ngs|lge|app|ern|fra|ede|ten|eri|inj|ta |at |pro| ar|mer|ang|jer|ele|nje|pe |ene| an|egn|str| al|ret|hvi|ill|sig|atu|els| be|ra |ska|kal|kon|før|omm|ant|enn|teg|map| sl|ut |lg | he|ved|ort|lut|ove|lse| ha|ont|al |tat| te|ile| bl|les|slu|nta|nin| sa|orm|tre|eng|tes|st |set|rd |mma|gje| ta|nst|rma| ov|ven| fø|met|ive|fik|rdi|ord|ess|ndo|ard|ate|ass|tid|tan|gna|rti|gn |del|vel|arg|stø|old|mel|sse|amm|erd|per|ens| na|asj|nn |sen|hol|kst|sti|sam| gj|ram|dar|akk|att| ny|jør|elt|ume| gr| to|lt |ble",
+  fi: "en |ist|on |ta | ei|ei |ett|nen|ell|ine| va|in |sto| kä|le |ost| ko|tet| vi|oit|lli|sta|tie|äyt|lin|an |sa |ssa| tu|vir| ol|tä |edo|rhe|tta| on|ied|irh|käy|dos|ole|lle|ttu|itt| ti|ste|een| si|taa| ta|eel|tu |ton|tee|ite|itu|tus|aa |ttä|ja |tel|us | li|ise|nni|lit|hee|aan|nis|tte|mis| lu|tun|lla|ava|stu|la |ali|ent|lis|ess|ytt|ksi|men|rit|ia |tti|koh|mat|hte|all| sy|tää| lo|sti| ar|voi|än | sa|mää|kis| mu|ime|sen|äär| vo|to |ää |mer| pa|si |utt|val|ato|enn|nim|joi|tav|ään|vai|ain|set|et |imi|isä|eri|eta|sym|tai|oli|ois|oso|ita|tii|luk|oht|soi|ivi|min|onn|käs| ja| tä|est|bol|ymb|mbo|ill|its|loh|ohk|hko| la|epä|ake|eki| ep|uut|kki| as| ku|iin|sä |tul| jo| su|ote| ka|uku|oll|ter|lä |tam|ssä|aus| re|etu|sis|per|oi | po|tui|nne|ytä|var| se|ees|tsi|ui |ti |stä|ema|rek| ha|ai |kir|aik|ust|irj|va |arv|ume|erk|ama|kse|int|lue|ark|sii|päo|äon|nta|dot|ope|ty |ata|äri| jä| al|ase|ses|uot|uet|koo|vaa|ais|nte|te |att|he |ran|odo|era|net|tty|elm| ri|sek|ulo|ami|rki|ri |rvo| ni|oa |sky|uks|äsk|vat|sim|los| pi|ko | ki|unt|ros|rkk|kem|iss|oko| op|alu|lii|ori|at |na |poi| to|tyy|ijo|isi| me|iä |ota| oh| x |and| mä|ude| en|llä|täm|sij|uva|suo| vä|ien|ot | ty|iir|ood|unn|ulk|del|un |ast| pu|itä|kai|sit",
+  pl: "nie|ie | ni| po|ani|na | pr| wy| za|ia |wan|nia| na| do|eni|owa|sta| je|ch |rze|lik|ny |prz|ne |go |pli|ego| pl| mo|ów |moż|ści|st | w |est|pod|ych|pis|jes| ko|any|wie|żna|ożn|ji |awi|ać |ej |do |zna|ku |rzy| od|ost|uży|raw|ane|cze|czy|dan| li| uż|nyc| z |cie|ien|cji|je |pra| st| bł|ier|cza| us|la | si| op|ika|tu |ię |zen|się|no |kat|pro| pa|iku|kon|nik|owy|owe|yć | in|ent|em |neg|oda|kie|kow|wa |naz|azw| i |wy |acj|za |pow|zmi|ci | ro|owi|ka |czn|zy | se|dzi| zn|ja | re|ale|ywa|ami|bra|ym |zyt|era|dło| ka|mie|mia| ty|su | ob|ki |cja|ik |bie|tal|zas|icz|orz|jąc|ucz| zm|ło |ini|yst|luc|iet|war|klu|alo|aln| dl|dla|ony| wi| kl|ków|tan|pcj|opc|taw| we|zap| ma|ko |ust| cz|zon|men|for|ty | sk| sy|ak |api|dow|łow|row|roz| ar|ist|jśc| lu|ośc| te|ąd |ić |lic|ole|błą|tor|str|łąd|ano|art|pol|ian|log|lub|ub |ocz|two|ion|wor|ść |rto|orm|ez |aki|ume|zan|acz| gi|li |ako|rma| al|to | sp|kcj|one|szy|ra |it |ata|odc|git|wym|isa|ącz|łąc|ana|fik|rak|nal|ran|res|ość|dcz|iep|by |le |pak|tów|ers|yfi|wid|gra| wa|poz|toś|obi|sek|wyk|trz|ięc|ące| ja| zo|nej| ws|dni|zos|lec|ast| br|wni|wyp|iej|nak|mi |wej|uni|wer|uje|now|jak|tow|yma| da|idł|lin|błę|łęd|zys|zek|ono|zie|ług|ste| to|stę|we |ikó|ktu|iwa|ece|że ",
+  cs: " ne|ní | po| př| pr|je | na|pro|sou| se| so|ení| je| vy|na |oub|ubo|bor|sta|pře|ová|ze | za|ván|ný |né |ova|ání|se | ch|at |uje|chy| od|hyb|ch |rov|vat|pou|it |ce |ho | do|uži|při|neb|pod|ro |no |pří| v |lze|zna|ou |ost|lo | st|nel| ná|elz|stu|kon|or |ru | ko| a |líč|oru|ent| ve|te |ouž|res|ná |nep| kl|klí|cí |lat| vý|to |kaz|nen|ba |em |le |nač| ba|atn|tel|en |ast|ky |tav|ku |men|ých|ový|pla|ebo| ad|tup|bo |adr|dre|ate|odp|vyp|slo|pis|ny |řep|yba|vol|ři |zen| zn|tu | ob|ého| s | zá|ína|byl|prá|str| ar|nov| sp|van|pín|epí| ro|bal|ové|hod| ja|řen|dno|vý |řád|če |ím |ako| in|měn|ka |ek |oče|řík|íka|tí |jak|nam| řá|ter|lov|sel| re|dat|odn|nak|sti|alí|náz|esá| sy|pov|čís|st |ver|nas|led|iva|lož|sář| už|et |mu |por|epl|án |ist|živ| da|for|raz|dpo|ta |zad|ace|ící|áze|orm|tov|ně |ově|ty |az | ce|poč|alo|ráv|la |pra|eno|že |áno|nos|íst|ale|ko | by|dov|řed| čí| sk| al|kov|ten|lík|ti | zp|ezn|mén| pa|aný|oku|žit|ume|ry | ho|ech|vyt|do |not|še |tný|jíc|roz|elh|čas|čen|ísl|ytv| jm|ran|edn|ač |sle|dní|ven| no|zí |sah|by |ak |ci |hal|de |lha|pol|oro| to|tní|ádk|íč |nou|žád|jmé|tra|čet|nýc|ave|pos| zm|ick|změ|odk| z |íče|ují|ele|bud|ací|zev|ev |cho|ifi|oto|nez|lic| u |dán|vé |fik| žá|ali|ače|ího",
+  tr: " bi|lan|eri|ir |in |en | de|lar|ama| ya|bir|anı|ler| do| iç| ge| ve|yor|an |arı|içi|ile|er |or | ol| ba|ası|len|dos|çin|osy|sya|lam| ka|ara|ya |değ|eçe| ku|dı |ak |ini|eği|kle|sı |ıla|lla| se|lem| sa|ste|ull|ar |alı|ene|le |kul|ma |ri |ili|ekl|de |çer|adı|nde|bil|eme| ha|şle| ye|ını|nda|ni |ır |geç|da |ind| be|si | gi|ala|esi|ayı|iz |rı | di|eti|iyo|den|eni|lir|dır|ın | ko|rin|lı | bu| ta|tır| il|rak|nı | ar| pa|tir|di |mad|ola| al|ana|yen|li | iş|eli|ata|baş|işl|ek |yaz|iri|ne |me | ad|ter|siz|ik | ay|aya|uru| yo| so|ınd|rsi|hat|izi|ve |ıyo|sın|ers|sin| gö|tar|ırı|ki |ist| da|seç|ere|bel|la |ril|lma|edi|it |tan|ok |ğiş|yal|and|rın| an|ine|diz|ver|ket|lik|say|şti|çık|ılı|ısı|atı|yas|leş|emi|rıl|rla|nın|dan|son|ele|ula|zin|amı| si|rma| ça| ön|yar|nım|ürü|nam|ldı| çı|bu |çen|mi |rle| i |yok|isi|erl|ış |ğer|yer|eye|mey| ki|eya|eğe|vey|rul|dir|ger|olu|ndı|yap|kar| he|nme| sı|enm| bo|man| sü|kte|rme|onu|ken|ği |ta |nin|mas|ake|ilm|unu|lle|git|bağ|pak|et |il |na |ndi|nek|al | ek|azı|sat|mak|çal| te| tü|end|nıl|lış|miy|iği|num|ıml|yan|ird|ştı|ız |iml|par|abi|ell|nce|sür|iş |aşa|nla|lin|olm|ağl| ne|gir|ulu| uy|el |may|med|eks|apı|arl|tek|cı |aht|tur|mıy| in|hta|nah|ına| li|tem|mış|irt|rek",
+  id: "an |kan| da|ak | di| me| ti|ida|dak|tid|ng |ang|si |men| pe|at |eng|ah | se| be|ala|ber|kas| ke|ter|per|nga|ika|ri |ari|uk |asi|ntu| in| un| te|al |gan|unt|tuk|as |da |ata| re| ta|apa|rka|yan|ada|pat| ya|dal|erk| ba|lam|dap|dar| ko|am |ali|ama|mem| de|aka|uka|ran|er |era|nya|pen|ung|it |ar |seb|tan|ma |ara|eri| pa|lan|ing|una| ad|gun|nam|bua|ai |emb|han|lah|ngk|nda|ya |ngg|and|gal|den|aga| si| ga| sa|nak| va|ini|is | ha|nta| bu|mba|id |dan|val|ena|ebu|ila|ke |lid| at|ent|bar| na|eks|ela| ma| st|tak| ja|ni |gka| su|rin|us |or |isi| op|mas|et |di |int|en |elu|tor|kom|erl| ar|tau|str|iha|ka | bi|set|au |pil|uah|ta |bol|ste|gag|ist| an|bag|aru|lik|ket|sta|bah|lua|ori|dir|tar|ili|lok|ipe|ers|kun|lih|in |oka|rek|git|on |uar| gi|lai|ode|ol |ban|atu|kon|dia|uat|dik|sim|tu |mat|aba|uku|jan|tik|de |end| pr|ura|ris|ire|ekt|tam|esa|ek | co|ind|rsi|san| la| ka|alu|ik |hka|esi|el |har|mbo|emu|nal|bel|ggu|for|amb|ver|any|uan|mbu|lka|asa|buk|ksi| ca|ruk|nde|tem| no|eta|ti |rma|akt|orm|ert| lo|aik|imb|ope|did| ak|tah|ens| po|aan|kel|dis|rel|es |dit|ite|sal|aha|reg|pad|eba|ati|ks |ant|nti|pro| le|erb|jal|ian| pi|pa |nsi|nst|sik|asu|tka|ole|aca|tif|rus|pak|dip|le |tas|eti|suk|ut |ike|mod|kto|bun|ere",
+  ro: " de|de |te |re |are| nu|ea |ul | se|ent|rea| în|tă |nu | fi|le | co|iun|ntr|ate|est|ste| in|fiș|ier| pe|at | re|se | es|iși|rul|șie|tru| a |une|în | di|ză | ne|ază|ui |țiu|pen|ru | pr|car|ie |eaz|num|la | po|oar|lui|nea|ele| la|men| ca|ulu|ere|ile|ire| cu| un|ume|nte|or |ist|con|tat|ne |ați|tor|che|val|ali|ter|int| ac| ex|ect|sta|cți|ată| li|ii |liz|com|ica|un | su|fic|ces| ar|nt |iza|eru| fo|er |cu |ște|oat|ver| da|tul|ili|că |ifi|sec|ero|loc| st| si| op|ri |ră |rec|pre| o |uni|it |să | și|ți | ma|til| er|ecț|uti|poa|pro| pa|al |ini|alo| ut|ut |bil|ori|imb|oca|roa|tar| al|uri|și |ia | va|pți|siu|ecu| sa|au |act|tur|rar|din|tre|tra|for|ar |in |ta |ace|orm|rma|imp|opț|ici|lid|ei |cat|des|lor| ti| s |lic|cit| ve| b |eri|nec|sau|sim|str|st |res| pu|dat|pri|me |eșt|zat| me|ina| să|șir|ara|lă |cte|ato| sc|înc|rat|omp|ca |ite|mbo|bol|ce |par|ări|țin|ers|chi|ime|ine| af|lul| tr|ept|abi|tri| ci|per|cut|oru|tiv|hei| ch| mo| im|ril|por|ții|utu|lin|pta|mul|id |ită|put|esa| sp| ad|cri|esc|tab|olu|ție|min|rie|tip|eși|dir|scu|eva|tea|het|stă| no|mat| do|imi|pli| ie|dă |scr|afi|mpl|eci|rsi| lu|cep|eal|ort|mai| au|nev|inf|ică|rel|cre|ai |ive|ieș|and|nfo|ale|spe| ce|eta|cun|iți|ach|mel|ost|pul|ide",
+  hu: " a | ne|em |az | me| az|nem|en |ele|fáj|ájl| ki| sz|ása|tel|meg|tt |sa |és | fá|gy |len|tás|cso| ha| el| le|egy|asz|nál| be|et | ka| ér|ek |ara|tés| va|ok | eg|has| hi| kö|men|agy|sze|szn|ás |ak | cs|hat|ssz|zná|ncs|es |jl |ény|ése|ítá|fel|sít|an |ett| fe|ért|lt |lít|se |sol|rás|tal|áll|at |hoz|tó |ter|kap|vén|ott|apc|pcs|ató|jel|for| mi| al| ta| fo|ene| ke|het|ker|ja | és|vag|tár|or |cs |szá|zés|kez|tum|hib|ere|oló|érv|eze|min|rvé| z |ált| ad|kor|sza|al |zet|llí|net| pa|íté|ála|lha|mez|zám|zás|lat|rak|par|akt|sor|si |írá|ent|ran|lás|ely|rte|elm|ező| re|ba |el |nak|va |nt |nyt|lme|ni |yte|gye|re |int|ség|ló |vál|ány|szt|let|iba|anc|alá|kar|eg |lye|ra | ho|lis| so|zer|hel|tar| vá|end| tö|ren|is |ala|kön|nyv|ato|ik |ez | ar|öny|kte|név|tet|er |nek| he|ind|orm|art|eál|yvt|vtá| te|os |oz |sik|sak|rté| ni|ti |rmá|um |öve|ete|inc|nin|dat|les| si|tot|esz|ha |ezé|csa|ték|ega| je|oma|ban|gad|rt | lé|ike|ége|iss|atá|mag|sok|ell|beá|ada|us |ár |alm|elő|ozá|öss|év |vet|nde| ál|ve |ül |ző |áso| li|szi|ehe|rül|som|tre|ára| né| ké|lap|elt|eti|elh|leh|val| ös| vi|lva|nye|áló|lét|ver|ész|át |erü|ntu|ben|lma|on |ume|ásá|kií|iír|lok| in|ta |ók |maz|lle|ont| pr|nev|olá|ot |köv|eme|eje|eté|tat| ez|res",
+  vi: "ng | th| kh| ch|ông|hôn|khô| tr|nh | ph| ti| nh|ên |in |ập |tin| gi|ác | cá|tập| tậ| đư|các|ỗi |hi |ược|ợc |thể|hể |ần |ch |đượ|ho | ng|có | có| hi|ục | là| và| lỗ|lỗi| đị|ới |ùng|số | số|ối |ột |ết |ong|cho|ại |tro|ron|ủa |của| củ| qu|ịnh|địn| mộ|chu|một|ển | lệ|khi|là |dùn| dù|mục|hiệ|tha|chỉ|thư|tên| tê| mụ|hỉ | tạ|iên| đã|đã |ay | sa|hư |ầu |iệu|ệu |ra | li|ặp |ào |hay|ọn |ải |phầ|hần| đầ| bả| ký|với| vớ|ất |họn|chọ|ặc | kế| gặ|gặp| ra|đầu|ký |kết|ến |và | đố|bản|ạng| đặ| ki|ếu |đối|nhậ|ình| lạ| bỏ|bỏ |iểu| vi|tùy|ích|ao | tù| nà|ườn|ờng|lại|ểu |ài | để|để |hợp|ợp | hợ|ản |ời |it |ện | bi|iện| từ|hiể|òng|huy|ang|vào|ghi|bị |uyể|yển| bị|ạn |ặt |ưa | gh|ai |đặt| độ|ùy | đổ|từ | tư|git| ho|kho| dò|ệnh|lện|ày | cả|ổi | đa|tự |ách|đổi|dòn|chư|iến|hiế|gia|kiể|hàn| bộ|ành| tự|ống|lệ |phả| đi|bộ |hải|iển|ọc |liệ| cầ|ượn|ợng|ấu |hị |chi|ấy |việ|au |ạo |ánh| ha|tạo|ật |này|ều |ân |thô|đan|iều|àm |óa |hân|hưa|dạn|ẫn |thứ|qua| xu|thị|như|ảnh|trư| đọ|đọc|oặc| sử|trì| dạ|ói | bạ|ệc | cấ| dụ|iệc|ảng|ộng|rìn|eo |hoặ| tí| gó|gói|ung|the|ơng|ươn| lư|sai|bạn|heo|ây |tiế|áo |ụng|thà|ái |dụn|thi| tì|iếu|giá| lầ|lần|trợ|rợ |tượ| co|hiê|cần|ức | vị|iao|vị |tìm|ìm |trị|rị |iá |ua |úc |ngư|phâ|ận |iết",
+};
+
+export const CYRILLIC_PROFILES: Record<string, string> = {
+  ru: " не|ть |ени| по| пр|не |ие |ние|пол| в |ия | за|ать|ый | ко|ова|ся |оль|ля |мен|но |стр|ет | ра|фай|айл| фа| дл|ния|ка | вы|пер|тся|ный|ить| со|про|для| на|ани|го |етс|ват|ров|раз|нны|на |ой |вер|льз|пре|ало| ис| па| пе|ере|уда|дал| от|ов | уд| до|спо|ии | об|льн|анн|ста|ого| си|тро|ком|ом |ост|сь |ред|ест|ств|дел|ли |ван|ки | ст|нов|ое |ые | ре|ает|исп| ка|зов|уст|чен|ла |под|ент|пис|сти|лен|при| из|ая | ин| с |дан|ых |ует|тел|еме|сим|мет|иро|ий |клю|люч|нач|ель| им|ось|енн|зна|лос|та |ера|ьзо|ист|кат|ите|лов|каз|тор| и |те |ект|жен| оп|щен|нев|рав|ива|зап|дер|оши|мож| ош|нен|шиб|вол|рам|ные|имв|ерж|рем|аци|мво|тан|анд|пар|пус|аме|ных|ибк|или|нно|аза|зме|ти |рок|ное|ен |ран|ног|име|ден|ата|сли|йл | ве|аче|бра| сл|бка|ате|жно|ции|ара|ход|ано| но| ил|ока|ок | то|мер|ржи|ра |ию |пра|сто|ей |воз|ика|етр|орм|фор|ной| ус|обр|фик|вле|ная|вае|зде|ука| бы|то |мещ| ук| зн| ар|оди|сле|олн|реж|кон|ожн|ьны|опу| кл|азд|еще|рма| да|пос|тны| мо|кци|ерн|ьно|йла|рек|чит|вод|тал|ри |ево| се|нст| эт|одн|ле | сп|оже|ыть|тву|да |лог| чт|ене|ер |оло|змо|пак|ома|по |рег|озм|тек|ави|тно|мя |ми |тов|опе|из |еги|доп|од |ифи|кет|ко |аль|ны |ман|ада|выв|гис| b |едо|ото|неп|это|ори|ном|ем |тр |льк|еде|зан|аке",
+  uk: " не|ти |ння|ня | по| ви|не | за|ува| пр|анн|енн|но |ий |пер|ати|ван|кор|ере|ів | ко| на|ся | до|від| у | ро|ори|зна|роз|ля |ист|на | пе|ого|ано|ста|про|ний|фай|айл| фа|го |рис|вик|ка |ити|для| дл|чен|ало|ико|ні |ено|их |тан|іст|оми|аче|нач|пом| си|ват| ст| пі| ві| па|пов|пис|мил|три|ть |илк|під|них| з |при|ки |ект|оре|до |стр|вда|рам|ми |каз|ани|сти|дал|ови|дан| зн| як| ре| бу|им |рек|діл| вк|сим|ося|вка|вол|лос|ає |ком|пар| вд| мо|зді|озд| ма|ент| об|льн|мож|нов|имв| да|мво|ії |ост|змі|сто|лен|вер|ом |ног|кат|аза| сп| ін|ктн|рес|зап|ку |ред|анд|опе|рим|тов|мет|ід |тьс|ься|лка| ти|еко|ара|ова|зан|ову|ряд|що | та|наз|азв|ою |ові|роб|аме|ути|мен|або|ок |вив| є |нек|ков|сту|жен| аб|тип|ри |апи|вор|етр| що|сув|тни|ла |тор|лів|клю|люч|час|ден|має|бо |ера|йл |бут|рит|есу|іль| ар|кон|ома|ідо|ції|за |ман|дом| кл|код|тво|фік| оп| чи| ря|ним|мін| вс|изн|ті |та |зав|ово|рів|ожн|му |еві|міс|ані|тув|ва |лу | ча|нев|пор|ідп|ами|су | се|кці|ядк|мат|нен|чит|рег| ка|аль|ої |ідн|дов|фор|орм|ств|ра |вув|вле|отр|тал|єть|иво| зм| ді|ифі|ій |йла| ве|ном|іка|ло |ому|вст|аці|тру|иве|пра| b |ран|ше |сть|вий|але| і |рук|гіс|егі|ну |ідт| мі|ерш|рма|ато|нем|трі|якщ|кщо|айт|рен|пос|оро|ата|пот|оди|нал|тек|дтр",
+  bg: "на | на|не | за| пр|ане| не|та | из|то | по|ван|те |да |за | да|ите| от|но |ата| се|ва |ия | е | ко|се |ен | фа|пре|айл|фай|ени|ран| съ|про| мо|оже|ка |мен|ред|ира|ни |мож|ето| в |ове|от |под|при|ава|же |ият|раз|ден| с |ция| оп|ани|ния| ст|ост| об| ре|ние|пра|ри | ра|ста|анд|ект|кат|ли |ът |изв| им|ат |ие | и |пол|ото|име| до|дав|ент|или|пци|опц|ежд|зва|нит|ест|ств|рав|тел|ма |ете|ход|нат|йл |ори|ята|дан|изп|лен|жда|нда|тор|нет|ави|сле| гр|неп| ин|ком|са |лед|сто| са|зна|ти | сл|аци|зад|рек|реш|ена|зве|ки |ома|дър|ве |ада|ато|it |ате|ман|вър|лов| то| gi|ят |каз|аде|тан|git| ди|лон|ода|веж| па|ива|гре|пис|нов|ука|йло|нос| ил|ява| бе|ез |вил|де |аза|дир|ват| ар| ук|пъл|епр| си| ка| кл|оме|ире|олз|лзв| въ|чен|ова|зап|ром|спе|ед |ешк|орм|ме |сти|кто|ст |рма|ко |фор|од |мат|ълн| бъ| но|усп| вр|бъд|рой|шка|во |изт|тов| къ|яне|ист|тва|ъм |еус|кет|дат|дад|към|арт|ети|мес|стр|обе|рем|ква|ърж|без|нен|нти|ржа|ешн|неу| пъ|ено|екс|три|бек|ене|ла | вс| зн|сва|гра|еме|дел|вер|рес|уме|изх|тно|али|ако|ъде|зат| ак|клю|люч|рия|еде|зхо|лно|лни|айт|зпъ|ема|зпо|нот|едн|пеш|вен|нал|ърв|тек| ни|ичн|ра |кло|ика|зи |ина| сп|мер|шно| та|вет|дар|аст|мо |той|има|або|пак| ве|лна|бро|ече| дъ|раб|бот|едо|тир|он ",
+  sr: "је | пр| по| да| не| на|ка |на | за|не |дат|да |тек| из|ато| је|оте|ње | ни|пре|тот|ња | ко| од|ста|за |ке | са|ори|но |ред|ије| у |ва |ост|про|под|ава|та |ања|ти | мо|ање|ма |ује|пра|оде|ист|им |ни | до|пис|те |мен|рав|исп|са |при| оп|ом |ли | ре| вр|ива| ис| ст| си|ниј|циј|рем|сти|зив|ази|зна|ан |кор| гр|ам |ја |или| ве|ако|нос|дељ|ека|спр|лаз|ку |еме|мог|огу|ак | се| и |ра |ова|вањ|иса|наз|рис|ављ|ван|ода|се |гу |ика|реш|тањ|ве |едн|одр|ла |пос|сим|нис|еке|поз|држ|гре|има|ија| би|сам| уп|ог |ење| бр| ка|тав|вре| ил|уме|адр|ко | b |ели|ешк|раз|ина|ент|ено|рој|дно|ем |бро|шта| ра| ди|нов|риј| ар|спи|ој |ена|опц|тор|имб|мбо|бол|ове|ема|пци|ну | ме|сто|гра|иск|ект|ата| та|рај|рек|шка|ени|ај | об|лик| ус|оме|дре|нак|неи|лич|чит|еис|ао |ита|их |спе|ржа|вел|сте| ос|ера|кљу|ључ|ију|усп|еку|ив |изв|дир|ора|аре|ису|изл| св|ара|ити|авн|зла|ире|озн|то |од |ави|ула|пот|вез|вља|ник|азн|ју |су |ите|неп| сп|чин|ака| су|ешт|епо|ичи| ак|ран|меш|тра|ани|оре|нем|вар|ст |ком|упо|рст|ви |сад|едб|врс|као|рам|так|нар|ци |ен |рад|нат|кто|еља|рењ|вор|тре|ово|ља |вер|љен|огр|аци|пом|пок|нав|ише|љак|јум|сно|едо|ају|ног|кој|мер|аз |сни|ано|ји |аје| зн|шав|азу| x |тај|суј|рик|ета| ун|бит|ира|реб|зап|ео |ења",
+};
+
+export const ARABIC_PROFILES: Record<string, string> = {
+  ar: " ال|الم|ير |ات |ية |ملف|مة |لف |غير| غي| مس|رة |مست|ند | في|تند| صو|ستن|الت|لا |لى | عل| مل|في | خط| لا|صور|لة | فش|دة |فشل|اء |على|لمل| تع|خطأ|طأ |ورة|يل |الأ|حزم|شل |الب| لل| اس|الح|ول |لمس| من|الق|تعذ|ار |يان|يف |لب | جد| إل|شيف| با|يم |من |صدر|در |أرش|رشي|الع| قا|الو| مص|شفر|مصد|الن|الر|يد | مح| عن| خا|مفت|سم |صوت|وت |فرة|وم |ال |اسم|الا| مج|لم | أر|بة | مت| سل| مع|بيا|يق | قر|حة |ليم|مكن|نات| بر|يو | شف|لحز|عذر|ام |امة|وي |انا|قرا|رف | أن|مسا| يم|يمك|لية| بي|قرص|رص | تر|توق|الخ|ها |لمف|زم |عمل|زمة|محر| أو|يمة|كن |طة |يدي|وقع|عة |حتو|مات|الة|تم |ذر |صال|الإ|سلي|أو |لوص|رمز| مر| سي|ديو|عد |صر |علا|لات|است|ور | تح|وع |يح |فات|لام|ون |ان |توي|فيد|دعم|قال|er |ين |راء|ءة |إلى| بع|دم |الد|كل |اءة|تاب| لم| وا| ر |نته|كتب|متو|عم |اح |ادة| مد|صحي|حيح|حرف|فة | مو|قيم|موع| ل |مكت| حز|دول|اد |فتا|تاح|مج |بال|ترو|كتا|تصا|الج| تن|لمح|لرم|ليل|مجم|وصل| يح|قع | مف| كا|بت | رو|جدو| كل|لقر| إع| تم|داخ|اخل|نة |ديد|انت|عنص|نصر|وح |rea|جمو|عند| ان| رس|روم|ريد|ثنا|لت |ملي|مدع| مش|اعد| تص| لي|يس |خام|رسا|يحت|بري| ma|وين|عاد|مل |رض |يت |شكل|أن |الص|ستخ|خدم|ناء|سال|فية|الك| فا| دا|يار| م |دعو|عوم|لكت| قي|سي |بعد| إن|لخا| نق|تخد| تس|ود |وعة|عدد|يات|لمك|الس|sta|فق |سكر|خط |زال| كت| تو|يب |ذا | نو|نوع|جدي",
+  fa: "ده | در|ست |ای |در | نا|نام|ان | بر|از |ار |نده|وند| خط|ام |رون| پر|رای|می |نی |خطا|پرو| پی| از|است|طا |ند | اس|رد |انی|وان| با| نم| نش|دار|شده| شد|ود | دا| را|برا|نمی|ید | ای| نو|دن |را |به |توا| یک| ها|یک | به| خو|اده|ته |کرد|های|تبر|بر |شان|معت|عتب|یست| فر|تن | یا|ری |نشا| نی| کر| تو|نه |بان| شک|شکس|کست|سه |این|ال |شود| مق|یر |نیس|کار|نگا| رو|مه | پا|فت |پیش|ین |شتی|تیب|ارد|نتظ|اخت|امع|یان|یبا|اند|خوا|نوی| شا|پای| پش|پشت| می|یا | کل|با |ویس|لی |امه| مش| شو| کن|مقد|قدا|وجو|داد|شد |وی | هن|یسه| مو|جود|نشد|هنگ| گر|مشخ|یش |دی |گام|کلی| ان| غی|غیر|پیا|ها |خته|نوش|اد |ورد|لید| بس|وشت|اری| بی|شنا|برن|رنا|خه | خا|ات |روی|شاخ|اخه| کا|یت |ون |ندا|بای|ور |ایا|ره |رفت|نما|فرا|یند|سیر|یم | سا|خور|ردن|ساخ|یاف|افت|انت| بد| گو|ندن|اید| عن|یه | سی|اه |نات|که | و |منت| مس|دست|ناس|شخص| تن|تنظ|نظی|ظیم|شکا|یاد|ودی|تظر|تظا|ظار|ریا| ند|یی |مسی| دس|بست|ینه| مح| مع|مان|ظره|عنص|نصر|یشک|اشن| وج| که|فرز|رزن|زند| هی| نس|اتو|تی |گزی| مت|سیا| شم|شما| گذ|مای|ادی|بود|ایج|یجا|جاد| حا|هیچ|یچ |فتن|ونه|صر | ور| گز|گذر| بو|soc|ock|نسا| دو|بل |موج|دون|ایی| طو|ناش| جا|ارج|رسی| ات|صال|بار| شن| وا|اس |بدو|ودن|کند|فاد|امت|مت | so|cks|ksv|sv |الی|له |ریخ|گون|بری|یار|علا|ساز|نش | سو|سته|ول | شی|زین|باش|طور|گاه|جزی| ار|ستف",
+  ur: "یں | ہے|ہے |نے |ور | می| کر|وں | کی| او|اور|میں|کی | کے|کے | کا|سے | کو| سے|ہیں|ریں|ان |کا |کو |تے | اس|ری | ہو| ہی| لی|یے |ہر |اری| اپ|اپن|ھی | کھ|کری|لیے|یا |کہ | پر| بھ| آپ|آپ |ات |ئی |ئے |نی | کہ|نا | دن| دو| پا| ای| مل|لک |اس |مت |بھی|یم | گئ| نے| با| دی|لے |یوں|ھر |دو | بی|ام | شہ|شہر|پر | ہم| تا|کھی|بار| ہر|اب | بہ|بہت|ہت |انے|یل | رو| ھر|دن | وا|بی |ملک| جس|جس |باد| زی|زیا|یاد|ادہ|دہ |حکو|کوم|ومت|سب |می |تی |پنے|روں|تو |مار|ھیل| گی| اگ|سی | ضر|ضرو|رور|گئی|وال|الا| جا| کل|ارش|رش |انی|ئیں|نوں|وری| من| مس| نہ| تع|تعل|علی|لیم|ہی |کرن|ند |کھا|روز| ھف|ھفت|تھ |ار |پاک|اکس|کست|ستا|تان|یک | آب|آبا|کرو| سب| بڑ|چی | قو|قوم|ومی| زب|زبا|بان| ان|گری|ریز|کار|کام|است|ستع|تعم|عما|مال|ال |ہوت|ابی|سند|لتے|ہما| سا|ین | خب|خبر|بری|وبا| مع|ملی|لیں|گی |اگر|گر | کس|کسی|ہو | تو|ہم | ٹی|ٹیم| حا| یہ|یہ |تار|ریخ|کھت|ھتے| مص|مصن| مش|کل | مو|وعا|عات| سم|سمج|مجھ|ھان|گئے|ھنے|پنی|جان|نچ |کیں| شا|یز |ہوئ|وئی| کئ|کئی|پان| مح|میا|ریو|یر | سف| اچ|اچھ|ھا |یدا|نہی| پو|پور| چل|چلت| تص| آت|توں| حک|بے | نئ|نئے|علا|کیا|حت |لاق|اقو|ائی| بچ|رنے| مق|ھائ|امی| جی| گل|گلی|لیو|انو|کر |دیک|یکھ|پنا|ای |میل| چی|صدی|کلک| ور|نہ |ہور|جد |شاہ|اہی| سی|پرا|ران|تا |وز |فتے|فتھ|وار| جن|جنو|نوب|وبی|ایش|یشی|شیا|ایک|ادی|دی |بیس|یس |روڑ",
+};
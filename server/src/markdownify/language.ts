@@ -0,0 +1,124 @@
+import { ARABIC_PROFILES, CYRILLIC_PROFILES, LATIN_PROFILES } from './language-profiles';
+
+export interface DetectedLanguage {
+  /** ISO 639-1 code, or "und" when the text is too short or ambiguous. */
+  code: string;
+  /** Dominant Unicode script of the text, e.g. "Latin", "Cyrillic", "Han". */
+  script: string;
+  /** Between 0 and 1; how far the winner is ahead of the runner-up. */
+  confidence: number;
+}
+
+/**
+ * Languages that own a script outright are decided by script alone; the rest
+ * need a closer look at the letters or words used.
+ */
+const SCRIPTS: Array<{ script: string; re: RegExp; code?: string }> = [
+  { script: "Hangul", re: /[\uAC00-\uD7AF\u1100-\u11FF]/g, code: "ko" },
+  { script: "Kana", re: /[\u3040-\u30FF]/g, code: "ja" },
+  { script: "Han", re: /[\u4E00-\u9FFF\u3400-\u4DBF]/g, code: "zh" },
+  { script: "Cyrillic", re: /[\u0400-\u04FF]/g },
+  { script: "Arabic", re: /[\u0600-\u06FF\u0750-\u077F]/g },
+  { script: "Hebrew", re: /[\u0590-\u05FF]/g, code: "he" },
+  { script: "Greek", re: /[\u0370-\u03FF]/g, code: "el" },
+  { script: "Devanagari", re: /[\u0900-\u097F]/g, code: "hi" },
+  { script: "Bengali", re: /[\u0980-\u09FF]/g, code: "bn" },
+  { script: "Tamil", re: /[\u0B80-\u0BFF]/g, code: "ta" },
+  { script: "Thai", re: /[\u0E00-\u0E7F]/g, code: "th" },
+  { script: "Georgian", re: /[\u10A0-\u10FF]/g, code: "ka" },
+  { script: "Armenian", re: /[\u0530-\u058F]/g, code: "hy" },
+  { script: "Latin", re: /[A-Za-z\u00C0-\u024F]/g },
+];
+
+/** Trigrams ranked per text and per profile (see language-profiles). */
+const PROFILE_SIZE = 300;
+
+const rankTables = new Map<Record<string, string>, Map<string, Map<string, number>>>();
+
+function profileRanks(profiles: Record<string, string>): Map<string, Map<string, number>> {
+  let tables = rankTables.get(profiles);
+  if (!tables) {
+    tables = new Map(Object.entries(profiles).map(([code, list]) => [code, new Map(list.split("|").map((gram, i) => [gram, i]))]));
+    rankTables.set(profiles, tables);
+  }
+  return tables;
+}
+
+/**
+ * Texts with fewer letters than this are reported as undetermined. CJK
+ * characters carry roughly a word each and count triple.
+ */
+const MIN_LETTERS = 20;
+
+/**
+ * Detects the language of a block of text: first by dominant script, then —
+ * for scripts shared by several languages — by comparing the text's most
+ * frequent character trigrams with each language's profile.
+ */
+export function detectLanguage(text: string | null | undefined): DetectedLanguage {
+  const sample = (text || "").slice(0, 20000);
+  const counts = SCRIPTS.map(({ script, re, code }) => ({ script, code, n: (sample.match(re) || []).length }));
+  const total = counts.reduce((sum, c) => sum + c.n, 0);
+  const cjk = counts.filter((c) => ["Han", "Kana", "Hangul"].includes(c.script)).reduce((sum, c) => sum + c.n, 0);
+  if (total + 2 * cjk < MIN_LETTERS) return { code: "und", script: "Unknown", confidence: 0 };
+
+  // Japanese mixes kana with Han ideographs; any real share of kana means ja.
+  const kana = counts.find((c) => c.script === "Kana")!;
+  const han = counts.find((c) => c.script === "Han")!;
+  if (kana.n > 0 && kana.n >= (kana.n + han.n) * 0.1) {
+    return { code: "ja", script: "Kana", confidence: round((kana.n + han.n) / total) };
+  }
+
+  const top = counts.sort((a, b) => b.n - a.n)[0];
+  const share = top.n / total;
+  if (top.code) return { code: top.code, script: top.script, confidence: round(share) };
+
+  const profiles = top.script === "Cyrillic" ? CYRILLIC_PROFILES : top.script === "Arabic" ? ARABIC_PROFILES : LATIN_PROFILES;
+  const scored = scoreProfiles(textTrigrams(sample), profiles);
+  if (scored.length === 0 || !scored[0].hits) return { code: "und", script: top.script, confidence: 0 };
+
+  const [best, second] = scored;
+  // The distances of related languages are close; a lead of a fifth or more is treated as certain.
+  const margin = second ? (second.distance - best.distance) / second.distance : 1;
+  return { code: best.code, script: top.script, confidence: round(share * Math.min(1, margin * 5)) };
+}
+
+/** The text's trigrams, most frequent first, counted as the profiles were. */
+function textTrigrams(text: string): string[] {
+  const counts = new Map<string, number>();
+  for (const word of text.normalize("NFC").toLowerCase().match(/\p{L}+/gu) || []) {
+    const padded = ` ${word} `;
+    for (let i = 0; i + 3 <= padded.length; i++) {
+      const gram = padded.slice(i, i + 3);
+      counts.set(gram, (counts.get(gram) ?? 0) + 1);
+    }
+  }
+  return [...counts.entries()].sort((a, b) => b[1] - a[1]).slice(0, PROFILE_SIZE).map(([gram]) => gram);
+}
+
+/**
+ * Cavnar and Trenkle's out-of-place distance: how far each of the text's
+ * trigrams sits from its rank in the profile, with trigrams the profile
+ * lacks costing the most. The smallest distance wins.
+ */
+function scoreProfiles(grams: string[], profiles: Record<string, string>): Array<{ code: string; distance: number; hits: number }> {
+  return [...profileRanks(profiles)]
+    .map(([code, ranks]) => {
+      let distance = 0;
+      let hits = 0;
+      grams.forEach((gram, i) => {
+        const rank = ranks.get(gram);
+        if (rank === undefined) distance += PROFILE_SIZE;
+        else {
+          distance += Math.abs(i - rank);
+          hits++;
+        }
+      });
+      return { code, distance, hits };
+    })
+    .sort((a, b) => a.distance - b.distance);
+}
+
+function round(n: number): number {
+  return Math.round(n * 100) / 100;
+}
@@ -0,0 +1,106 @@
+import { ConversionError, ConversionErrorCode } from './errors';
+
+/**
+ * Default per-conversion memory ceiling: none, unless MARKDOWN_MEMORY_LIMIT_MB
+ * sets one for the process. A ceiling makes one enormous page fail on its
+ * own instead of taking the process down, at the cost of failing pages that
+ * used to convert, so it is opt-in.
+ */
+export const DEFAULT_MEMORY_LIMIT_BYTES = Number(process.env.MARKDOWN_MEMORY_LIMIT_MB) > 0
+  ? Number(process.env.MARKDOWN_MEMORY_LIMIT_MB) * 1024 * 1024
+  : Infinity;
+
+/**
+ * Rough heap cost of one parsed node (element or text) with its attributes
+ * and parent/sibling links; generous, since overshooting only fails pages
+ * that were close to the limit anyway.
+ */
+const NODE_BYTES = 320;
+
+/** Thrown when a conversion's estimated memory use passes its ceiling; code INPUT_TOO_LARGE. */
+export class MemoryLimitError extends ConversionError {
+  readonly limit: number;
+  readonly estimated: number;
+
+  constructor(limit: number, estimated: number, stage: string) {
+    super(
+      ConversionErrorCode.INPUT_TOO_LARGE,
+      `Conversion aborted during ${stage}: estimated ${Math.round(estimated / 1048576)} MB exceeds the ${Math.round(limit / 1048576)} MB limit`
+    );
+    this.name = "MemoryLimitError";
+    this.limit = limit;
+    this.estimated = estimated;
+  }
+}
+
+export interface MemoryBudget {
+  /** Records that `slot` now holds `text` as a string, replacing what it held before. */
+  holdString(slot: string, text: string, stage: string): void;
+  /** Records that `slot` now holds `html` and the tree parsed from it, replacing what it held before. */
+  holdDocument(slot: string, html: string, stage: string): void;
+  /** The data in `slot` is garbage now. */
+  release(slot: string): void;
+  /** Adds `bytes` of output, which only grows. */
+  charge(bytes: number, stage: string): void;
+}
+
+/**
+ * Tracks the estimated memory one conversion holds at once: the input, the
+ * repaired copy, whichever parsed tree is current and the markdown produced.
+ * Each intermediate copy lives in a named slot that the next stage replaces
+ * or releases, so the check is against what is live, not against every copy
+ * ever made. The check throws MemoryLimitError once the estimate passes
+ * `limit`; an infinite limit (the default) disables it.
+ */
+export function createMemoryBudget(limit = DEFAULT_MEMORY_LIMIT_BYTES): MemoryBudget {
+  const slots = new Map<string, number>();
+  let output = 0;
+  const check = (stage: string) => {
+    if (!Number.isFinite(limit)) return;
+    let used = output;
+    for (const bytes of slots.values()) used += bytes;
+    if (used > limit) throw new MemoryLimitError(limit, used, stage);
+  };
+  const hold = (slot: string, bytes: number, stage: string) => {
+    slots.set(slot, bytes);
+    check(stage);
+  };
+  return {
+    // Strings are UTF-16 in memory.
+    holdString: (slot, text, stage) => hold(slot, text.length * 2, stage),
+    holdDocument: (slot, html, stage) => hold(slot, html.length * 2 + estimateNodes(html) * NODE_BYTES, stage),
+    release(slot) {
+      slots.delete(slot);
+    },
+    charge(bytes, stage) {
+      output += bytes;
+      check(stage);
+    },
+  };
+}
+
+/** Upper-bound node count without parsing: each tag can open an element, and each can be followed by a text node. */
+function estimateNodes(html: string): number {
+  let tags = 0;
+  for (let i = html.indexOf("<"); i !== -1; i = html.indexOf("<", i + 1)) tags++;
+  return tags * 2 + 1;
+}
+
+export interface Deadline {
+  /** Throws a TIMEOUT ConversionError once the time is up. */
+  check(stage: string): void;
+}
+
+/**
+ * Conversions are synchronous, so a time limit can't interrupt them from
+ * outside; instead the converter checks the deadline as it goes. Without a
+ * positive `timeoutMs` the check never fires.
+ */
+export function createDeadline(timeoutMs?: number): Deadline {
+  const end = timeoutMs && timeoutMs > 0 ? Date.now() + timeoutMs : Infinity;
+  return {
+    check(stage) {
+      if (Date.now() > end) throw new ConversionError(ConversionErrorCode.TIMEOUT, `Conversion timed out during ${stage} after ${timeoutMs} ms`);
+    },
+  };
+}
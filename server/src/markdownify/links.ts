@@ -0,0 +1,64 @@
+import * as cheerio from 'cheerio';
+import { URL } from 'url';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export interface LinkInfo {
+  url: string;
+  text: string;
+  rel: string[];
+  nofollow: boolean;
+  internal: boolean;
+  title?: string;
+}
+
+function hostKey(url: string): string | null {
+  try {
+    return new URL(url).hostname.toLowerCase().replace(/^www\./, "");
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Lists every anchor in the page with its resolved URL, visible text and rel
+ * flags. A link is internal when it points at the same host as `baseUrl`
+ * (ignoring a leading "www."); mailto:, tel: and other non-HTTP links are
+ * always external. javascript: pseudo-links are skipped.
+ */
+export function extractLinks(html: string | null | undefined, baseUrl?: string | null): LinkInfo[] {
+  if (!html) return [];
+
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+  const baseHost = base ? hostKey(base) : null;
+  const links: LinkInfo[] = [];
+
+  $("a[href], area[href]").each((_i, el) => {
+    const $el = $(el);
+    const href = ($el.attr("href") || "").trim();
+    if (!href || /^javascript:/i.test(href.replace(/[\x00-\x20]/g, ""))) return;
+
+    const url = resolveUrl(href, base);
+    const rel = ($el.attr("rel") || "").toLowerCase().split(/\s+/).filter(Boolean);
+    const text = ($el.text().replace(/\s+/g, " ").trim()
+      || $el.attr("aria-label")?.trim()
+      || $el.find("img[alt]").attr("alt")?.trim()
+      || "");
+    const hasScheme = /^[a-z][a-z0-9+.-]*:/i.test(url);
+    const host = /^https?:\/\//i.test(url) ? hostKey(url) : null;
+
+    const link: LinkInfo = {
+      url,
+      text,
+      rel,
+      nofollow: rel.includes("nofollow"),
+      // Without a base, a relative URL can only point back into the same site.
+      internal: hasScheme ? host !== null && host === baseHost : true,
+    };
+    const title = $el.attr("title")?.trim();
+    if (title) link.title = title;
+    links.push(link);
+  });
+
+  return links;
+}
@@ -0,0 +1,217 @@
+import * as cheerio from 'cheerio';
+
+export type ListFieldType = "text" | "link" | "image";
+
+export interface ListFieldCandidate {
+  name: string;
+  /** Selector relative to the item element. */
+  selector: string;
+  type: ListFieldType;
+  /** Attribute holding the value for link/image fields. */
+  attribute?: "href" | "src";
+  /** Share of items (0–1) in which the field was found. */
+  coverage: number;
+  samples: string[];
+}
+
+export interface ListPattern {
+  containerSelector: string;
+  /** Absolute selector matching every item: `${containerSelector} > item`. */
+  itemSelector: string;
+  itemCount: number;
+  score: number;
+  fields: ListFieldCandidate[];
+}
+
+export interface ListDetectionOptions {
+  /** Minimum number of similar siblings for a group to count as a list (default 3). */
+  minItems?: number;
+  /** Maximum number of patterns returned, best first (default 5). */
+  maxResults?: number;
+}
+
+const IGNORED_CONTAINERS = new Set(["head", "select", "datalist", "optgroup", "svg", "picture", "colgroup"]);
+const NOISE_SELECTOR = "script, style, noscript, template, svg";
+
+/** Classes that flip per item (state, zebra striping) and would split a list into several groups. */
+const UNSTABLE_CLASS = /^(?:active|selected|current|is-.*|has-.*|odd|even|first|last|hidden|visible|open|closed|disabled|focus.*|hover.*|js-.*)$|\d{3,}/i;
+
+const FIELD_NAME_HINTS = [
+  "title", "name", "price", "rating", "review", "date", "time", "author", "brand",
+  "description", "desc", "summary", "category", "location", "address", "sku", "stock",
+];
+
+/**
+ * Detects repeated sibling structures — product cards, search results, forum
+ * posts — and proposes an item selector plus per-item field candidates. Groups
+ * are formed from siblings sharing tag and stable classes; a group only counts
+ * if most of its items expose the same fields, which filters out menus and
+ * other lists of bare links.
+ */
+export function detectListPatterns(html: string | null | undefined, options: ListDetectionOptions = {}): ListPattern[] {
+  if (!html) return [];
+
+  const minItems = options.minItems ?? 3;
+  const maxResults = options.maxResults ?? 5;
+  const $ = cheerio.load(html);
+  $(NOISE_SELECTOR).remove();
+
+  const patterns: ListPattern[] = [];
+
+  $("body *").each((_i, parent: any) => {
+    if (IGNORED_CONTAINERS.has(parent.name)) return;
+    const children = $(parent).children().toArray();
+    if (children.length < minItems) return;
+
+    const groups = new Map<string, any[]>();
+    for (const child of children) {
+      const sig = signature($, child);
+      const group = groups.get(sig);
+      if (group) group.push(child);
+      else groups.set(sig, [child]);
+    }
+
+    for (const [sig, items] of groups) {
+      if (items.length < minItems) continue;
+      const fields = collectFields($, items);
+      if (fields.length === 0) continue;
+      if (fields.length === 1 && fields[0].type === "link" && averageText($, items) < 30) continue;
+
+      const containerSelector = cssPath($, parent);
+      const textWeight = 1 + Math.log(averageText($, items) + 1);
+      patterns.push({
+        containerSelector,
+        itemSelector: `${containerSelector} > ${sig}`,
+        itemCount: items.length,
+        score: Math.round(items.length * fields.length * textWeight * 100) / 100,
+        fields,
+      });
+    }
+  });
+
+  return patterns.sort((a, b) => b.score - a.score).slice(0, maxResults);
+}
+
+function stableClasses($: cheerio.CheerioAPI, el: any): string[] {
+  return ($(el).attr("class") || "")
+    .split(/\s+/)
+    .filter((c) => c && !UNSTABLE_CLASS.test(c))
+    .sort();
+}
+
+/** Tag plus stable classes, written as a CSS compound selector. */
+function signature($: cheerio.CheerioAPI, el: any): string {
+  return el.name + stableClasses($, el).map((c) => `.${cssEscape(c)}`).join("");
+}
+
+function averageText($: cheerio.CheerioAPI, items: any[]): number {
+  const total = items.reduce((sum, el) => sum + $(el).text().replace(/\s+/g, " ").trim().length, 0);
+  return total / items.length;
+}
+
+function collectFields($: cheerio.CheerioAPI, items: any[]): ListFieldCandidate[] {
+  const stats = new Map<string, { type: ListFieldType; selector: string; count: number; samples: string[]; hint?: string }>();
+
+  for (const item of items) {
+    const seen = new Set<string>();
+    $(item).find("*").addBack().each((_i, el: any) => {
+      const $el = $(el);
+      const found: Array<[ListFieldType, string]> = [];
+
+      if (el.name === "a" && $el.attr("href")) found.push(["link", $el.attr("href")!.trim()]);
+      if (el.name === "img") {
+        const src = $el.attr("src") || $el.attr("data-src");
+        if (src) found.push(["image", src.trim()]);
+      }
+      const ownText = $el.contents().filter((_j, n: any) => n.type === "text").text().replace(/\s+/g, " ").trim();
+      if (ownText) found.push(["text", ownText]);
+
+      for (const [type, value] of found) {
+        const selector = relativePath($, item, el);
+        const key = `${type}|${selector}`;
+        if (seen.has(key)) continue;
+        seen.add(key);
+
+        const entry = stats.get(key) || { type, selector, count: 0, samples: [], hint: nameHint($, el) };
+        entry.count++;
+        if (entry.samples.length < 3) entry.samples.push(value.slice(0, 200));
+        stats.set(key, entry);
+      }
+    });
+  }
+
+  const used = new Map<string, number>();
+  return [...stats.values()]
+    .filter((s) => s.count / items.length >= 0.5)
+    .sort((a, b) => b.count - a.count)
+    .map((s) => {
+      const base = s.hint || s.type;
+      const n = (used.get(base) ?? 0) + 1;
+      used.set(base, n);
+      const field: ListFieldCandidate = {
+        name: n === 1 ? base : `${base}${n}`,
+        selector: s.selector,
+        type: s.type,
+        coverage: Math.round((s.count / items.length) * 100) / 100,
+        samples: s.samples,
+      };
+      if (s.type === "link") field.attribute = "href";
+      if (s.type === "image") field.attribute = "src";
+      return field;
+    });
+}
+
+function nameHint($: cheerio.CheerioAPI, el: any): string | undefined {
+  const haystack = `${$(el).attr("class") || ""} ${$(el).attr("itemprop") || ""} ${$(el).attr("id") || ""}`.toLowerCase();
+  if (/^h[1-6]$/.test(el.name)) return "title";
+  return FIELD_NAME_HINTS.find((hint) => haystack.includes(hint));
+}
+
+/** Child-combinator path from `item` down to `el`, e.g. "div.meta > span.price"; ":scope" for the item itself. */
+function relativePath($: cheerio.CheerioAPI, item: any, el: any): string {
+  const parts: string[] = [];
+  let node = el;
+  while (node && node !== item) {
+    const first = stableClasses($, node)[0];
+    parts.unshift(first ? `${node.name}.${cssEscape(first)}` : node.name);
+    node = node.parent;
+  }
+  return parts.length ? parts.join(" > ") : ":scope";
+}
+
+/**
+ * Shortest reasonably stable absolute selector for an element: anchored at the
+ * nearest ancestor with a unique id, otherwise at <body>, using :nth-of-type
+ * only where siblings would be ambiguous.
+ */
+export function cssPath($: cheerio.CheerioAPI, el: any): string {
+  const parts: string[] = [];
+  let node = el;
+  while (node && node.type === "tag" && node.name !== "html") {
+    const id = $(node).attr("id");
+    // Counted by comparing ids: any character may appear in one, and a built selector can fail to parse.
+    if (id && $("[id]").filter((_i, other: any) => other.attribs.id === id).length === 1) {
+      parts.unshift(`#${cssEscape(id)}`);
+      break;
+    }
+    if (node.name === "body") {
+      parts.unshift("body");
+      break;
+    }
+    const sameTag = $(node.parent).children(node.name).toArray();
+    parts.unshift(sameTag.length > 1 ? `${node.name}:nth-of-type(${sameTag.indexOf(node) + 1})` : node.name);
+    node = node.parent;
+  }
+  return parts.join(" > ");
+}
+
+/** A CSS string literal, for attribute selectors: quotes and backslashes escaped, line breaks as code points. */
+export function cssString(value: string): string {
+  return `"${value.replace(/["\\]/g, "\\$&").replace(/[\n\r\f]/g, (c) => `\\${c.charCodeAt(0).toString(16)} `)}"`;
+}
+
+export function cssEscape(ident: string): string {
+  return ident
+    .replace(/([^\w-])/g, "\\$1")
+    .replace(/^(-?)(\d)/, (_m, dash, digit) => `${dash}\\3${digit} `);
+}
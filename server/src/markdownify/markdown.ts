@@ -4,8 +4,186 @@ import { gfm } from 'joplin-turndown-plugin-gfm';
 import * as cheerio from 'cheerio';
 import { URL } from 'url';
 import { AsyncLocalStorage } from 'async_hooks';
+import { normalizeTypography, TypographyMode } from './typography';
+import { insertTableOfContents } from './outline';
+import { DetectedLanguage, detectLanguage } from './language';
+import { computeTextStats, TextStats } from './stats';
+import { markdownToPlainText } from './text';
+import { computeSimHash } from './fingerprint';
+import { countTokens, TokenCount } from './tokenizer';
+import { emptySanitizeReport, sanitizeDocument, SanitizeReport } from './sanitize';
+import { extractDates } from './dates';
+import { markdownToAsciiDoc } from './asciidoc';
+import { markdownToRst } from './rst';
+import { markdownToSlack } from './slack';
+import { markdownToAirtable } from './airtable';
+import { normalizeAmp } from './amp';
+import { flattenShadowRoots } from './shadow-dom';
+import { EmojiMode, emojiFromImage, emojiShortcode } from './emoji';
+import { applyBidi, BIDI_DIR_ATTR, BidiMode } from './bidi';
+import { createProgressReporter, ProgressCallback, ProgressReporter } from './progress';
+import { createDeadline, createMemoryBudget, Deadline, MemoryBudget } from './limits';
+import { ConversionError, ConversionErrorCode, conversionErrorCode, ConversionErrorInfo, conversionErrorInfo } from './errors';
+import { selectElements } from './query';
+import { emptyRepairReport, repairHtml, RepairReport } from './repair';
+import { htmlBlocks } from './stream';
+import { applyRules, ConversionRule, renderRule, RULE_ACTIONS, RULE_ATTR } from './rules';
+import { emptyRedactionCounts, PII_KINDS, PiiKind, RedactionCounts, redactPii } from './redact';
+import { prepareInlineSvgs, renderSvg, SVG_ATTR, SvgAsset, SvgMode } from './svg';
 
-const _als = new AsyncLocalStorage<{ baseUrl: string | null }>();
+/**
+ * How literal markdown syntax characters in scraped text are escaped.
+ * - full: escape every syntax character (turndown's default behaviour)
+ * - smart: escape only where the character would actually change rendering
+ * - none: emit text verbatim, except that a "|" in a table cell is always
+ *   escaped, as it would otherwise split the cell
+ */
+export type EscapeMode = "full" | "smart" | "none";
+
+/**
+ * What to do with tables whose cells contain block-level content, which GFM
+ * table rows cannot represent.
+ * - inline: join the cell's blocks into one line separated by <br>
+ * - html: emit the whole table as raw HTML
+ */
+export type TableCellBlockMode = "inline" | "html";
+
+/**
+ * Markup the conversion emits. Every format goes through the same markdown
+ * pipeline; asciidoc (Antora), rst (Sphinx), slack (mrkdwn, for alert
+ * messages) and airtable (the rich text field subset) are rendered from its
+ * result.
+ */
+export type OutputFormat = "markdown" | "asciidoc" | "rst" | "slack" | "airtable";
+
+/**
+ * How content images are emitted. Emoji images follow `emoji` instead.
+ * - keep: as markdown images
+ * - alt: as their alt text only
+ * - drop: not at all
+ */
+export type ImageMode = "keep" | "alt" | "drop";
+
+export interface MarkdownOptions {
+  escapeMode?: EscapeMode;
+  typography?: TypographyMode;
+  tableCellBlocks?: TableCellBlockMode;
+  /** Insert a linked table of contents at the top of the document. */
+  toc?: boolean;
+  /** Deepest heading level listed in the table of contents (default 3). */
+  tocDepth?: number;
+  /** Include what the sanitizer removed in parseMarkdownWithMetadata's envelope. */
+  sanitizeReport?: boolean;
+  /** Include the repairs made to malformed HTML in parseMarkdownWithMetadata's envelope. */
+  repairReport?: boolean;
+  /** Output markup (default "markdown"). */
+  outputFormat?: OutputFormat;
+  /** Emoji drawn as images (default "unicode"). */
+  emoji?: EmojiMode;
+  /** Content images (default "keep"). */
+  images?: ImageMode;
+  /** Inline <svg> graphics (default "text"). */
+  svg?: SvgMode;
+  /** Elements removed before conversion on top of the built-in chrome, e.g. a site's related-articles box. */
+  stripSelectors?: string[];
+  /**
+   * The element holding the page's main content, used instead of guessing
+   * when it matches. Ignored by convertFragment and streaming conversion.
+   */
+  mainContentSelector?: string;
+  /** Custom rules for site quirks, applied in order. */
+  rules?: ConversionRule[];
+  /** Mask personal data in the output: every kind, or only those listed. */
+  redact?: boolean | PiiKind[];
+  /** Direction handling for right-to-left text (default "marks"). */
+  bidi?: BidiMode;
+  /** Called as the conversion advances, for progress displays on large documents. */
+  onProgress?: ProgressCallback;
+  /**
+   * Estimated memory one conversion may use before it is aborted with a
+   * MemoryLimitError. Unlimited by default, unless MARKDOWN_MEMORY_LIMIT_MB
+   * is set; Infinity disables a limit the environment sets.
+   */
+  maxMemoryBytes?: number;
+  /** Abort with a TIMEOUT ConversionError after this many milliseconds. */
+  timeoutMs?: number;
+  /**
+   * parseMarkdown converts block by block without building the whole
+   * document, for very large pages; see streamMarkdown for what changes.
+   */
+  streaming?: boolean;
+}
+
+/** Facts about the converted document returned alongside the markdown. */
+export interface MarkdownMetadata {
+  /** Language detected from the converted text. */
+  language: DetectedLanguage;
+  /** Language declared by the page's <html lang>, if any. */
+  declaredLanguage?: string;
+  stats: TextStats;
+  /** SimHash of the visible text; compare with hammingDistance. */
+  fingerprint: string;
+  tokens: TokenCount;
+  /** ISO-8601 publish date of the article, when the page gives one. */
+  published?: string;
+  /** ISO-8601 last-modified date, when the page gives one. */
+  modified?: string;
+  /** Present when `sanitizeReport` is set. */
+  sanitized?: SanitizeReport;
+  /** Present when `repairReport` is set. */
+  repairs?: RepairReport;
+  /** Present when `redact` is set. */
+  redactions?: RedactionCounts;
+  /** Present when `svg` is "asset": the graphics the markdown refers to by name. */
+  svgs?: SvgAsset[];
+  /** Present when the conversion failed unexpectedly; the markdown is then empty. */
+  error?: ConversionErrorInfo;
+}
+
+export interface MarkdownResult {
+  markdown: string;
+  metadata: MarkdownMetadata;
+}
+
+interface ConversionContext {
+  baseUrl: string | null;
+  options: MarkdownOptions;
+  /** Collects sanitizer removals when the caller asked for a report. */
+  report?: SanitizeReport;
+  progress?: ProgressReporter;
+  memory: MemoryBudget;
+  deadline: Deadline;
+  /** Inline SVGs taken out of the document, for asset and raw output. */
+  svgs: SvgAsset[];
+}
+
+interface ConversionExtras {
+  report?: SanitizeReport;
+  repairs?: RepairReport;
+  redactions?: RedactionCounts;
+  svgs?: SvgAsset[];
+  progress?: ProgressReporter;
+}
+
+const _als = new AsyncLocalStorage<ConversionContext>();
+
+/**
+ * Private-use placeholder standing in for "|" inside table cells until the
+ * markdown is emitted, so cell pipes can be escaped without touching pipes in
+ * ordinary prose.
+ */
+const CELL_PIPE = "\uE000";
+const CELL_PIPE_RE = new RegExp(CELL_PIPE, "g");
+const CELL_BREAK = "\uE001";
+const RAW_TABLE_ATTR = "data-md-raw";
+
+/** Prefix of the attributes conversion marks elements with (RAW_TABLE_ATTR, BIDI_DIR_ATTR, RULE_ATTR, SVG_ATTR). */
+const MARKER_ATTR_PREFIX = "data-md-";
+
+const CELL_BLOCK_SELECTOR = [
+  "p", "div", "ul", "ol", "li", "h1", "h2", "h3", "h4", "h5", "h6",
+  "blockquote", "pre", "dl", "dt", "dd", "hr", "br", "figure", "section",
+].join(",");
 
 const _turndown = (() => {
   const t = new TurndownService({
@@ -29,6 +207,14 @@ const _turndown = (() => {
     replacement: () => "",
   });
 
+  // Only placeholders that name a collected asset; a span that doesn't keeps its content.
+  const svgAsset = (node: any) =>
+    node.nodeName === "SPAN" && node.hasAttribute(SVG_ATTR) ? _als.getStore()?.svgs[Number(node.getAttribute(SVG_ATTR))] : undefined;
+  t.addRule("inlineSvg", {
+    filter: (node: any) => svgAsset(node) !== undefined,
+    replacement: (_content: string, node: any) => renderSvg(svgAsset(node)!, _als.getStore()?.options.svg ?? "text"),
+  });
+
   t.addRule("superscript", {
     filter: "sup",
     replacement: (content: string) => {
@@ -101,6 +287,17 @@ const _turndown = (() => {
       let src = node.getAttribute("src")?.trim() || "";
       if (!src) return "";
 
+      const mode = _als.getStore()?.options.emoji ?? "unicode";
+      const emoji = mode === "image" ? undefined : emojiFromImage(src, alt, node.getAttribute("class") || "");
+      if (emoji) {
+        if (mode === "unicode") return emoji;
+        // GitHub and Slack put the shortcode in alt.
+        return emojiShortcode(emoji) || (/^:[\w+-]+:$/.test(alt) ? alt : emoji);
+      }
+
+      const images = _als.getStore()?.options.images ?? "keep";
+      if (images === "drop") return "";
+      if (images === "alt") return alt;
       if (src.startsWith("data:")) return "";
 
       const _baseUrl = _als.getStore()?.baseUrl ?? null;
@@ -114,6 +311,51 @@ const _turndown = (() => {
   });
 
   t.use(gfm);
+
+  t.addRule("bidiBlock", {
+    filter: (node: any) => node.nodeName === "DIV" && node.hasAttribute(BIDI_DIR_ATTR),
+    replacement: (content: string, node: any) => {
+      const dir = node.getAttribute(BIDI_DIR_ATTR) === "rtl" ? "rtl" : "ltr";
+      return content.trim() ? `\n\n<div dir="${dir}">\n\n${content.trim()}\n\n</div>\n\n` : "";
+    },
+  });
+
+  t.addRule("rawHtmlTable", {
+    filter: (node: any) => node.nodeName === "TABLE" && node.hasAttribute(RAW_TABLE_ATTR),
+    replacement: (_content: string, node: any) => {
+      node.removeAttribute(RAW_TABLE_ATTR);
+      return `\n\n${node.outerHTML.replace(CELL_PIPE_RE, "|")}\n\n`;
+    },
+  });
+
+  // Added last so custom rules take precedence over the built-in ones for the elements they claim.
+  // The filter only takes elements whose mark names a configured rule; anything else keeps its own markdown.
+  const markedRule = (node: any) =>
+    node.hasAttribute?.(RULE_ATTR) ? _als.getStore()?.options.rules?.[Number(node.getAttribute(RULE_ATTR))] : undefined;
+  t.addRule("customRule", {
+    filter: (node: any) => markedRule(node) !== undefined,
+    replacement: (content: string, node: any) => renderRule(markedRule(node)!, content, node),
+  });
+
+  const defaultEscape = t.escape.bind(t);
+  const escape = (text: string, options: MarkdownOptions) => {
+    if (options.typography) text = normalizeTypography(text, options.typography);
+    const mode = options.escapeMode ?? "full";
+    if (mode === "none") return text;
+    if (mode === "smart") return smartEscape(text);
+    return defaultEscape(text);
+  };
+  // Turndown escapes every text node outside code, which makes this the place to count them.
+  t.escape = (text: string) => {
+    const store = _als.getStore();
+    const out = escape(text, store?.options ?? {});
+    store?.progress?.node(out);
+    // The escaped text is the markdown being built, which stays live until the end.
+    store?.memory.charge(out.length * 2, "conversion");
+    store?.deadline.check("conversion");
+    return out;
+  };
+
   return t;
 })();
 
@@ -151,29 +393,367 @@ const CHROME_WIDGET_SELECTOR = [
   ".skiptranslate", ".goog-te-banner-frame", "#goog-gt-tt", "#google_translate_element",
 ].join(",");
 
+/**
+ * Converts a page to markdown. Failures are thrown as ConversionErrors; a
+ * bug in the converter, which used to be logged and give "", is thrown as
+ * PANIC_RECOVERED, so "" now always means the page has no content.
+ */
 export async function parseMarkdown(
   html: string | null | undefined,
-  baseUrl?: string | null
+  baseUrl?: string | null,
+  options: MarkdownOptions = {}
 ): Promise<string> {
+  validateOptions(options);
+  const progress = createProgressReporter(options.onProgress);
+  let converted: string;
+  if (options.streaming) {
+    const blocks: string[] = [];
+    if (html) for await (const md of convertBlocks(html, baseUrl, options, progress)) blocks.push(md);
+    converted = finishDocument(blocks.join("\n\n"), options);
+  } else {
+    converted = convertDocument(html, baseUrl, options, { progress });
+  }
+  const markdown = renderOutput(converted, options);
+  progress?.done(Buffer.byteLength(markdown, "utf8"));
+  return markdown;
+}
+
+/**
+ * Low-memory conversion for very large pages, such as archived pages of
+ * tens of megabytes. The HTML is tokenized and converted one block at a
+ * time (see htmlBlocks), so no tree of the whole page is built and the
+ * input may itself be a stream. Yields the markdown in document order;
+ * concatenated, the pieces form the document.
+ *
+ * The page is never seen whole, so main-content detection is reduced to
+ * dropping landmark chrome and no title heading is added; `toc` and
+ * `outputFormat` are not applied, as they need the assembled document
+ * (parseMarkdown with `streaming` applies both). The memory limit applies
+ * to each block, the time limit to the whole stream. A block the converter
+ * fails on ends the stream with PANIC_RECOVERED instead of being skipped.
+ */
+export async function* streamMarkdown(
+  input: string | AsyncIterable<string | Buffer>,
+  baseUrl?: string | null,
+  options: MarkdownOptions = {}
+): AsyncGenerator<string> {
+  validateOptions(options);
+  const progress = createProgressReporter(options.onProgress);
+  let bytes = 0;
+  for await (const md of convertBlocks(input, baseUrl, options, progress)) {
+    const piece = bytes ? `\n\n${md}` : md;
+    bytes += Buffer.byteLength(piece, "utf8");
+    yield piece;
+  }
+  progress?.done(bytes);
+}
+
+async function* convertBlocks(
+  input: string | AsyncIterable<string | Buffer>,
+  baseUrl: string | null | undefined,
+  options: MarkdownOptions,
+  progress?: ProgressReporter
+): AsyncGenerator<string> {
+  const deadline = createDeadline(options.timeoutMs);
+  const svgs: SvgAsset[] = [];
+  progress?.phase("parse");
+  for await (const block of htmlBlocks(input)) {
+    // Each block's strings and trees are garbage once it is converted, so each gets a budget of its own.
+    const memory = createMemoryBudget(options.maxMemoryBytes);
+    const md = _als.run({ baseUrl: baseUrl ?? null, options, progress, memory, deadline, svgs }, () => {
+      try {
+        deadline.check("parsing");
+        memory.holdDocument("parse", block, "parsing");
+        return redactOutput(renderMarkdown(tidyFragment(block, options)), options);
+      } catch (err) {
+        throw recovered(err, "HTML→Markdown block conversion");
+      }
+    });
+    if (md) yield md;
+  }
+}
+
+/**
+ * Every failure is thrown as a ConversionError: the ones the caller can act
+ * on (too large, timed out, invalid options) as they are, anything else, a
+ * converter bug, logged and wrapped as PANIC_RECOVERED (see recovered).
+ */
+function convertDocument(
+  html: string | null | undefined,
+  baseUrl: string | null | undefined,
+  options: MarkdownOptions,
+  extras: ConversionExtras = {}
+): string {
+  if (!html) return "";
+
+  const { report, progress } = extras;
+  const memory = createMemoryBudget(options.maxMemoryBytes);
+  const deadline = createDeadline(options.timeoutMs);
+  const svgs = extras.svgs ?? [];
+  return _als.run({ baseUrl: baseUrl ?? null, options, report, progress, memory, deadline, svgs }, () => {
+    try {
+      progress?.phase("parse");
+      memory.holdString("input", html as string, "parsing");
+      const repaired = repairHtml(html as string, extras.repairs).html;
+      memory.holdString("repaired", repaired, "parsing");
+      memory.holdDocument("parse", repaired, "parsing");
+      const tidied = tidyHtml(repaired, options);
+      memory.release("parse");
+      const md = redactOutput(renderMarkdown(tidied), options, extras.redactions);
+      return finishDocument(md, options);
+    } catch (err) {
+      throw recovered(err, "HTML→Markdown");
+    }
+  });
+}
+
+/**
+ * Same conversion as parseMarkdown, wrapped in an envelope with metadata about
+ * the result so callers don't need a second pass over the markdown.
+ */
+export async function parseMarkdownWithMetadata(
+  html: string | null | undefined,
+  baseUrl?: string | null,
+  options: MarkdownOptions = {}
+): Promise<MarkdownResult> {
+  validateOptions(options);
+  const extras: ConversionExtras = {
+    report: options.sanitizeReport ? emptySanitizeReport() : undefined,
+    repairs: options.repairReport ? emptyRepairReport() : undefined,
+    redactions: options.redact ? emptyRedactionCounts() : undefined,
+    svgs: options.svg === "asset" ? [] : undefined,
+    progress: createProgressReporter(options.onProgress),
+  };
+  // The envelope reports a converter bug in `metadata.error` rather than throwing.
+  let markdown = "";
+  let failure: ConversionErrorInfo | undefined;
+  try {
+    markdown = convertDocument(html, baseUrl, options, extras);
+  } catch (err) {
+    if (conversionErrorCode(err) !== ConversionErrorCode.PANIC_RECOVERED) throw err;
+    failure = conversionErrorInfo(err);
+  }
+  const metadata: MarkdownMetadata = {
+    language: detectLanguage(markdownToPlainText(markdown)),
+    stats: computeTextStats(markdown),
+    fingerprint: computeSimHash(markdown),
+    tokens: countTokens(markdown),
+  };
+
+  const declared = (html || "").match(/<html\b[^>]*?\blang\s*=\s*["']?([A-Za-z]{2,3}(?:-[A-Za-z0-9]+)*)/i);
+  if (declared) metadata.declaredLanguage = declared[1];
+  const dates = extractDates(html, baseUrl);
+  if (dates.published) metadata.published = dates.published;
+  if (dates.modified) metadata.modified = dates.modified;
+  if (extras.report) metadata.sanitized = extras.report;
+  if (extras.repairs) metadata.repairs = extras.repairs;
+  if (extras.redactions) metadata.redactions = extras.redactions;
+  if (extras.svgs) metadata.svgs = extras.svgs;
+  if (failure) metadata.error = failure;
+
+  // Metadata describes the text, so it is computed before any non-markdown rendering.
+  const output = renderOutput(markdown, options);
+  extras.progress?.done(Buffer.byteLength(output, "utf8"));
+  return { markdown: output, metadata };
+}
+
+export interface FragmentOptions extends MarkdownOptions {
+  /** Convert every element matching the selector instead of only the first. */
+  all?: boolean;
+}
+
+/**
+ * Converts only the element(s) matching `selector`, skipping main-content
+ * detection and the rest of the document. Matches nested inside another match
+ * are converted once, as part of their ancestor. Throws an INVALID_OPTIONS
+ * ConversionError on an invalid selector, and PANIC_RECOVERED as parseMarkdown does.
+ */
+export async function convertFragment(
+  html: string | null | undefined,
+  selector: string,
+  baseUrl?: string | null,
+  options: FragmentOptions = {}
+): Promise<string> {
+  validateOptions(options);
+  const progress = createProgressReporter(options.onProgress);
+  const markdown = fragmentMarkdown(html, selector, baseUrl, options, progress);
+  progress?.done(Buffer.byteLength(markdown, "utf8"));
+  return markdown;
+}
+
+function fragmentMarkdown(
+  html: string | null | undefined,
+  selector: string,
+  baseUrl: string | null | undefined,
+  options: FragmentOptions,
+  progress?: ProgressReporter
+): string {
   if (!html) return "";
 
-  return _als.run({ baseUrl: baseUrl ?? null }, () => {
+  const memory = createMemoryBudget(options.maxMemoryBytes);
+  const deadline = createDeadline(options.timeoutMs);
+  memory.holdString("input", html, "parsing");
+  const repaired = repairHtml(html).html;
+  memory.holdString("repaired", repaired, "parsing");
+  // The page's tree stays live while each match is converted.
+  memory.holdDocument("parse", repaired, "parsing");
+  const $ = cheerio.load(repaired);
+  const matches = selectElements($, selector);
+  const targets = options.all ? matches : matches.slice(0, 1);
+  const targetSet = new Set(targets);
+  const roots = targets.filter((el) => !$(el).parents().toArray().some((p) => targetSet.has(p)));
+  if (roots.length === 0) return "";
+  // The fragment is converted on its own, so a direction declared above it has to travel with it.
+  for (const el of roots) {
+    const dir = $(el).closest("[dir]").attr("dir");
+    if (dir && !$(el).attr("dir")) $(el).attr("dir", dir);
+  }
+
+  return _als.run({ baseUrl: baseUrl ?? null, options, progress, memory, deadline, svgs: [] }, () => {
     try {
-      const tidiedHtml = tidyHtml(html as string);
-      let out = _turndown.turndown(tidiedHtml);
-      out = fixBrokenLinks(out);
-      out = stripSkipLinks(out);
-      out = stripEditLinks(out);
-      out = out.replace(/\s*\((?:opens?|opening)[^)]*\b(?:tab|window)\)/gi, "");
-      out = cleanupExtraWhitespace(out);
-      return out.trim();
+      progress?.phase("parse");
+      const out = roots
+        .map((el) => renderMarkdown(tidyFragment($.html(el), options)))
+        .filter(Boolean)
+        .join("\n\n");
+      return renderOutput(finishDocument(redactOutput(out, options), options), options);
     } catch (err) {
-      console.error("HTML→Markdown failed", { err });
-      return "";
+      throw recovered(err, "HTML→Markdown fragment conversion");
     }
   });
 }
 
+/**
+ * What a conversion that failed throws: ConversionErrors unchanged, anything
+ * else logged and wrapped as PANIC_RECOVERED, with the original as `cause`,
+ * so callers can branch on the code instead of receiving an empty result.
+ */
+function recovered(err: unknown, what: string): ConversionError {
+  if (err instanceof ConversionError) return err;
+  console.error(`${what} failed`, { err });
+  const message = err instanceof Error ? err.message : String(err);
+  return new ConversionError(ConversionErrorCode.PANIC_RECOVERED, `${what} failed: ${message}`, { cause: err });
+}
+
+const OPTION_VALUES: Partial<Record<keyof MarkdownOptions, readonly string[]>> = {
+  escapeMode: ["full", "smart", "none"],
+  typography: ["ascii", "unicode"],
+  tableCellBlocks: ["inline", "html"],
+  outputFormat: ["markdown", "asciidoc", "rst", "slack", "airtable"],
+  emoji: ["unicode", "shortcode", "image"],
+  images: ["keep", "alt", "drop"],
+  svg: ["text", "asset", "raw", "drop"],
+  bidi: ["marks", "annotate", "none"],
+};
+
+/** Rejects options a caller got wrong (typically from untyped robot config) with INVALID_OPTIONS. */
+export function validateOptions(options: MarkdownOptions): void {
+  const invalid = (name: string, value: unknown) =>
+    new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid option ${name}: ${JSON.stringify(value) ?? String(value)}`);
+
+  for (const [name, allowed] of Object.entries(OPTION_VALUES)) {
+    const value = (options as Record<string, unknown>)[name];
+    if (value !== undefined && !allowed!.includes(value as string)) throw invalid(name, value);
+  }
+  const { tocDepth, maxMemoryBytes, timeoutMs, onProgress, stripSelectors, mainContentSelector, rules, redact } = options;
+  if (tocDepth !== undefined && !(Number.isInteger(tocDepth) && tocDepth >= 1 && tocDepth <= 6)) throw invalid("tocDepth", tocDepth);
+  if (maxMemoryBytes !== undefined && !(typeof maxMemoryBytes === "number" && maxMemoryBytes > 0)) throw invalid("maxMemoryBytes", maxMemoryBytes);
+  if (timeoutMs !== undefined && !(typeof timeoutMs === "number" && timeoutMs >= 0)) throw invalid("timeoutMs", timeoutMs);
+  if (onProgress !== undefined && typeof onProgress !== "function") throw invalid("onProgress", onProgress);
+  if (stripSelectors !== undefined && !(Array.isArray(stripSelectors) && stripSelectors.every((s) => typeof s === "string"))) {
+    throw invalid("stripSelectors", stripSelectors);
+  }
+  if (mainContentSelector !== undefined && typeof mainContentSelector !== "string") throw invalid("mainContentSelector", mainContentSelector);
+  if (redact !== undefined && typeof redact !== "boolean" && !(Array.isArray(redact) && redact.every((k) => PII_KINDS.includes(k)))) {
+    throw invalid("redact", redact);
+  }
+  if (rules !== undefined && !Array.isArray(rules)) throw invalid("rules", rules);
+  for (const rule of rules ?? []) {
+    const valid =
+      rule && typeof rule.selector === "string" && RULE_ACTIONS.includes(rule.action) &&
+      (rule.action !== "replace" || typeof rule.template === "string") &&
+      [rule.template, rule.prefix, rule.suffix].every((t) => t === undefined || typeof t === "string");
+    if (!valid) throw invalid("rules", rule);
+  }
+}
+
+function renderMarkdown(tidiedHtml: string): string {
+  // Turndown parses the tidied HTML into a tree of its own, replacing the previous one.
+  _als.getStore()?.memory.holdDocument("conversion", tidiedHtml, "conversion");
+  _als.getStore()?.deadline.check("cleanup");
+  let out = _turndown.turndown(tidiedHtml);
+  _als.getStore()?.progress?.phase("finish");
+  out = out.replace(CELL_PIPE_RE, "\\|");
+  out = joinCellBreaks(out);
+  out = fixBrokenLinks(out);
+  out = stripSkipLinks(out);
+  out = stripEditLinks(out);
+  out = out.replace(/\s*\((?:opens?|opening)[^)]*\b(?:tab|window)\)/gi, "");
+  out = cleanupExtraWhitespace(out);
+  return out.trim();
+}
+
+/**
+ * Options that act on the assembled document rather than on individual
+ * conversions. Exported for converters that stitch one document together
+ * from several conversions, such as EPUB chapters.
+ */
+export function renderOutput(md: string, options: MarkdownOptions): string {
+  if (!md) return md;
+  if (options.outputFormat === "asciidoc") return markdownToAsciiDoc(md);
+  if (options.outputFormat === "rst") return markdownToRst(md);
+  if (options.outputFormat === "slack") return markdownToSlack(md);
+  if (options.outputFormat === "airtable") return markdownToAirtable(md);
+  return md;
+}
+
+/** Runs before the table of contents is built, so headings in it are redacted too. */
+function redactOutput(md: string, options: MarkdownOptions, counts?: RedactionCounts): string {
+  if (!options.redact || !md) return md;
+  return redactPii(md, options.redact === true ? PII_KINDS : options.redact, counts).text;
+}
+
+function finishDocument(md: string, options: MarkdownOptions): string {
+  if (options.toc && md) md = insertTableOfContents(md, options.tocDepth ?? 3);
+  return md;
+}
+
+/**
+ * Escape only the characters that would otherwise be read as markdown syntax,
+ * so names like "A*Star" or "snake_case" survive untouched while "*a* b" or a
+ * leading "# 1" are still protected.
+ */
+function smartEscape(text: string): string {
+  let out = text.replace(/\\(?=[!-\/:-@\[-`{-~])/g, "\\\\");
+
+  out = out
+    .replace(/^(\s*)([-+*])(?=\s)/gm, "$1\\$2")
+    .replace(/^(\s*)(#{1,6})(?=\s|$)/gm, "$1\\$2")
+    .replace(/^(\s*)(\d+)([.)])(?=\s)/gm, "$1$2\\$3")
+    .replace(/^(\s*)>/gm, "$1\\>")
+    .replace(/^(\s*)([-=*_])(?=\2{2,}\s*$)/gm, "$1\\$2")
+    .replace(/^(\s*)(`{3,}|~{3,})/gm, "$1\\$2");
+
+  if ((out.match(/(?<!\\)\*/g) || []).length >= 2) {
+    out = out.replace(/(?<!\\)\*/g, "\\*");
+  }
+
+  // Intraword underscores can never open or close emphasis.
+  const boundaryUnderscore = /(?<![\p{L}\p{N}\\])_|(?<!\\)_(?![\p{L}\p{N}])/gu;
+  if ((out.match(boundaryUnderscore) || []).length >= 2) {
+    out = out.replace(boundaryUnderscore, "\\_");
+  }
+
+  if ((out.match(/(?<!\\)`/g) || []).length >= 2) {
+    out = out.replace(/(?<!\\)`/g, "\\`");
+  }
+
+  out = out.replace(/~~/g, "\\~\\~");
+  out = out.replace(/\[([^\[\]]*)\]/g, "\\[$1\\]");
+
+  return out;
+}
+
 function isRelativeUrl(url: string): boolean {
   if (!url) return false;
   return !url.includes("://") && !url.startsWith("mailto:") && !url.startsWith("data:") && !url.startsWith("tel:");
@@ -228,23 +808,35 @@ function unwrapRedirect(href: string): string {
   return href;
 }
 
-function tidyHtml(html: string): string {
-  const $ = cheerio.load(html);
+function tidyHtml(html: string, options: MarkdownOptions): string {
+  const { $, $content } = loadCleanDocument(html, options);
 
-  $(TECHNICAL_SELECTOR).remove();
+  prepareContent($, $content, options);
 
-  $("math").each((_i, el) => {
-    const $el = $(el);
-    const isBlock = ($el.attr("display") || "").toLowerCase() === "block";
-    const annotation = $el.find('annotation[encoding="application/x-tex"]').text().trim();
-    const alttext = ($el.attr("alttext") || "").trim();
-    const latex = annotation || alttext;
-    if (latex) {
-      $el.replaceWith(isBlock ? `<p>$$${latex}$$</p>` : `<span>$${latex}$</span>`);
-    } else {
-      $el.remove();
-    }
-  });
+  const title = $("title").text().trim() || $("h1").first().text().trim();
+  let resultHtml = $content.html() || "";
+
+  if (title && !resultHtml.includes(title)) {
+    resultHtml = `<h1>${title}</h1>\n${resultHtml}`;
+  }
+
+  return resultHtml;
+}
+
+/**
+ * Loads a page and strips technical noise and semantically declared site
+ * chrome, returning the best main-content candidate (or <body>). Shared by the
+ * markdown pipeline and the structural extractors so all of them agree on
+ * what "the content" of a page is.
+ */
+export function loadCleanDocument(
+  html: string,
+  options: MarkdownOptions = {}
+): { $: cheerio.CheerioAPI; $content: cheerio.Cheerio<any> } {
+  const $ = cheerio.load(html);
+
+  stripTechnical($, options);
+  stripConfigured($, options);
 
   $(CHROME_LANDMARK_SELECTOR).remove();
   $("header, footer").each((_i, el) => {
@@ -254,6 +846,11 @@ function tidyHtml(html: string): string {
   });
   $(CHROME_WIDGET_SELECTOR).remove();
 
+  if (options.mainContentSelector) {
+    const [main] = selectElements($, options.mainContentSelector);
+    if (main) return { $, $content: $(main) };
+  }
+
   const mainSelectors = ["main", "article", "#main-content", "#content", ".main", ".content", ".article", ".post-content", "[role='main']"];
   let bestContent: cheerio.Cheerio<any> | null = null;
   for (const selector of mainSelectors) {
@@ -275,7 +872,77 @@ function tidyHtml(html: string): string {
     }
   }
 
-  const $content = bestContent || $("body");
+  return { $, $content: bestContent || $("body") };
+}
+
+/**
+ * Fragment counterpart of tidyHtml: the caller already chose the content, so
+ * only technical noise is removed — no landmark stripping, main-content
+ * guessing or title injection.
+ */
+function tidyFragment(html: string, options: MarkdownOptions): string {
+  const $ = cheerio.load(html, null, false);
+
+  stripTechnical($, options);
+  stripConfigured($, options);
+  $(CHROME_WIDGET_SELECTOR).remove();
+  prepareContent($, $.root(), options);
+
+  return $.html();
+}
+
+function stripTechnical($: cheerio.CheerioAPI, options: MarkdownOptions): void {
+  normalizeAmp($);
+  flattenShadowRoots($);
+  stripMarkers($);
+  sanitizeDocument($, _als.getStore()?.report);
+  // Outside a conversion (extractDomTree) the collected assets go nowhere.
+  prepareInlineSvgs($, options.svg ?? "text", _als.getStore()?.svgs ?? []);
+  $(TECHNICAL_SELECTOR).remove();
+
+  $("math").each((_i, el) => {
+    const $el = $(el);
+    const isBlock = ($el.attr("display") || "").toLowerCase() === "block";
+    const annotation = $el.find('annotation[encoding="application/x-tex"]').text().trim();
+    const alttext = ($el.attr("alttext") || "").trim();
+    const latex = annotation || alttext;
+    if (latex) {
+      $el.replaceWith(isBlock ? `<p>$$${latex}$$</p>` : `<span>$${latex}$</span>`);
+    } else {
+      $el.remove();
+    }
+  });
+}
+
+/**
+ * Removes the page's own data-md-* attributes before any are set, so that
+ * markup can't pass itself off as the converter's: the markers choose rules,
+ * assets and raw-HTML output.
+ */
+function stripMarkers($: cheerio.CheerioAPI): void {
+  $("*").each((_i, el: any) => {
+    for (const name of Object.keys(el.attribs ?? {})) {
+      if (name.toLowerCase().startsWith(MARKER_ATTR_PREFIX)) $(el).removeAttr(name);
+    }
+  });
+}
+
+function stripConfigured($: cheerio.CheerioAPI, options: MarkdownOptions): void {
+  for (const selector of options.stripSelectors ?? []) {
+    for (const el of selectElements($, selector)) $(el).remove();
+  }
+}
+
+function prepareContent($: cheerio.CheerioAPI, $content: cheerio.Cheerio<any>, options: MarkdownOptions): void {
+  if (options.rules) applyRules($, options.rules);
+  applyBidi($, $content, options.bidi ?? "marks");
+  flattenTableCellBlocks($, $content, options.tableCellBlocks ?? "inline");
+
+  $content.find("td, th").find("*").addBack().contents().each((_i, node: any) => {
+    if (node.type === "text" && node.data.includes("|")) {
+      node.data = node.data.replace(/\|/g, CELL_PIPE);
+    }
+  });
 
   $content.find("button, span, a, div").each((_i, el) => {
     const $el = $(el);
@@ -283,14 +950,65 @@ function tidyHtml(html: string): string {
     if (UI_ARTIFACTS.has($el.text().trim())) $el.remove();
   });
 
-  const title = $("title").text().trim() || $("h1").first().text().trim();
-  let resultHtml = $content.html() || "";
+  const progress = _als.getStore()?.progress;
+  if (progress) progress.phase("convert", countTextNodes($content));
+}
 
-  if (title && !resultHtml.includes(title)) {
-    resultHtml = `<h1>${title}</h1>\n${resultHtml}`;
-  }
+/** Text nodes turndown will escape: non-blank and outside code. */
+function countTextNodes($content: cheerio.Cheerio<any>): number {
+  let count = 0;
+  const walk = (node: any) => {
+    if (node.name === "pre" || node.name === "code") return;
+    if (node.type === "text" && node.data.trim()) count++;
+    for (const child of node.children || []) walk(child);
+  };
+  $content.toArray().forEach(walk);
+  return count;
+}
 
-  return resultHtml;
+/**
+ * GFM table rows must fit on one line. Tables whose cells hold lists,
+ * paragraphs or line breaks are either flattened into <br>-separated inline
+ * text or marked to be emitted verbatim as HTML. Nested tables can't be
+ * flattened meaningfully, so they always fall back to HTML.
+ */
+function flattenTableCellBlocks(
+  $: cheerio.CheerioAPI,
+  $content: cheerio.Cheerio<any>,
+  mode: TableCellBlockMode
+): void {
+  $content.find("table").each((_i, table) => {
+    const $table = $(table);
+    if ($table.parents("table").length > 0) return;
+
+    const $cells = $table.find("td, th");
+    const hasBlocks = $cells.toArray().some((cell) => $(cell).find(CELL_BLOCK_SELECTOR).length > 0);
+    if (!hasBlocks) return;
+
+    if (mode === "html" || $table.find("table").length > 0) {
+      $table.attr(RAW_TABLE_ATTR, "");
+      return;
+    }
+
+    $cells.each((_j, cell) => {
+      const $cell = $(cell);
+      $cell.find("br, hr").replaceWith(CELL_BREAK);
+      $cell.find("li").prepend("• ");
+      $cell.find(CELL_BLOCK_SELECTOR).toArray().reverse().forEach((el) => {
+        const $el = $(el);
+        $el.append(CELL_BREAK);
+        $el.replaceWith($el.contents());
+      });
+    });
+  });
+}
+
+function joinCellBreaks(md: string): string {
+  return md
+    .replace(/[ \t]*(?:\uE001[ \t]*)+/g, CELL_BREAK)
+    .replace(/\|\uE001/g, "| ")
+    .replace(/\uE001(?=\|)/g, " ")
+    .replace(/\uE001/g, "<br>");
 }
 
 function fixBrokenLinks(md: string): string {
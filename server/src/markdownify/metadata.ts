@@ -0,0 +1,82 @@
+import * as cheerio from 'cheerio';
+import { resolveUrl } from './urls';
+
+export interface PageMetadata {
+  title?: string;
+  description?: string;
+  keywords?: string[];
+  author?: string;
+  robots?: string;
+  themeColor?: string;
+  lang?: string;
+  canonical?: string;
+  openGraph: Record<string, string | string[]>;
+  twitter: Record<string, string | string[]>;
+  /** Every other named <meta> tag, keyed by lowercased name/property. */
+  meta: Record<string, string>;
+}
+
+/** OpenGraph/Twitter keys whose values are URLs and get resolved against the page. */
+const URL_KEYS = new Set([
+  "image", "image:url", "image:secure_url", "url", "video", "video:url",
+  "video:secure_url", "audio", "player", "image:src",
+]);
+
+/**
+ * Reads OpenGraph (og:*), Twitter Card (twitter:*) and the standard meta tags
+ * of a page. Repeated properties such as multiple og:image tags are returned
+ * as arrays in document order.
+ */
+export function extractMetadata(html: string | null | undefined, baseUrl?: string | null): PageMetadata {
+  const result: PageMetadata = { openGraph: {}, twitter: {}, meta: {} };
+  if (!html) return result;
+
+  const $ = cheerio.load(html);
+  const base = baseUrl ?? null;
+
+  const add = (target: Record<string, string | string[]>, key: string, value: string) => {
+    const existing = target[key];
+    if (existing === undefined) target[key] = value;
+    else if (Array.isArray(existing)) existing.push(value);
+    else target[key] = [existing, value];
+  };
+
+  $("meta").each((_i, el) => {
+    const $el = $(el);
+    const key = ($el.attr("property") || $el.attr("name") || $el.attr("itemprop") || "").trim().toLowerCase();
+    let value = ($el.attr("content") || $el.attr("value") || "").trim();
+    if (!key || !value) return;
+
+    if (key.startsWith("og:")) {
+      const prop = key.slice(3);
+      if (URL_KEYS.has(prop)) value = resolveUrl(value, base);
+      add(result.openGraph, prop, value);
+      return;
+    }
+    if (key.startsWith("twitter:")) {
+      const prop = key.slice(8);
+      if (URL_KEYS.has(prop)) value = resolveUrl(value, base);
+      add(result.twitter, prop, value);
+      return;
+    }
+    if (result.meta[key] === undefined) result.meta[key] = value;
+  });
+
+  const title = $("head > title").first().text().trim() || $("title").first().text().trim();
+  if (title) result.title = title;
+  if (result.meta.description) result.description = result.meta.description;
+  if (result.meta.keywords) {
+    result.keywords = result.meta.keywords.split(",").map((k) => k.trim()).filter(Boolean);
+  }
+  if (result.meta.author) result.author = result.meta.author;
+  if (result.meta.robots) result.robots = result.meta.robots;
+  if (result.meta["theme-color"]) result.themeColor = result.meta["theme-color"];
+
+  const lang = ($("html").attr("lang") || "").trim();
+  if (lang) result.lang = lang;
+
+  const canonical = $('link[rel~="canonical" i]').attr("href");
+  if (canonical) result.canonical = resolveUrl(canonical, base);
+
+  return result;
+}
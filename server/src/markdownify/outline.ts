@@ -0,0 +1,113 @@
+export interface HeadingOutlineEntry {
+  level: number;
+  text: string;
+  /** GitHub-style slug, de-duplicated with -1, -2… suffixes like GitHub does. */
+  anchor: string;
+  /** UTF-8 byte offset of the heading line within the markdown. */
+  offset: number;
+}
+
+/**
+ * Strip inline markdown from heading text so the outline shows what a reader
+ * sees: links and images collapse to their label, emphasis/code markers and
+ * backslash escapes are dropped.
+ */
+export function plainHeadingText(raw: string): string {
+  return raw
+    .replace(/!\[([^\]]*)\]\([^)]*\)/g, "$1")
+    .replace(/\[([^\]]*)\]\([^)]*\)/g, "$1")
+    .replace(/(\*\*|__|~~)(.+?)\1/g, "$2")
+    .replace(/(^|[^\\\w])_(.+?)_(?!\w)/g, "$1$2")
+    .replace(/(^|[^\\])[*`]+/g, "$1")
+    .replace(/\\([!-\/:-@\[-`{-~])/g, "$1")
+    .replace(/\s+/g, " ")
+    .trim();
+}
+
+export function slugifyHeading(text: string): string {
+  return text
+    .toLowerCase()
+    .replace(/[^\p{L}\p{N}\p{M}\s_-]/gu, "")
+    .trim()
+    .replace(/\s/g, "-");
+}
+
+/**
+ * Returns the heading hierarchy of a markdown document. ATX and setext
+ * headings are recognised; anything inside fenced code blocks is ignored.
+ */
+export function extractOutline(markdown: string | null | undefined): HeadingOutlineEntry[] {
+  if (!markdown) return [];
+
+  const entries: HeadingOutlineEntry[] = [];
+  const seen = new Map<string, number>();
+  const lines = markdown.split("\n");
+
+  let offset = 0;
+  let fence: string | null = null;
+  let prevLine: { text: string; offset: number } | null = null;
+
+  const push = (level: number, raw: string, lineOffset: number) => {
+    const text = plainHeadingText(raw);
+    if (!text) return;
+    const base = slugifyHeading(text);
+    const count = seen.get(base) ?? 0;
+    seen.set(base, count + 1);
+    entries.push({ level, text, anchor: count ? `${base}-${count}` : base, offset: lineOffset });
+  };
+
+  for (const line of lines) {
+    const lineOffset = offset;
+    offset += Buffer.byteLength(line, "utf8") + 1;
+
+    const fenceMatch = line.match(/^\s{0,3}(`{3,}|~{3,})/);
+    if (fenceMatch) {
+      if (!fence) fence = fenceMatch[1];
+      else if (fenceMatch[1][0] === fence[0] && fenceMatch[1].length >= fence.length) fence = null;
+      prevLine = null;
+      continue;
+    }
+    if (fence) continue;
+
+    const atx = line.match(/^\s{0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$/);
+    if (atx) {
+      push(atx[1].length, atx[2], lineOffset);
+      prevLine = null;
+      continue;
+    }
+
+    const setext = line.match(/^\s{0,3}(=+|-+)[ \t]*$/);
+    if (setext && prevLine && prevLine.text.trim()) {
+      push(setext[1][0] === "=" ? 1 : 2, prevLine.text, prevLine.offset);
+      prevLine = null;
+      continue;
+    }
+
+    prevLine = { text: line, offset: lineOffset };
+  }
+
+  return entries;
+}
+
+/**
+ * Prepends a linked table of contents listing headings up to `maxDepth`. A
+ * leading title heading stays first and is left out of the list; nothing is
+ * inserted when fewer than two headings qualify.
+ */
+export function insertTableOfContents(markdown: string, maxDepth = 3): string {
+  const outline = extractOutline(markdown);
+  const titleMatch = markdown.match(/^#[ \t]+[^\n]*\n*/);
+  const entries = outline
+    .filter((h) => !(titleMatch && h.offset === 0))
+    .filter((h) => h.level <= maxDepth);
+  if (entries.length < 2) return markdown;
+
+  const minLevel = Math.min(...entries.map((h) => h.level));
+  const toc = entries
+    .map((h) => `${"  ".repeat(h.level - minLevel)}- [${h.text.replace(/([\[\]])/g, "\\$1")}](#${h.anchor})`)
+    .join("\n");
+
+  if (!titleMatch) return `${toc}\n\n${markdown}`;
+  const title = titleMatch[0].trimEnd();
+  return `${title}\n\n${toc}\n\n${markdown.slice(titleMatch[0].length)}`;
+}
@@ -0,0 +1,124 @@
+import * as cheerio from 'cheerio';
+import { cssPath } from './list-patterns';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export type PaginationKind = "rel-next" | "rel-prev" | "next-link" | "prev-link" | "numbered" | "load-more";
+
+export interface PaginationCandidate {
+  kind: PaginationKind;
+  /** Target URL; absent for script-driven controls such as "Load more" buttons. */
+  url?: string;
+  /** Selector of the control, for robots that click instead of navigating. */
+  selector: string;
+  text?: string;
+  /** Heuristic confidence between 0 and 1. */
+  confidence: number;
+}
+
+export interface PaginationInfo {
+  /** Best next-page URL across all candidates. */
+  nextUrl?: string;
+  prevUrl?: string;
+  currentPage?: number;
+  candidates: PaginationCandidate[];
+}
+
+const NEXT_TEXT = /^(?:next(?:\s+page)?|more\s+results|older(?:\s+posts)?|›|»|→|>|>>|suivant|weiter|siguiente|successivo|próxima|volgende|следующая|下一页|下一頁|次へ|次のページ|다음)\s*[›»→>]?$/i;
+const PREV_TEXT = /^[‹«←<]?\s*(?:prev(?:ious)?(?:\s+page)?|newer(?:\s+posts)?|‹|«|←|<|<<|précédent|zurück|anterior|precedente|vorige|предыдущая|上一页|上一頁|前へ|前のページ|이전)$/i;
+const LOAD_MORE_TEXT = /^(?:load\s+more|show\s+more|view\s+more|see\s+more|more\s+results|mehr\s+(?:laden|anzeigen)|voir\s+plus|cargar\s+más|mostrar\s+más|carica\s+altro|加载更多|显示更多|もっと見る|더\s*보기)/i;
+const PAGINATION_CONTAINER = 'nav[aria-label*="pag" i], [role="navigation"][aria-label*="pag" i], .pagination, .pager, .paging, .page-numbers, [class*="pagination" i], [class*="pager" i]';
+
+/**
+ * Identifies pagination controls — rel=next/prev links, "Next"-style anchors,
+ * numbered page links and "Load more" buttons — and ranks candidate next-page
+ * URLs so a scheduler can follow listings without user-authored selectors.
+ */
+export function detectPagination(html: string | null | undefined, baseUrl?: string | null): PaginationInfo {
+  const info: PaginationInfo = { candidates: [] };
+  if (!html) return info;
+
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+  const pageUrl = baseUrl ?? null;
+  const seen = new Set<string>();
+
+  const add = (candidate: PaginationCandidate) => {
+    if (candidate.url && pageUrl && stripHash(candidate.url) === stripHash(pageUrl)) return;
+    const key = `${candidate.kind}|${candidate.url ?? candidate.selector}`;
+    if (seen.has(key)) return;
+    seen.add(key);
+    info.candidates.push(candidate);
+  };
+
+  const hrefOf = ($el: cheerio.Cheerio<any>): string | undefined => {
+    const href = ($el.attr("href") || "").trim();
+    if (!href || href.startsWith("#") || /^javascript:/i.test(href)) return undefined;
+    return resolveUrl(href, base);
+  };
+
+  $('link[rel~="next" i], a[rel~="next" i]').each((_i, el) => {
+    const url = hrefOf($(el));
+    if (url) add({ kind: "rel-next", url, selector: cssPath($, el), text: labelOf($, el), confidence: 0.95 });
+  });
+  $('link[rel~="prev" i], link[rel~="previous" i], a[rel~="prev" i]').each((_i, el) => {
+    const url = hrefOf($(el));
+    if (url) add({ kind: "rel-prev", url, selector: cssPath($, el), text: labelOf($, el), confidence: 0.95 });
+  });
+
+  $("a[href], button, [role='button']").each((_i, el) => {
+    const $el = $(el);
+    const label = labelOf($, el);
+    const cls = `${$el.attr("class") || ""} ${$el.attr("id") || ""}`.toLowerCase();
+    const inPager = $el.closest(PAGINATION_CONTAINER).length > 0;
+    const url = el.name === "a" ? hrefOf($el) : undefined;
+
+    if (NEXT_TEXT.test(label) || /(?:^|[\s_-])next(?:$|[\s_-])/.test(cls)) {
+      if (url) add({ kind: "next-link", url, selector: cssPath($, el), text: label, confidence: inPager ? 0.85 : 0.7 });
+    } else if (PREV_TEXT.test(label) || /(?:^|[\s_-])prev(?:ious)?(?:$|[\s_-])/.test(cls)) {
+      if (url) add({ kind: "prev-link", url, selector: cssPath($, el), text: label, confidence: inPager ? 0.85 : 0.7 });
+    } else if (LOAD_MORE_TEXT.test(label)) {
+      add({ kind: "load-more", url, selector: cssPath($, el), text: label, confidence: 0.6 });
+    }
+  });
+
+  const current = detectCurrentPage($);
+  if (current !== undefined) info.currentPage = current;
+
+  $(PAGINATION_CONTAINER).find("a[href]").each((_i, el) => {
+    const label = labelOf($, el);
+    if (!/^\d+$/.test(label)) return;
+    const url = hrefOf($(el));
+    if (!url) return;
+    const n = parseInt(label, 10);
+    const isNext = current !== undefined && n === current + 1;
+    add({ kind: "numbered", url, selector: cssPath($, el), text: label, confidence: isNext ? 0.8 : 0.3 });
+  });
+
+  info.candidates.sort((a, b) => b.confidence - a.confidence);
+  const nextKinds: PaginationKind[] = ["rel-next", "next-link", "numbered"];
+  const next = info.candidates.find((c) => c.url && nextKinds.includes(c.kind) && c.confidence >= 0.5);
+  const prev = info.candidates.find((c) => c.url && (c.kind === "rel-prev" || c.kind === "prev-link"));
+  if (next) info.nextUrl = next.url;
+  if (prev) info.prevUrl = prev.url;
+
+  return info;
+}
+
+function labelOf($: cheerio.CheerioAPI, el: any): string {
+  const $el = $(el);
+  return ($el.text().replace(/\s+/g, " ").trim() || $el.attr("aria-label")?.trim() || $el.attr("title")?.trim() || "");
+}
+
+function detectCurrentPage($: cheerio.CheerioAPI): number | undefined {
+  const $current = $('[aria-current="page"]')
+    .add($(PAGINATION_CONTAINER).find(".current, .active, .selected, [class*='current'], [class*='active']"));
+  for (const el of $current.toArray()) {
+    const label = labelOf($, el);
+    if (/^\d+$/.test(label)) return parseInt(label, 10);
+  }
+  return undefined;
+}
+
+function stripHash(url: string): string {
+  return url.split("#")[0];
+}
@@ -0,0 +1,405 @@
+import logger from '../logger';
+import { ConversionError, ConversionErrorCode } from './errors';
+
+export interface PdfPageInfo {
+  pageNumber: number;
+  width: number;
+  height: number;
+  /** Characters of text on the page; near zero for scanned pages. */
+  chars: number;
+  headings: number;
+  tables: number;
+}
+
+export interface PdfConversionResult {
+  markdown: string;
+  title?: string;
+  author?: string;
+  pageCount: number;
+  /** True when the PDF has (almost) no text layer and needs OCR instead. */
+  scanned: boolean;
+  pages: PdfPageInfo[];
+}
+
+export interface PdfConversionOptions {
+  /** Insert a horizontal rule between pages (default false). */
+  pageBreaks?: boolean;
+  /** Stop after this many pages. */
+  maxPages?: number;
+}
+
+interface TextLine {
+  text: string;
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+  size: number;
+  bold: boolean;
+}
+
+type Item =
+  | { kind: "line"; line: TextLine }
+  | { kind: "table"; rows: string[][] };
+
+const SCANNED_CHARS_PER_PAGE = 30;
+const BULLET = /^(?:[•◦▪▫●○■□‣⁃–-]|\(?\d{1,3}[.)]|\(?[a-z][.)])\s+/;
+
+/**
+ * Converts a PDF's text layer to markdown. Headings are inferred from font
+ * size (and bold standalone lines) relative to the body text, running
+ * headers, footers and page numbers are dropped, paragraphs are rebuilt from
+ * line spacing, and grids of aligned short text become GFM tables. Scanned
+ * PDFs come back with `scanned: true`; DocumentInterpreter handles OCR.
+ */
+export async function convertPdfToMarkdown(
+  buffer: Buffer,
+  options: PdfConversionOptions = {}
+): Promise<PdfConversionResult> {
+  // mupdf is ESM-only; the Function wrapper keeps tsc from rewriting the import to require().
+  const mupdf: any = await (Function('return import("mupdf")')() as Promise<any>);
+  let doc: any;
+  let pageCount: number;
+  try {
+    doc = mupdf.Document.openDocument(buffer, "application/pdf");
+    pageCount = doc.countPages();
+  } catch (err) {
+    doc?.destroy?.();
+    throw new ConversionError(ConversionErrorCode.PARSE_FAILED, "Not a PDF, or a damaged one", { cause: err });
+  }
+
+  try {
+    const limit = Math.min(pageCount, options.maxPages ?? pageCount);
+    const pageLines: TextLine[][] = [];
+    const pages: PdfPageInfo[] = [];
+
+    for (let i = 0; i < limit; i++) {
+      let page: any;
+      try {
+        page = doc.loadPage(i);
+        const [x0, y0, x1, y1] = page.getBounds();
+        const stext = page.toStructuredText("preserve-whitespace");
+        const lines = readLines(JSON.parse(stext.asJSON()));
+        stext.destroy?.();
+        pageLines.push(lines);
+        pages.push({
+          pageNumber: i + 1,
+          width: Math.round(x1 - x0),
+          height: Math.round(y1 - y0),
+          chars: lines.reduce((sum, l) => sum + l.text.length, 0),
+          headings: 0,
+          tables: 0,
+        });
+      } catch (err) {
+        throw new ConversionError(ConversionErrorCode.PARSE_FAILED, `Could not read page ${i + 1} of the PDF`, { cause: err });
+      } finally {
+        page?.destroy?.();
+      }
+    }
+
+    const totalChars = pages.reduce((sum, p) => sum + p.chars, 0);
+    const scanned = limit > 0 && totalChars / limit < SCANNED_CHARS_PER_PAGE;
+
+    dropRunningLines(pageLines, pages);
+    const bodySize = dominantSize(pageLines.flat());
+    const headingLevel = headingScale(pageLines.flat(), bodySize);
+
+    const parts: string[] = [];
+    pageLines.forEach((lines, i) => {
+      const items = detectTables(lines, pages[i].width);
+      const md = renderPage(items, bodySize, headingLevel, pages[i]);
+      if (!md) return;
+      if (options.pageBreaks && parts.length) parts.push("---");
+      parts.push(md);
+    });
+
+    const result: PdfConversionResult = {
+      markdown: joinPages(parts),
+      pageCount,
+      scanned,
+      pages,
+    };
+    const title = metadata(doc, "info:Title");
+    const author = metadata(doc, "info:Author");
+    if (title) result.title = title;
+    if (author) result.author = author;
+    return result;
+  } finally {
+    doc.destroy?.();
+  }
+}
+
+function metadata(doc: any, key: string): string | undefined {
+  try {
+    return (doc.getMetaData(key) || "").trim() || undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+function readLines(json: any): TextLine[] {
+  const lines: TextLine[] = [];
+  for (const block of json?.blocks ?? []) {
+    if (block.type !== "text") continue;
+    for (const line of block.lines ?? []) {
+      if (line.wmode) continue; // vertical text has no sensible markdown reading order
+      const text = String(line.text ?? "").replace(/\s+/g, " ").trim();
+      if (!text) continue;
+      lines.push({
+        text,
+        x: line.bbox.x,
+        y: line.bbox.y,
+        width: line.bbox.w,
+        height: line.bbox.h,
+        size: Math.round((line.font?.size ?? 0) * 2) / 2,
+        bold: line.font?.weight === "bold" || /bold|black|heavy/i.test(line.font?.name ?? ""),
+      });
+    }
+  }
+  return lines;
+}
+
+/**
+ * Lines in the top or bottom margin that repeat on most pages (with digits
+ * ignored, so "Page 3 of 9" matches "Page 4 of 9") are running headers or
+ * footers; bare page numbers in the margins go too.
+ */
+function dropRunningLines(pageLines: TextLine[][], pages: PdfPageInfo[]): void {
+  const inMargin = (line: TextLine, page: PdfPageInfo) =>
+    line.y < page.height * 0.08 || line.y + line.height > page.height * 0.92;
+  const key = (text: string) => text.replace(/\d+/g, "#").toLowerCase();
+
+  const seen = new Map<string, number>();
+  pageLines.forEach((lines, i) => {
+    const keys = new Set(lines.filter((l) => inMargin(l, pages[i])).map((l) => key(l.text)));
+    for (const k of keys) seen.set(k, (seen.get(k) ?? 0) + 1);
+  });
+
+  const threshold = Math.max(2, Math.ceil(pageLines.length * 0.5));
+  pageLines.forEach((lines, i) => {
+    pageLines[i] = lines.filter((l) => {
+      if (!inMargin(l, pages[i])) return true;
+      if (/^(?:page\s+)?\d+(?:\s*(?:of|\/)\s*\d+)?$/i.test(l.text)) return false;
+      return pageLines.length < 2 || (seen.get(key(l.text)) ?? 0) < threshold;
+    });
+  });
+}
+
+/** Font size carrying the most characters, i.e. the body text. */
+function dominantSize(lines: TextLine[]): number {
+  const weight = new Map<number, number>();
+  for (const l of lines) weight.set(l.size, (weight.get(l.size) ?? 0) + l.text.length);
+  let best = 0;
+  let bestWeight = -1;
+  for (const [size, w] of weight) {
+    if (w > bestWeight) {
+      best = size;
+      bestWeight = w;
+    }
+  }
+  return best;
+}
+
+/**
+ * Maps font sizes noticeably larger than the body to heading levels, largest
+ * first. Only the three largest sizes become levels; the rest, like bold
+ * standalone lines, share the next level down.
+ */
+function headingScale(lines: TextLine[], bodySize: number): (line: TextLine) => number {
+  const sizes = [...new Set(lines.filter((l) => l.size >= bodySize * 1.15 && l.text.length <= 200).map((l) => l.size))]
+    .sort((a, b) => b - a);
+  const top = sizes.slice(0, 3);
+  const minor = top.length + 1;
+
+  return (line) => {
+    if (line.text.length > 200) return 0;
+    const rank = top.indexOf(line.size);
+    if (rank >= 0) return rank + 1;
+    if (line.size >= bodySize * 1.15) return minor;
+    if (line.bold && !BULLET.test(line.text) && line.size >= bodySize && line.text.length <= 80 && !/[.,;:]$/.test(line.text)) return Math.min(minor, 6);
+    return 0;
+  };
+}
+
+/**
+ * Finds runs of three or more visual rows that split into two or more short,
+ * horizontally separated segments and turns them into tables. Long segments
+ * are prose in a multi-column layout, not cells.
+ */
+function detectTables(lines: TextLine[], pageWidth: number): Item[] {
+  const rows = groupRows(lines);
+  const isCellRow = (row: TextLine[]) =>
+    row.length >= 2 && (row.length >= 3 || row.every((l) => l.width < pageWidth * 0.3));
+
+  const tableOf = new Map<TextLine, number>();
+  const tables: string[][][] = [];
+  for (let i = 0; i < rows.length; ) {
+    let j = i;
+    while (j < rows.length && isCellRow(rows[j])) j++;
+    if (j - i >= 3) {
+      const run = rows.slice(i, j);
+      const index = tables.push(toGrid(run)) - 1;
+      for (const row of run) for (const l of row) tableOf.set(l, index);
+    }
+    i = Math.max(j, i + 1);
+  }
+
+  const items: Item[] = [];
+  const emitted = new Set<number>();
+  for (const line of lines) {
+    const index = tableOf.get(line);
+    if (index === undefined) items.push({ kind: "line", line });
+    else if (!emitted.has(index)) {
+      emitted.add(index);
+      items.push({ kind: "table", rows: tables[index] });
+    }
+  }
+  return items;
+}
+
+function groupRows(lines: TextLine[]): TextLine[][] {
+  const sorted = [...lines].sort((a, b) => a.y - b.y || a.x - b.x);
+  const rows: TextLine[][] = [];
+  for (const line of sorted) {
+    const row = rows[rows.length - 1];
+    if (row && Math.abs(row[0].y - line.y) < Math.max(2, row[0].height * 0.5)) row.push(line);
+    else rows.push([line]);
+  }
+  for (const row of rows) row.sort((a, b) => a.x - b.x);
+  return rows;
+}
+
+/** Clusters segment start positions into columns and places each segment in the nearest one. */
+function toGrid(rows: TextLine[][]): string[][] {
+  const tolerance = Math.max(6, dominantSize(rows.flat()) * 1.5);
+  const columns: number[] = [];
+  for (const x of rows.flat().map((l) => l.x).sort((a, b) => a - b)) {
+    if (!columns.length || x - columns[columns.length - 1] > tolerance) columns.push(x);
+  }
+
+  return rows.map((row) => {
+    const cells = columns.map(() => "");
+    for (const l of row) {
+      let nearest = 0;
+      for (let c = 1; c < columns.length; c++) {
+        if (Math.abs(columns[c] - l.x) < Math.abs(columns[nearest] - l.x)) nearest = c;
+      }
+      cells[nearest] = cells[nearest] ? `${cells[nearest]} ${l.text}` : l.text;
+    }
+    return cells;
+  });
+}
+
+function renderPage(
+  items: Item[],
+  bodySize: number,
+  headingLevel: (line: TextLine) => number,
+  info: PdfPageInfo
+): string {
+  const blocks: string[] = [];
+  let paragraph: string[] = [];
+  let previous: TextLine | null = null;
+  let lastHeading: { level: number; line: TextLine } | null = null;
+
+  const flush = () => {
+    if (paragraph.length) blocks.push(paragraph.join(" ").replace(/(\p{Ll})- (\p{Ll})/gu, "$1$2"));
+    paragraph = [];
+    previous = null;
+  };
+
+  for (const item of items) {
+    if (item.kind === "table") {
+      flush();
+      blocks.push(renderTable(item.rows));
+      info.tables++;
+      continue;
+    }
+
+    const line = item.line;
+    const level = headingLevel(line);
+    if (level) {
+      flush();
+      // A heading that wraps onto a second line stays one heading.
+      const wrapped = lastHeading && lastHeading.level === level && blocks.length
+        && line.y - (lastHeading.line.y + lastHeading.line.height) < line.height * 0.5;
+      if (wrapped) blocks[blocks.length - 1] += ` ${escapeInline(line.text)}`;
+      else {
+        blocks.push(`${"#".repeat(level)} ${escapeLine(line.text)}`);
+        info.headings++;
+      }
+      lastHeading = { level, line };
+      continue;
+    }
+    lastHeading = null;
+
+    if (BULLET.test(line.text)) {
+      flush();
+      const text = line.text.replace(BULLET, "");
+      const ordered = /^\(?\d/.test(line.text);
+      blocks.push(`${ordered ? `${line.text.match(/\d+/)![0]}.` : "-"} ${escapeLine(text)}`);
+      continue;
+    }
+
+    // A gap well beyond normal leading, or a change of font size, starts a new paragraph.
+    const gap = previous ? line.y - (previous.y + previous.height) : 0;
+    if (previous && (gap > Math.max(previous.height, bodySize) * 0.8 || gap < -previous.height || line.size !== previous.size)) {
+      flush();
+    }
+    paragraph.push(paragraph.length ? escapeInline(line.text) : escapeLine(line.text));
+    previous = line;
+  }
+  flush();
+
+  return mergeListItems(blocks).join("\n\n");
+}
+
+/** Consecutive list items belong to one list; markdown needs them on adjacent lines. */
+function mergeListItems(blocks: string[]): string[] {
+  const out: string[] = [];
+  for (const block of blocks) {
+    const prev = out[out.length - 1];
+    if (prev !== undefined && /^(?:-|\d+\.) /.test(block) && /^(?:-|\d+\.) /.test(prev.split("\n").pop()!)) {
+      out[out.length - 1] = `${prev}\n${block}`;
+    } else {
+      out.push(block);
+    }
+  }
+  return out;
+}
+
+function renderTable(rows: string[][]): string {
+  const cell = (text: string) => text.replace(/\|/g, "\\|") || " ";
+  const [header, ...body] = rows;
+  return [
+    `| ${header.map(cell).join(" | ")} |`,
+    `| ${header.map(() => "---").join(" | ")} |`,
+    ...body.map((row) => `| ${row.map(cell).join(" | ")} |`),
+  ].join("\n");
+}
+
+/** Escapes text that would otherwise start a markdown block construct. */
+function escapeLine(text: string): string {
+  return escapeInline(text)
+    .replace(/^(\d+)([.)]\s)/, "$1\\$2")
+    .replace(/^(#{1,6}\s|>|[-+]\s|={3,}|-{3,})/, "\\$1");
+}
+
+function escapeInline(text: string): string {
+  return text.replace(/([\\*_`[\]<])/g, "\\$1");
+}
+
+function joinPages(parts: string[]): string {
+  // A paragraph cut by a page break continues when the next page starts lower-case.
+  const out: string[] = [];
+  for (const part of parts) {
+    const prev = out[out.length - 1];
+    if (prev && /\p{Ll}[,;]?$/u.test(prev) && /^\p{Ll}/u.test(part)) {
+      out[out.length - 1] = `${prev} ${part}`;
+    } else {
+      out.push(part);
+    }
+  }
+  const markdown = out.join("\n\n").trim();
+  if (!markdown) logger.log('warn', "PDF produced no text; it may need OCR");
+  return markdown;
+}
@@ -0,0 +1,149 @@
+export interface PriceMatch {
+  /** The matched text, e.g. "1.299,00 €". */
+  raw: string;
+  value: number;
+  /** ISO 4217 code, when a symbol or code accompanied the number. */
+  currency?: string;
+  /** Offset of the match in the input. */
+  index: number;
+}
+
+export interface PriceOptions {
+  /**
+   * BCP 47 locale of the page, used to settle ambiguous symbols: "$" is USD
+   * unless the region says otherwise (en-CA, es-MX, ...), "¥" is JPY unless
+   * the language is Chinese, "kr" follows the Nordic country.
+   */
+  locale?: string;
+}
+
+/** Unambiguous symbols and local abbreviations. Longer entries must win over their prefixes ("US$" over "$"). */
+const SYMBOLS: Record<string, string> = {
+  "US$": "USD", "U$S": "USD", "USD$": "USD", "C$": "CAD", "CA$": "CAD", "A$": "AUD", "AU$": "AUD",
+  "NZ$": "NZD", "HK$": "HKD", "S$": "SGD", "R$": "BRL", "MX$": "MXN", "NT$": "TWD", "CN¥": "CNY", "JP¥": "JPY",
+  "RMB": "CNY", "€": "EUR", "£": "GBP", "₹": "INR", "Rs.": "INR", "Rs": "INR", "₩": "KRW", "₽": "RUB",
+  "руб.": "RUB", "руб": "RUB", "₺": "TRY", "₫": "VND", "₪": "ILS", "฿": "THB", "₱": "PHP", "₴": "UAH",
+  "zł": "PLN", "Kč": "CZK", "Ft": "HUF", "lei": "RON", "CHF": "CHF", "Fr.": "CHF", "円": "JPY", "元": "CNY",
+  "₦": "NGN", "₲": "PYG", "₡": "CRC", "₸": "KZT", "﷼": "SAR", "د.إ": "AED",
+};
+
+const ISO_CODES = [
+  "USD", "EUR", "GBP", "JPY", "CNY", "INR", "KRW", "RUB", "TRY", "BRL", "MXN", "CAD", "AUD", "NZD", "CHF",
+  "SEK", "NOK", "DKK", "PLN", "CZK", "HUF", "RON", "BGN", "HKD", "SGD", "TWD", "THB", "VND", "IDR", "MYR",
+  "PHP", "ILS", "AED", "SAR", "ZAR", "EGP", "NGN", "KES", "ARS", "CLP", "COP", "PEN", "UAH", "KZT", "ISK",
+];
+
+/** Symbols whose currency depends on where the page is from. */
+const AMBIGUOUS = ["$", "¥", "kr", "kr."];
+
+const DOLLAR_REGIONS: Record<string, string> = {
+  CA: "CAD", AU: "AUD", NZ: "NZD", HK: "HKD", SG: "SGD", TW: "TWD", MX: "MXN", AR: "ARS", CL: "CLP", CO: "COP",
+};
+const KRONA_REGIONS: Record<string, string> = { SE: "SEK", NO: "NOK", DK: "DKK", IS: "ISK" };
+const KRONA_LANGUAGES: Record<string, string> = { sv: "SEK", nb: "NOK", nn: "NOK", no: "NOK", da: "DKK", is: "ISK" };
+
+/** Magnitude suffixes: CJK myriads and the usual k/M/bn shorthands. */
+const MULTIPLIERS: Record<string, number> = {
+  "千": 1e3, "万": 1e4, "萬": 1e4, "億": 1e8, "亿": 1e8, "k": 1e3, "K": 1e3, "M": 1e6, "mn": 1e6, "bn": 1e9,
+};
+
+const escape = (s: string) => s.replace(/[.*+?^${}()|[\]\\]/g, "\\$&");
+const CURRENCY = [...Object.keys(SYMBOLS), ...AMBIGUOUS, ...ISO_CODES]
+  .sort((a, b) => b.length - a.length)
+  .map(escape)
+  .join("|");
+// Indian lakh grouping ("1,00,000") first, then ordinary thousands groups, then plain digits.
+const NUMBER = "\\d{1,2}(?:,\\d{2})+,\\d{3}(?:\\.\\d{1,2})?|\\d{1,3}(?:[.,'’\\u00A0\\u202F ]\\d{3})+(?:[.,]\\d{1,2})?|\\d+(?:[.,]\\d{1,2})?";
+const MULTIPLIER = Object.keys(MULTIPLIERS).sort((a, b) => b.length - a.length).map(escape).join("|");
+
+/**
+ * A number with a currency before or after it. Letter-based currencies need a
+ * word boundary so "Rs" doesn't fire inside "Drs 12". The trailing ",-" or
+ * ".-" is the Central European "no cents" mark ("12,-").
+ */
+const PRICE_RE = new RegExp(
+  `(?<![\\p{L}\\d])(?:(${CURRENCY})\\s?(-)?(${NUMBER})(?:\\s?(${MULTIPLIER}))?(?:[.,][-–])?` +
+    `|(-)?(${NUMBER})(?:\\s?(${MULTIPLIER}))?(?:[.,][-–])?\\s?(${CURRENCY}))(?![\\p{L}\\d])`,
+  "gu"
+);
+
+/**
+ * Finds every price in free text or markdown and normalises it to a number
+ * plus ISO currency code, whichever separators the locale uses: "1.299,00 €",
+ * "US$1,299", "CHF 1'299.–" and "¥12万" come out as 1299, 1299, 1299 and
+ * 120000. Bare numbers without a currency are not prices and are skipped.
+ */
+export function findPrices(text: string | null | undefined, options: PriceOptions = {}): PriceMatch[] {
+  if (!text) return [];
+  const matches: PriceMatch[] = [];
+  for (const m of text.matchAll(PRICE_RE)) {
+    const [raw, preSymbol, preSign, preNumber, preMult, postSign, postNumber, postMult, postSymbol] = m;
+    const symbol = preSymbol ?? postSymbol;
+    const value = parseAmount(preNumber ?? postNumber, preMult ?? postMult);
+    if (value === undefined) continue;
+
+    const match: PriceMatch = { raw: raw.trim(), value: preSign || postSign ? -value : value, index: m.index ?? 0 };
+    const currency = currencyFor(symbol, raw, options.locale);
+    if (currency) match.currency = currency;
+    matches.push(match);
+  }
+  return matches;
+}
+
+/**
+ * Parses one price string. Unlike findPrices, a bare number is accepted (as
+ * in a "Price" column), in which case no currency is set.
+ */
+export function parsePrice(text: string | null | undefined, options: PriceOptions = {}): PriceMatch | null {
+  if (!text || !text.trim()) return null;
+  const [found] = findPrices(text, options);
+  if (found) return found;
+
+  const bare = text.match(new RegExp(`(-)?(${NUMBER})(?:\\s?(${MULTIPLIER}))?`, "u"));
+  if (!bare) return null;
+  const value = parseAmount(bare[2], bare[3]);
+  if (value === undefined) return null;
+  return { raw: bare[0].trim(), value: bare[1] ? -value : value, index: bare.index ?? 0 };
+}
+
+/**
+ * Decides which separator is the decimal point. The last of two different
+ * separators is decimal ("1.299,00", "1,299.00"); a repeated one groups
+ * thousands ("1.000.000"); a single one followed by exactly three digits
+ * groups thousands ("1.299", "1,299"), otherwise it is decimal ("12,5").
+ */
+function parseAmount(number: string, multiplier?: string): number | undefined {
+  const compact = number.replace(/[\s\u00A0\u202F'’]/g, "");
+  const separators = compact.match(/[.,]/g) || [];
+  let normalized: string;
+
+  if (new Set(separators).size > 1) {
+    const decimal = compact.lastIndexOf(".") > compact.lastIndexOf(",") ? "." : ",";
+    const group = decimal === "." ? "," : ".";
+    normalized = compact.split(group).join("").replace(decimal, ".");
+  } else if (separators.length > 1) {
+    normalized = compact.replace(/[.,]/g, "");
+  } else if (separators.length === 1) {
+    const [whole, fraction] = compact.split(/[.,]/);
+    normalized = fraction.length === 3 && whole !== "0" ? whole + fraction : `${whole}.${fraction}`;
+  } else {
+    normalized = compact;
+  }
+
+  let value = Number(normalized);
+  if (!Number.isFinite(value)) return undefined;
+  if (multiplier) value = Math.round(value * MULTIPLIERS[multiplier] * 100) / 100;
+  return value;
+}
+
+function currencyFor(symbol: string | undefined, raw: string, locale?: string): string | undefined {
+  if (!symbol) return undefined;
+  if (SYMBOLS[symbol]) return SYMBOLS[symbol];
+  if (ISO_CODES.includes(symbol)) return symbol;
+
+  const [language, region] = (locale || "").replace("_", "-").split("-").map((p, i) => (i ? p.toUpperCase() : p.toLowerCase()));
+  if (symbol === "$") return (region && DOLLAR_REGIONS[region]) || "USD";
+  if (symbol === "¥") return language === "zh" || /[元亿]/.test(raw) ? "CNY" : "JPY";
+  if (symbol === "kr" || symbol === "kr.") return (region && KRONA_REGIONS[region]) || KRONA_LANGUAGES[language] || "SEK";
+  return undefined;
+}
@@ -0,0 +1,263 @@
+import * as cheerio from 'cheerio';
+import { parsePrice } from './price';
+import { extractStructuredData, shortTypeName } from './structured-data';
+import { documentBaseUrl, resolveUrl } from './urls';
+
+export interface ProductRating {
+  value: number;
+  count?: number;
+  best?: number;
+  worst?: number;
+}
+
+export interface ProductOffer {
+  price?: number;
+  /** Upper bound, for AggregateOffer price ranges. */
+  highPrice?: number;
+  /** ISO 4217 code. */
+  currency?: string;
+  /** schema.org ItemAvailability short name, e.g. "InStock", "OutOfStock". */
+  availability?: string;
+  condition?: string;
+  seller?: string;
+  url?: string;
+}
+
+export interface ProductInfo {
+  name?: string;
+  description?: string;
+  brand?: string;
+  sku?: string;
+  gtin?: string;
+  mpn?: string;
+  url?: string;
+  /** Price, currency and availability of the first offer, for convenience. */
+  price?: number;
+  currency?: string;
+  availability?: string;
+  images: string[];
+  rating?: ProductRating;
+  offers: ProductOffer[];
+  source: "structured-data" | "dom";
+}
+
+const PRODUCT_TYPES = new Set(["Product", "ProductGroup", "ProductModel", "IndividualProduct", "SomeProducts", "Vehicle", "Car"]);
+
+/**
+ * Normalises schema.org Product data (JSON-LD, microdata or RDFa, including
+ * products nested under ItemPage/ItemList) into one stable shape. Pages with
+ * no product markup fall back to Open Graph product tags and common DOM
+ * patterns, yielding at most one product.
+ */
+export function extractProducts(html: string | null | undefined, baseUrl?: string | null): ProductInfo[] {
+  if (!html) return [];
+
+  const products: ProductInfo[] = [];
+  const seen = new Set<string>();
+  for (const item of extractStructuredData(html, baseUrl)) {
+    for (const node of findProducts(item.data)) {
+      const product = fromStructuredData(node, baseUrl ?? null);
+      const key = `${product.sku ?? ""}|${product.gtin ?? ""}|${(product.name ?? "").toLowerCase()}`;
+      if (key === "||" || seen.has(key)) continue;
+      seen.add(key);
+      products.push(product);
+    }
+  }
+  if (products.length) return products;
+
+  const fallback = fromDom(html, baseUrl ?? null);
+  return fallback ? [fallback] : [];
+}
+
+/** The page's main product: the first one carrying a price, else the first found. */
+export function extractProduct(html: string | null | undefined, baseUrl?: string | null): ProductInfo | null {
+  const products = extractProducts(html, baseUrl);
+  return products.find((p) => p.price !== undefined) ?? products[0] ?? null;
+}
+
+function isProduct(node: any): boolean {
+  const types = Array.isArray(node?.["@type"]) ? node["@type"] : [node?.["@type"]];
+  return types.some((t: unknown) => typeof t === "string" && PRODUCT_TYPES.has(shortTypeName(t)));
+}
+
+/** Products at any depth, without descending into a product's own properties (variants aside). */
+function findProducts(node: any, depth = 0, out: any[] = []): any[] {
+  if (!node || typeof node !== "object" || depth > 8) return out;
+  if (Array.isArray(node)) {
+    for (const child of node) findProducts(child, depth + 1, out);
+    return out;
+  }
+  if (isProduct(node)) {
+    out.push(node);
+    if (node.hasVariant) findProducts(node.hasVariant, depth + 1, out);
+    return out;
+  }
+  for (const value of Object.values(node)) findProducts(value, depth + 1, out);
+  return out;
+}
+
+function first(value: any): any {
+  return Array.isArray(value) ? value[0] : value;
+}
+
+/** Text of a property that may be a string, a number, {"@value"} or a named Thing. */
+function text(value: any): string | undefined {
+  const v = first(value);
+  if (v === undefined || v === null) return undefined;
+  if (typeof v === "string" || typeof v === "number") return String(v).replace(/\s+/g, " ").trim() || undefined;
+  if (typeof v === "object") return text(v.name ?? v["@value"] ?? v.value);
+  return undefined;
+}
+
+const PLAIN_NUMBER_RE = /^[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:e[+-]?\d+)?$/i;
+
+function number(value: any): number | undefined {
+  const v = first(value);
+  if (typeof v === "number") return Number.isFinite(v) ? v : undefined;
+  if (typeof v === "object" && v) return number(v["@value"] ?? v.value);
+  if (typeof v !== "string") return undefined;
+  // schema.org numbers use "." for decimals, so "4.625" is read as written; only
+  // other forms ("1,234.56", "19,99 €") go through the price heuristics.
+  const plain = v.trim();
+  if (PLAIN_NUMBER_RE.test(plain)) return Number(plain);
+  return parsePrice(v)?.value;
+}
+
+function urlList(value: any, baseUrl: string | null): string[] {
+  const list = Array.isArray(value) ? value : value ? [value] : [];
+  return list
+    .map((v) => (typeof v === "string" ? v : v?.contentUrl ?? v?.url))
+    .filter((v): v is string => typeof v === "string" && !!v.trim())
+    .map((v) => resolveUrl(v, baseUrl));
+}
+
+function availabilityName(value: any): string | undefined {
+  const raw = text(value);
+  return raw ? shortTypeName(raw).replace(/^.*\//, "") : undefined;
+}
+
+function offersOf(node: any, baseUrl: string | null): ProductOffer[] {
+  const list = Array.isArray(node.offers) ? node.offers : node.offers ? [node.offers] : [];
+  const offers: ProductOffer[] = [];
+
+  for (const raw of list) {
+    if (!raw || typeof raw !== "object") continue;
+    // A price specification may carry the price instead of the offer itself.
+    const spec = first(raw.priceSpecification) ?? {};
+    const nested = raw.offers && first(raw.offers);
+    const offer: ProductOffer = {};
+
+    const price = number(raw.price ?? raw.lowPrice ?? spec.price ?? spec.minPrice ?? nested?.price);
+    const highPrice = number(raw.highPrice ?? spec.maxPrice);
+    const currency = text(raw.priceCurrency ?? spec.priceCurrency ?? nested?.priceCurrency);
+    const availability = availabilityName(raw.availability ?? nested?.availability);
+    const condition = availabilityName(raw.itemCondition);
+    const seller = text(raw.seller ?? raw.offeredBy);
+    const url = text(raw.url);
+
+    if (price !== undefined) offer.price = price;
+    if (highPrice !== undefined && highPrice !== price) offer.highPrice = highPrice;
+    if (currency) offer.currency = currency.toUpperCase();
+    if (availability) offer.availability = availability;
+    if (condition) offer.condition = condition;
+    if (seller) offer.seller = seller;
+    if (url) offer.url = resolveUrl(url, baseUrl);
+    if (Object.keys(offer).length) offers.push(offer);
+  }
+  return offers;
+}
+
+function ratingOf(node: any): ProductRating | undefined {
+  const raw = first(node.aggregateRating);
+  if (!raw || typeof raw !== "object") return undefined;
+  const value = number(raw.ratingValue);
+  if (value === undefined) return undefined;
+
+  const rating: ProductRating = { value };
+  const count = number(raw.reviewCount ?? raw.ratingCount);
+  const best = number(raw.bestRating);
+  const worst = number(raw.worstRating);
+  if (count !== undefined) rating.count = count;
+  if (best !== undefined) rating.best = best;
+  if (worst !== undefined) rating.worst = worst;
+  return rating;
+}
+
+function fromStructuredData(node: any, baseUrl: string | null): ProductInfo {
+  const product: ProductInfo = { images: urlList(node.image, baseUrl), offers: offersOf(node, baseUrl), source: "structured-data" };
+
+  const fields: Array<[keyof ProductInfo, string | undefined]> = [
+    ["name", text(node.name)],
+    ["description", text(node.description)],
+    ["brand", text(node.brand ?? node.manufacturer)],
+    ["sku", text(node.sku ?? node.productID)],
+    ["gtin", text(node.gtin ?? node.gtin13 ?? node.gtin12 ?? node.gtin14 ?? node.gtin8)],
+    ["mpn", text(node.mpn)],
+    ["url", text(node.url)],
+  ];
+  for (const [key, value] of fields) if (value) (product as any)[key] = value;
+  if (product.url) product.url = resolveUrl(product.url, baseUrl);
+
+  const rating = ratingOf(node);
+  if (rating) product.rating = rating;
+  applyFirstOffer(product);
+  return product;
+}
+
+function applyFirstOffer(product: ProductInfo): void {
+  const offer = product.offers.find((o) => o.price !== undefined) ?? product.offers[0];
+  if (!offer) return;
+  if (offer.price !== undefined) product.price = offer.price;
+  if (offer.currency) product.currency = offer.currency;
+  if (offer.availability) product.availability = offer.availability;
+}
+
+const PRICE_SELECTOR = [
+  '[itemprop="price"]', '[data-price]', '[class*="price" i]:not([class*="old" i]):not([class*="was" i]):not([class*="strike" i])',
+].join(",");
+
+/**
+ * Best-effort product from Open Graph product tags and common markup, for
+ * shops that publish no schema.org data. Returns null unless a price or the
+ * og:type says this really is a product page.
+ */
+function fromDom(html: string, baseUrl: string | null): ProductInfo | null {
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+  const meta = (name: string) =>
+    $(`meta[property="${name}"], meta[name="${name}"]`).first().attr("content")?.trim() || undefined;
+
+  const product: ProductInfo = { images: [], offers: [], source: "dom" };
+  const name = meta("og:title") || $("h1").first().text().replace(/\s+/g, " ").trim();
+  if (name) product.name = name;
+  const description = meta("og:description") || meta("description");
+  if (description) product.description = description;
+  const image = meta("og:image");
+  if (image) product.images.push(resolveUrl(image, base));
+
+  const offer: ProductOffer = {};
+  let price = number(meta("product:price:amount") ?? meta("og:price:amount"));
+  let currency = meta("product:price:currency") ?? meta("og:price:currency");
+  if (price === undefined) {
+    const $price = $(PRICE_SELECTOR).filter((_i, el) => /\d/.test($(el).attr("content") || $(el).attr("data-price") || $(el).text())).first();
+    const parsed = parsePrice($price.attr("content") || $price.attr("data-price") || $price.text(), { locale: $("html").attr("lang") });
+    price = parsed?.value;
+    currency = currency ?? parsed?.currency;
+  }
+  if (price !== undefined) offer.price = price;
+  if (currency) offer.currency = currency.toUpperCase();
+
+  const stockText = $('[class*="stock" i], [class*="availability" i], [id*="availability" i]').first().text().toLowerCase();
+  if (/out of stock|sold out|unavailable|ausverkauft|épuisé|agotado/.test(stockText)) offer.availability = "OutOfStock";
+  else if (/pre-?order/.test(stockText)) offer.availability = "PreOrder";
+  else if (/in stock|available|auf lager|en stock|disponible/.test(stockText)) offer.availability = "InStock";
+  if (Object.keys(offer).length) product.offers.push(offer);
+
+  const sku = $('[itemprop="sku"], [class*="sku" i]').first().text().replace(/^\s*sku\s*[:#]?\s*/i, "").trim();
+  if (sku && sku.length <= 64) product.sku = sku;
+
+  const isProductPage = /product/i.test(meta("og:type") ?? "") || price !== undefined;
+  if (!isProductPage || !product.name) return null;
+  applyFirstOffer(product);
+  return product;
+}
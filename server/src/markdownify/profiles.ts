@@ -0,0 +1,172 @@
+import * as cheerio from 'cheerio';
+import { readFile } from 'fs/promises';
+import { URL } from 'url';
+import { parse as parseYaml } from 'yaml';
+import { ConversionError, ConversionErrorCode } from './errors';
+import { FetchedMarkdown, fetchAndConvert, FetchOptions } from './fetch';
+import { selectElements } from './query';
+import { ConversionSession, createConversionSession } from './session';
+import { ConversionRule } from './rules';
+import {
+  convertFragment,
+  FragmentOptions,
+  ImageMode,
+  MarkdownOptions,
+  MarkdownResult,
+  OutputFormat,
+  parseMarkdown,
+  parseMarkdownWithMetadata,
+  streamMarkdown,
+  validateOptions,
+} from './markdown';
+
+/** Conversion tuning for the pages of one site. */
+export interface SiteProfile {
+  /** Hostnames the profile applies to, each including its subdomains. */
+  domains: string[];
+  /** Elements to remove, e.g. comment threads or related-article boxes. */
+  strip?: string[];
+  /** The element holding the main content, used instead of guessing. */
+  mainContent?: string;
+  /** Output flavor. */
+  outputFormat?: OutputFormat;
+  images?: ImageMode;
+  /** Custom rules for the site's quirks. */
+  rules?: ConversionRule[];
+}
+
+export interface ConverterConfig {
+  /** The profiles, or the text of a JSON or YAML profile file. */
+  profiles?: SiteProfile[] | string;
+  /** Options for every conversion; a matching profile overrides them, and per-call options override both. */
+  defaults?: MarkdownOptions;
+}
+
+/** The markdown exports with site profiles applied, chosen by the page's base URL. */
+export interface MarkdownConverter {
+  parseMarkdown(html: string | null | undefined, baseUrl?: string | null, options?: MarkdownOptions): Promise<string>;
+  parseMarkdownWithMetadata(html: string | null | undefined, baseUrl?: string | null, options?: MarkdownOptions): Promise<MarkdownResult>;
+  convertFragment(html: string | null | undefined, selector: string, baseUrl?: string | null, options?: FragmentOptions): Promise<string>;
+  streamMarkdown(input: string | AsyncIterable<string | Buffer>, baseUrl?: string | null, options?: MarkdownOptions): AsyncGenerator<string>;
+  fetchAndConvert(url: string, options?: FetchOptions): Promise<FetchedMarkdown>;
+  createConversionSession(baseUrl?: string | null, options?: MarkdownOptions): ConversionSession;
+  /** The profile that applies to a page, if any. */
+  profileFor(url: string | null | undefined): SiteProfile | undefined;
+}
+
+/**
+ * Builds a converter around a set of site profiles, so operators can tune
+ * conversion for the sites they scrape most from configuration. A page
+ * gets the profile of the most specific matching domain. Strip selectors
+ * and rules add up across defaults, profile and call rather than replacing
+ * each other. Invalid profiles throw an INVALID_OPTIONS ConversionError here,
+ * at creation, rather than on the first matching page.
+ */
+export function createConverter(config: ConverterConfig = {}): MarkdownConverter {
+  const profiles = typeof config.profiles === "string" ? parseSiteProfiles(config.profiles) : toProfiles(config.profiles ?? []);
+  const defaults = config.defaults ?? {};
+  validateOptions(defaults);
+
+  const profileFor = (url: string | null | undefined): SiteProfile | undefined => {
+    if (!url) return undefined;
+    let host: string;
+    try {
+      host = new URL(url).hostname.toLowerCase();
+    } catch {
+      return undefined;
+    }
+    let best: SiteProfile | undefined;
+    let bestLength = 0;
+    for (const profile of profiles) {
+      for (const domain of profile.domains) {
+        if ((host === domain || host.endsWith(`.${domain}`)) && domain.length > bestLength) {
+          best = profile;
+          bestLength = domain.length;
+        }
+      }
+    }
+    return best;
+  };
+
+  const optionsFor = <T extends MarkdownOptions>(baseUrl: string | null | undefined, options: T = {} as T): T => {
+    const profile = profileFor(baseUrl);
+    const merged: T = { ...defaults, ...(profile ? profileOptions(profile) : {}), ...options };
+    const strip = [...(defaults.stripSelectors ?? []), ...(profile?.strip ?? []), ...(options.stripSelectors ?? [])];
+    if (strip.length) merged.stripSelectors = strip;
+    const rules = [...(defaults.rules ?? []), ...(profile?.rules ?? []), ...(options.rules ?? [])];
+    if (rules.length) merged.rules = rules;
+    return merged;
+  };
+
+  return {
+    parseMarkdown: (html, baseUrl, options) => parseMarkdown(html, baseUrl, optionsFor(baseUrl, options)),
+    parseMarkdownWithMetadata: (html, baseUrl, options) => parseMarkdownWithMetadata(html, baseUrl, optionsFor(baseUrl, options)),
+    convertFragment: (html, selector, baseUrl, options) => convertFragment(html, selector, baseUrl, optionsFor(baseUrl, options)),
+    streamMarkdown: (input, baseUrl, options) => streamMarkdown(input, baseUrl, optionsFor(baseUrl, options)),
+    fetchAndConvert: (url, options) => fetchAndConvert(url, optionsFor(url, options)),
+    createConversionSession: (baseUrl, options) => createConversionSession(baseUrl, optionsFor(baseUrl, options)),
+    profileFor,
+  };
+}
+
+/**
+ * Reads profiles from JSON or YAML text: either a list of profiles or an
+ * object with a `profiles` list. JSON is valid YAML, so one parser reads both.
+ */
+export function parseSiteProfiles(text: string): SiteProfile[] {
+  let value: unknown;
+  try {
+    value = parseYaml(text);
+  } catch (err) {
+    throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid site profile file: ${(err as Error).message}`, { cause: err });
+  }
+  if (value && typeof value === "object" && !Array.isArray(value)) value = (value as { profiles?: unknown }).profiles;
+  return toProfiles(value);
+}
+
+export async function loadSiteProfiles(path: string): Promise<SiteProfile[]> {
+  return parseSiteProfiles(await readFile(path, "utf8"));
+}
+
+function toProfiles(value: unknown): SiteProfile[] {
+  const invalid = (message: string) => new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid site profile: ${message}`);
+  if (!Array.isArray(value)) throw invalid("expected a list of profiles");
+
+  return value.map((raw, i) => {
+    if (!raw || typeof raw !== "object") throw invalid(`entry ${i} is not an object`);
+    const { domains, strip, mainContent, outputFormat, images, rules } = raw as Record<string, unknown>;
+    if (!Array.isArray(domains) || !domains.length || !domains.every((d) => typeof d === "string" && d.trim())) {
+      throw invalid(`entry ${i} needs a list of domains`);
+    }
+    const profile: SiteProfile = {
+      // "*.example.com" and ".example.com" mean the same as "example.com", which covers subdomains anyway.
+      domains: domains.map((d: string) => d.trim().toLowerCase().replace(/^\*?\./, "")),
+    };
+    if (strip !== undefined) profile.strip = strip as string[];
+    if (mainContent !== undefined) profile.mainContent = mainContent as string;
+    if (outputFormat !== undefined) profile.outputFormat = outputFormat as OutputFormat;
+    if (images !== undefined) profile.images = images as ImageMode;
+    if (rules !== undefined) profile.rules = rules as ConversionRule[];
+    try {
+      validateOptions(profileOptions(profile));
+      // Selectors are only run on matching pages, so their syntax is checked now against an empty document.
+      const $ = cheerio.load("");
+      const selectors = [...(profile.strip ?? []), ...(profile.rules ?? []).map((rule) => rule.selector)];
+      if (profile.mainContent) selectors.push(profile.mainContent);
+      for (const selector of selectors) selectElements($, selector);
+    } catch (err) {
+      throw invalid(`entry ${i} (${profile.domains[0]}): ${(err as Error).message}`);
+    }
+    return profile;
+  });
+}
+
+function profileOptions(profile: SiteProfile): MarkdownOptions {
+  const options: MarkdownOptions = {};
+  if (profile.strip) options.stripSelectors = profile.strip;
+  if (profile.mainContent) options.mainContentSelector = profile.mainContent;
+  if (profile.outputFormat) options.outputFormat = profile.outputFormat;
+  if (profile.images) options.images = profile.images;
+  if (profile.rules) options.rules = profile.rules;
+  return options;
+}
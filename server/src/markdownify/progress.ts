@@ -0,0 +1,71 @@
+/**
+ * Stages of a conversion, in order.
+ * - parse: loading the HTML and stripping technical noise and page chrome
+ * - convert: turning the cleaned DOM into markdown, node by node
+ * - finish: whole-document passes (link and whitespace cleanup, TOC)
+ * - done: the output is complete; reported exactly once
+ */
+export type ConversionPhase = "parse" | "convert" | "finish" | "done";
+
+export interface ConversionProgress {
+  phase: ConversionPhase;
+  /** Text nodes converted so far. */
+  nodesProcessed: number;
+  /** Text nodes to convert, known once the convert phase starts (0 before). */
+  totalNodes: number;
+  /** UTF-8 bytes of markdown produced so far; on done, the size of the output. */
+  bytesEmitted: number;
+}
+
+export type ProgressCallback = (progress: ConversionProgress) => void;
+
+export interface ProgressReporter {
+  /** Enters a phase, adding `nodes` more text nodes to the total. */
+  phase(phase: ConversionPhase, nodes?: number): void;
+  /** Records one converted text node and the markdown it produced. */
+  node(text: string): void;
+  done(bytesEmitted: number): void;
+}
+
+/** Reports between phases are throttled to about one per percent, and never more often than this many nodes. */
+const MIN_NODES_PER_REPORT = 200;
+
+/**
+ * Wraps a caller's progress callback with throttling; undefined when there is
+ * no callback, so conversions without one pay nothing. An exception thrown
+ * by the callback is swallowed: a broken progress display must not fail the
+ * conversion.
+ */
+export function createProgressReporter(callback?: ProgressCallback): ProgressReporter | undefined {
+  if (!callback) return undefined;
+
+  const state: ConversionProgress = { phase: "parse", nodesProcessed: 0, totalNodes: 0, bytesEmitted: 0 };
+  let lastReported = 0;
+  const emit = () => {
+    lastReported = state.nodesProcessed;
+    try {
+      callback({ ...state });
+    } catch {}
+  };
+
+  return {
+    phase(phase, nodes = 0) {
+      if (state.phase === "done") return;
+      state.phase = phase;
+      state.totalNodes += nodes;
+      emit();
+    },
+    node(text) {
+      state.nodesProcessed++;
+      state.bytesEmitted += Buffer.byteLength(text, "utf8");
+      if (state.nodesProcessed - lastReported >= Math.max(MIN_NODES_PER_REPORT, state.totalNodes / 100)) emit();
+    },
+    done(bytesEmitted) {
+      if (state.phase === "done") return;
+      state.phase = "done";
+      state.nodesProcessed = Math.max(state.nodesProcessed, state.totalNodes);
+      state.bytesEmitted = bytesEmitted;
+      emit();
+    },
+  };
+}
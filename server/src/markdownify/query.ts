@@ -0,0 +1,60 @@
+import * as cheerio from 'cheerio';
+import { cssPath } from './list-patterns';
+import { ConversionError, ConversionErrorCode } from './errors';
+import { xpathOf } from './xpath';
+
+export interface SelectorMatch {
+  html: string;
+  /** Whitespace-collapsed text content. */
+  text: string;
+  tag: string;
+  attributes: Record<string, string>;
+  /** Stable CSS path to the element, usable as a robot selector. */
+  selector: string;
+  /** Absolute XPath of the element. */
+  xpath: string;
+}
+
+export interface QueryOptions {
+  /** Stop after this many matches (default: all). */
+  limit?: number;
+}
+
+/**
+ * Static querySelectorAll: evaluates a CSS selector against HTML without a
+ * browser. Good for validating user selectors and pulling single fields from
+ * server-rendered pages; content injected by scripts is of course absent.
+ * Throws on an invalid selector.
+ */
+export function querySelectorAll(
+  html: string | null | undefined,
+  selector: string,
+  options: QueryOptions = {}
+): SelectorMatch[] {
+  const $ = cheerio.load(html || "");
+  const elements = selectElements($, selector);
+  const limited = options.limit !== undefined ? elements.slice(0, Math.max(0, options.limit)) : elements;
+
+  return limited.map((el: any) => ({
+    html: $.html(el),
+    text: $(el).text().replace(/\s+/g, " ").trim(),
+    tag: el.name,
+    attributes: { ...(el.attribs ?? {}) },
+    selector: cssPath($, el),
+    xpath: xpathOf(el),
+  }));
+}
+
+/** Number of elements `selector` matches; throws on an invalid selector. */
+export function countMatches(html: string | null | undefined, selector: string): number {
+  return selectElements(cheerio.load(html || ""), selector).length;
+}
+
+/** Matches of `selector`; an invalid selector throws an INVALID_OPTIONS ConversionError. */
+export function selectElements($: cheerio.CheerioAPI, selector: string): any[] {
+  try {
+    return $(selector).toArray();
+  } catch (err) {
+    throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid selector "${selector}": ${(err as Error).message}`, { cause: err });
+  }
+}
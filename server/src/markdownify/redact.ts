@@ -0,0 +1,99 @@
+export type PiiKind = "email" | "phone" | "card" | "nationalId";
+
+export const PII_KINDS: readonly PiiKind[] = ["email", "phone", "card", "nationalId"];
+
+/** Redactions made, by kind. */
+export type RedactionCounts = Record<PiiKind, number>;
+
+export function emptyRedactionCounts(): RedactionCounts {
+  return { email: 0, phone: 0, card: 0, nationalId: 0 };
+}
+
+/** Placeholders contain no spaces, so a redacted link destination is still a link. */
+const MASKS: Record<PiiKind, string> = {
+  email: "[REDACTED_EMAIL]",
+  phone: "[REDACTED_PHONE]",
+  card: "[REDACTED_CARD]",
+  nationalId: "[REDACTED_ID]",
+};
+
+/** The output is markdown, so "_" in an address may have been escaped ("jane\_doe@example.org"). */
+const EMAIL_RE = /(?:[A-Za-z0-9._%+\-]|\\_)+@(?:[A-Za-z0-9\-]+\.)+[A-Za-z]{2,24}/g;
+/** 13 to 19 digits, optionally grouped by spaces or dashes; only Luhn-valid numbers count. */
+const CARD_RE = /(?<![\w-])\d(?:[ -]?\d){12,18}(?![\w-])/g;
+const PHONE_RE = /(?<![\w.\-\/+])(?:\+\d[\d\s().\-]{5,20}\d|\(?\d{2,5}\)?(?:[\s.\-]\d{2,5}){1,4})(?![\w\-\/]|\.\d)/g;
+const DATE_LIKE_RE = /^(?:\d{4}[\s.\-]\d{1,2}[\s.\-]\d{1,2}|\d{1,2}[\s.\-]\d{1,2}[\s.\-]\d{4})$/;
+const IP_LIKE_RE = /^\d{1,3}(?:\.\d{1,3}){3}$/;
+
+const DNI_LETTERS = "TRWAGMYFPDXBNJZSQVHLCKE";
+
+/** National identifier formats, most with a check digit to keep ordinary numbers out. */
+const NATIONAL_IDS: { re: RegExp; valid?: (match: string) => boolean }[] = [
+  // US Social Security number, in its written form only: nine bare digits are too common.
+  { re: /(?<![\w-])(?!000|666|9\d\d)\d{3}-(?!00)\d{2}-(?!0000)\d{4}(?![\w-])/g },
+  // UK National Insurance number.
+  { re: /\b(?!BG|GB|NK|KN|TN|NT|ZZ)[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b/g },
+  // Spanish DNI and NIE, with their check letter.
+  {
+    re: /\b[XYZ]?\d{7,8}-?[A-HJ-NP-TV-Z]\b/g,
+    valid: (match) => {
+      const value = match.replace("-", "");
+      const digits = value.slice(0, -1).replace(/^[XYZ]/, (c) => String("XYZ".indexOf(c)));
+      return digits.length === 8 && DNI_LETTERS[Number(digits) % 23] === value.slice(-1);
+    },
+  },
+  // Indian Aadhaar, as printed in groups of four.
+  { re: /\b[2-9]\d{3} \d{4} \d{4}\b/g },
+  // Canadian Social Insurance number.
+  { re: /(?<![\w-])\d{3}[ -]\d{3}[ -]\d{3}(?![\w-])/g, valid: (match) => luhn(match.replace(/\D/g, "")) },
+];
+
+/**
+ * Masks personal data in converted output: email addresses, phone numbers,
+ * payment card numbers and common national ID numbers (US SSN, UK NINO,
+ * Spanish DNI/NIE, Aadhaar, Canadian SIN). Patterns err towards redacting,
+ * as the pass exists for compliance, but checksums, and guards for dates
+ * and IP addresses, keep ordinary numbers intact. Each kind is matched on
+ * the text left by the previous one, so a card number is not also counted
+ * as a phone number.
+ */
+export function redactPii(
+  text: string,
+  kinds: readonly PiiKind[] = PII_KINDS,
+  counts: RedactionCounts = emptyRedactionCounts()
+): { text: string; counts: RedactionCounts } {
+  const mask = (kind: PiiKind, re: RegExp, valid: (match: string) => boolean = () => true) => {
+    if (!kinds.includes(kind)) return;
+    text = text.replace(re, (match) => {
+      if (!valid(match)) return match;
+      counts[kind]++;
+      return MASKS[kind];
+    });
+  };
+
+  mask("email", EMAIL_RE);
+  mask("card", CARD_RE, (match) => {
+    const digits = match.replace(/\D/g, "");
+    return digits.length >= 13 && digits.length <= 19 && luhn(digits);
+  });
+  for (const id of NATIONAL_IDS) mask("nationalId", id.re, id.valid);
+  mask("phone", PHONE_RE, (match) => {
+    const digits = match.replace(/\D/g, "").length;
+    if (DATE_LIKE_RE.test(match) || IP_LIKE_RE.test(match)) return false;
+    return digits <= 15 && digits >= (match.startsWith("+") ? 7 : 9);
+  });
+  return { text, counts };
+}
+
+function luhn(digits: string): boolean {
+  let sum = 0;
+  for (let i = 0; i < digits.length; i++) {
+    let d = Number(digits[digits.length - 1 - i]);
+    if (i % 2 === 1) {
+      d *= 2;
+      if (d > 9) d -= 9;
+    }
+    sum += d;
+  }
+  return sum % 10 === 0;
+}
@@ -0,0 +1,272 @@
+/** What the repair pass changed in a document. */
+export interface RepairReport {
+  /**
+   * Comments and raw-text elements (<title>, <textarea>, <script>, …) that
+   * were never closed; a parser treats the whole rest of the page as their
+   * content, so they are ended where the page evidently resumes.
+   */
+  unterminated: number;
+  /** End tags with no matching open element, dropped. */
+  strayEndTags: number;
+  /** Elements left open (at the end of the document or inside a closing parent), closed explicitly. */
+  unclosedElements: number;
+  /** Formatting elements closed out of order ("<b><i></b></i>"), re-nested. */
+  misnestedTags: number;
+  /** <form> tags inside another form, which HTML does not allow, dropped. */
+  nestedForms: number;
+  /**
+   * Text and elements sitting directly in a table outside any cell, which a
+   * parser would move in front of the table; they get a cell of their own.
+   */
+  fosterParented: number;
+}
+
+export function emptyRepairReport(): RepairReport {
+  return { unterminated: 0, strayEndTags: 0, unclosedElements: 0, misnestedTags: 0, nestedForms: 0, fosterParented: 0 };
+}
+
+interface OpenElement {
+  name: string;
+  /** The start tag as written, for reopening a formatting element. */
+  tag: string;
+}
+
+export const VOID_ELEMENTS = new Set([
+  "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr",
+]);
+
+/** Elements whose content is text up to their end tag. */
+export const RAW_TEXT_ELEMENTS = new Set(["script", "style", "textarea", "title", "xmp", "iframe", "noembed", "noframes", "noscript"]);
+
+/** End tags HTML lets authors leave out; closing them implicitly is not a repair. */
+const OPTIONAL_END = new Set([
+  "p", "li", "dt", "dd", "td", "th", "tr", "tbody", "thead", "tfoot", "option", "optgroup",
+  "rb", "rt", "rp", "colgroup", "caption", "html", "head", "body",
+]);
+
+const FORMATTING = new Set(["a", "b", "big", "code", "em", "font", "i", "nobr", "s", "small", "strike", "strong", "tt", "u"]);
+
+/** Starting one of these closes an open <p>. */
+const CLOSES_P = new Set([
+  "address", "article", "aside", "blockquote", "details", "dialog", "div", "dl", "fieldset", "figcaption",
+  "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr", "main", "menu",
+  "nav", "ol", "p", "pre", "section", "table", "ul",
+]);
+
+/** Starting the key closes an open element in the list, up to the boundary element. */
+const IMPLIED_CLOSE: Record<string, { closes: string[]; boundary: string[] }> = {
+  li: { closes: ["li"], boundary: ["ul", "ol", "menu"] },
+  dt: { closes: ["dt", "dd"], boundary: ["dl"] },
+  dd: { closes: ["dt", "dd"], boundary: ["dl"] },
+  td: { closes: ["td", "th"], boundary: ["tr", "table"] },
+  th: { closes: ["td", "th"], boundary: ["tr", "table"] },
+  tr: { closes: ["tr", "td", "th"], boundary: ["table"] },
+  tbody: { closes: ["tbody", "thead", "tfoot", "tr", "td", "th"], boundary: ["table"] },
+  thead: { closes: ["tbody", "thead", "tfoot", "tr", "td", "th"], boundary: ["table"] },
+  tfoot: { closes: ["tbody", "thead", "tfoot", "tr", "td", "th"], boundary: ["table"] },
+  option: { closes: ["option"], boundary: ["select", "datalist"] },
+  optgroup: { closes: ["option", "optgroup"], boundary: ["select"] },
+};
+
+/** An open <p> is out of reach behind these. */
+const P_SCOPE_BOUNDARY = new Set(["table", "td", "th", "caption", "button", "object", "marquee", "template", "html"]);
+
+/** Containers whose direct content must be table structure. */
+const TABLE_CONTEXT = new Set(["table", "tbody", "thead", "tfoot", "tr"]);
+const TABLE_CONTENT = new Set(["caption", "colgroup", "col", "tbody", "thead", "tfoot", "tr", "td", "th", "script", "style", "template", "form"]);
+
+/** The outermost open element (names innermost last) that starting `name` closes implicitly, or -1. */
+export function impliedCloseIndex(open: readonly string[], name: string): number {
+  let index = -1;
+  if (CLOSES_P.has(name)) {
+    for (let i = open.length - 1; i >= 0 && !P_SCOPE_BOUNDARY.has(open[i]); i--) {
+      if (open[i] === "p") {
+        index = i;
+        break;
+      }
+    }
+  }
+  const implied = IMPLIED_CLOSE[name];
+  if (implied) {
+    for (let i = open.length - 1; i >= 0 && !implied.boundary.includes(open[i]); i--) {
+      if (implied.closes.includes(open[i])) {
+        if (index === -1 || i < index) index = i;
+        break;
+      }
+    }
+  }
+  return index;
+}
+
+export const START_TAG_RE = /<([a-zA-Z][^\s\/>]*)((?:[^>"']|"[^"]*"|'[^']*')*?)(\/?)>/y;
+export const END_TAG_RE = /<\/([a-zA-Z][^\s\/>]*)[^>]*>/y;
+
+/** Where a page resumes after an unclosed comment or raw-text element: the next thing that looks like a tag. */
+export const RESUME_RE = /<\/?[a-zA-Z]/g;
+
+/**
+ * Rewrites malformed HTML into a balanced equivalent before it is parsed.
+ * An HTML5 parser never fails, but some of its recoveries lose content
+ * downstream: an unclosed <title> or comment swallows the page, and stray
+ * cell content is moved out of its table. This pass fixes those and makes
+ * the parser's own implicit fixes explicit (stray end tags, unclosed and
+ * misnested elements, nested forms) so every change can be reported.
+ * Well-formed documents come back unchanged apart from closing tags the
+ * author was allowed to omit.
+ */
+export function repairHtml(html: string, report: RepairReport = emptyRepairReport()): { html: string; report: RepairReport } {
+  const out: string[] = [];
+  const stack: OpenElement[] = [];
+  let droppedForms = 0;
+  let pos = 0;
+
+  const top = () => stack[stack.length - 1]?.name;
+  const inForeign = () => stack.some((el) => el.name === "svg" || el.name === "math");
+  const indexOf = (name: string) => {
+    for (let i = stack.length - 1; i >= 0; i--) if (stack[i].name === name) return i;
+    return -1;
+  };
+  const closeFrom = (index: number) => {
+    while (stack.length > index) out.push(`</${stack.pop()!.name}>`);
+  };
+  /** Closes what a start tag implicitly ends; anything in between that needed an end tag was left open. */
+  const closeImplied = (index: number) => {
+    report.unclosedElements += stack.slice(index + 1).filter((el) => !OPTIONAL_END.has(el.name)).length;
+    closeFrom(index);
+  };
+  const fosterIfNeeded = () => {
+    const name = top();
+    if (!name || !TABLE_CONTEXT.has(name)) return;
+    report.fosterParented++;
+    if (name !== "tr") {
+      out.push("<tr>");
+      stack.push({ name: "tr", tag: "<tr>" });
+    }
+    out.push("<td>");
+    stack.push({ name: "td", tag: "<td>" });
+  };
+  const text = (value: string) => {
+    if (value.trim()) fosterIfNeeded();
+    out.push(value);
+  };
+
+  while (pos < html.length) {
+    const lt = html.indexOf("<", pos);
+    if (lt === -1) {
+      text(html.slice(pos));
+      break;
+    }
+    if (lt > pos) text(html.slice(pos, lt));
+    pos = lt;
+
+    if (html.startsWith("<!--", pos)) {
+      const end = html.indexOf("-->", pos + 4);
+      if (end !== -1) {
+        out.push(html.slice(pos, end + 3));
+        pos = end + 3;
+      } else {
+        // Without "-->" the comment would run to the end of the page; it ends where markup resumes.
+        report.unterminated++;
+        RESUME_RE.lastIndex = pos + 4;
+        const resume = RESUME_RE.exec(html);
+        pos = resume ? resume.index : html.length;
+      }
+      continue;
+    }
+    if (html[pos + 1] === "!" || html[pos + 1] === "?") {
+      const gt = html.indexOf(">", pos);
+      out.push(html.slice(pos, gt === -1 ? html.length : gt + 1));
+      pos = gt === -1 ? html.length : gt + 1;
+      continue;
+    }
+
+    END_TAG_RE.lastIndex = pos;
+    const endTag = END_TAG_RE.exec(html);
+    if (endTag) {
+      pos = END_TAG_RE.lastIndex;
+      closeTag(endTag[1].toLowerCase());
+      continue;
+    }
+
+    START_TAG_RE.lastIndex = pos;
+    const startTag = START_TAG_RE.exec(html);
+    if (!startTag) {
+      // A bare "<" in text, as in "a < b".
+      text("<");
+      pos++;
+      continue;
+    }
+    pos = START_TAG_RE.lastIndex;
+    const name = startTag[1].toLowerCase();
+    const tag = startTag[0];
+
+    if (RAW_TEXT_ELEMENTS.has(name) && !inForeign()) {
+      if (!TABLE_CONTENT.has(name)) fosterIfNeeded();
+      const closeRe = new RegExp(`</${name}\\s*>`, "ig");
+      closeRe.lastIndex = pos;
+      const close = closeRe.exec(html);
+      if (close) {
+        out.push(tag, html.slice(pos, closeRe.lastIndex));
+        pos = closeRe.lastIndex;
+      } else {
+        report.unterminated++;
+        RESUME_RE.lastIndex = pos;
+        const resume = RESUME_RE.exec(html);
+        const end = resume ? resume.index : html.length;
+        out.push(tag, html.slice(pos, end), `</${name}>`);
+        pos = end;
+      }
+      continue;
+    }
+    openTag(name, tag, startTag[3] === "/");
+  }
+
+  report.unclosedElements += stack.filter((el) => !OPTIONAL_END.has(el.name)).length;
+  closeFrom(0);
+  return { html: out.join(""), report };
+
+  function openTag(name: string, tag: string, selfClosing: boolean): void {
+    if (name === "form" && indexOf("form") !== -1) {
+      report.nestedForms++;
+      droppedForms++;
+      return;
+    }
+    if (!TABLE_CONTENT.has(name)) fosterIfNeeded();
+
+    const implied = impliedCloseIndex(stack.map((el) => el.name), name);
+    if (implied !== -1) closeImplied(implied);
+
+    out.push(tag);
+    // Only SVG and MathML honour "/>"; on an HTML element the parser ignores it.
+    if (VOID_ELEMENTS.has(name) || (selfClosing && (inForeign() || name === "svg" || name === "math"))) return;
+    stack.push({ name, tag });
+  }
+
+  function closeTag(name: string): void {
+    if (name === "form" && droppedForms > 0) {
+      droppedForms--;
+      return;
+    }
+    // The document's own wrappers are closed once, at the end; content after "</body>" still belongs to the body.
+    if (name === "html" || name === "body") return;
+
+    const index = indexOf(name);
+    if (index === -1) {
+      if (!VOID_ELEMENTS.has(name)) report.strayEndTags++;
+      return;
+    }
+
+    const above = stack.slice(index + 1);
+    const reopen = above.filter((el) => FORMATTING.has(el.name));
+    if (reopen.length) report.misnestedTags++;
+    report.unclosedElements += above.filter((el) => !OPTIONAL_END.has(el.name) && !FORMATTING.has(el.name)).length;
+
+    closeFrom(index);
+    // Formatting that was still open carries on after the element that closed out of order, as a parser would have it.
+    if (!FORMATTING.has(name) || !reopen.length) return;
+    for (const el of reopen) {
+      out.push(el.tag);
+      stack.push(el);
+    }
+  }
+}
@@ -0,0 +1,166 @@
+export interface RobotsRule {
+  allow: boolean;
+  path: string;
+}
+
+export interface RobotsGroup {
+  /** Lower-cased product tokens this group applies to; "*" is the default group. */
+  userAgents: string[];
+  rules: RobotsRule[];
+  /** Seconds between requests, when the group sets Crawl-delay. */
+  crawlDelay?: number;
+}
+
+export interface RobotsTxt {
+  groups: RobotsGroup[];
+  /** Absolute Sitemap: URLs; these are global and not tied to any group. */
+  sitemaps: string[];
+}
+
+/** RFC 9309 lets crawlers ignore everything past the first 500 KiB. */
+const MAX_BYTES = 500 * 1024;
+
+/**
+ * Parses robots.txt per RFC 9309. Consecutive User-agent lines open a shared
+ * group; rules before any User-agent line are dropped, and unknown directives
+ * are ignored. Crawl-delay and Sitemap are the common non-standard extensions.
+ */
+export function parseRobotsTxt(content: string | null | undefined): RobotsTxt {
+  const robots: RobotsTxt = { groups: [], sitemaps: [] };
+  if (!content) return robots;
+
+  let current: RobotsGroup | null = null;
+  let inAgentLines = false;
+
+  for (const rawLine of Buffer.from(content).subarray(0, MAX_BYTES).toString("utf8").split(/\r\n|\r|\n/)) {
+    const line = rawLine.replace(/#.*$/, "").trim();
+    const colon = line.indexOf(":");
+    if (colon < 0) continue;
+
+    const key = line.slice(0, colon).trim().toLowerCase();
+    const value = line.slice(colon + 1).trim();
+
+    switch (key) {
+      case "user-agent":
+        if (!current || !inAgentLines) {
+          current = { userAgents: [], rules: [] };
+          robots.groups.push(current);
+        }
+        current.userAgents.push(value.toLowerCase());
+        inAgentLines = true;
+        break;
+      case "allow":
+      case "disallow":
+        inAgentLines = false;
+        // An empty Disallow means "allow everything" and adds no rule.
+        if (current && value) current.rules.push({ allow: key === "allow", path: value });
+        break;
+      case "crawl-delay": {
+        inAgentLines = false;
+        const delay = parseFloat(value);
+        if (current && Number.isFinite(delay) && delay >= 0) current.crawlDelay = delay;
+        break;
+      }
+      case "sitemap":
+        if (/^https?:\/\//i.test(value)) robots.sitemaps.push(value);
+        break;
+      default:
+        break;
+    }
+  }
+
+  return robots;
+}
+
+/**
+ * Reports whether `userAgent` may fetch `url`. The most specific matching rule
+ * wins (longest pattern), with Allow winning ties; /robots.txt itself is always
+ * allowed.
+ */
+export function isAllowed(robots: RobotsTxt, userAgent: string, url: string): boolean {
+  const target = pathOf(url);
+  if (target === "/robots.txt") return true;
+
+  let best: RobotsRule | null = null;
+  let bestLength = -1;
+  for (const rule of groupRules(robots, userAgent)) {
+    const pattern = normalizeEncoding(rule.path);
+    if (!matches(pattern, target)) continue;
+    const length = pattern.length;
+    if (length > bestLength || (length === bestLength && rule.allow && !best?.allow)) {
+      best = rule;
+      bestLength = length;
+    }
+  }
+  return best ? best.allow : true;
+}
+
+/** Crawl-delay in seconds for `userAgent`, or undefined when none is set. */
+export function crawlDelay(robots: RobotsTxt, userAgent: string): number | undefined {
+  const delays = matchingGroups(robots, userAgent).map((g) => g.crawlDelay).filter((d): d is number => d !== undefined);
+  return delays.length ? Math.max(...delays) : undefined;
+}
+
+/**
+ * Crawlers match groups by product token ("Maxun" in "Maxun/1.0 (+https://…)"),
+ * case-insensitively. The longest group name that prefixes the token wins, so
+ * "googlebot-news" uses a "googlebot" group when it has none of its own. All
+ * groups naming the winner are merged, as RFC 9309 requires.
+ */
+function matchingGroups(robots: RobotsTxt, userAgent: string): RobotsGroup[] {
+  const token = (userAgent.trim().split(/[\/\s]/)[0] || "").toLowerCase();
+  let bestAgent = "";
+  for (const group of robots.groups) {
+    for (const agent of group.userAgents) {
+      if (agent !== "*" && token.startsWith(agent) && agent.length > bestAgent.length) bestAgent = agent;
+    }
+  }
+  const wanted = bestAgent || "*";
+  return robots.groups.filter((g) => g.userAgents.includes(wanted));
+}
+
+function groupRules(robots: RobotsTxt, userAgent: string): RobotsRule[] {
+  return matchingGroups(robots, userAgent).flatMap((g) => g.rules);
+}
+
+function pathOf(url: string): string {
+  try {
+    const parsed = new URL(url);
+    return normalizeEncoding(parsed.pathname + parsed.search);
+  } catch {
+    return normalizeEncoding(url.startsWith("/") ? url : `/${url}`);
+  }
+}
+
+/**
+ * Brings paths and patterns to one percent-encoding so "/caf%C3%A9" and
+ * "/café" compare equal: non-ASCII is encoded, hex digits upper-cased, and
+ * encoded unreserved characters decoded.
+ */
+function normalizeEncoding(path: string): string {
+  return path
+    .replace(/[^\x21-\x7E]/gu, (ch) => encodeURIComponent(ch))
+    .replace(/%[0-9a-f]{2}/gi, (esc) => {
+      const ch = String.fromCharCode(parseInt(esc.slice(1), 16));
+      return /[A-Za-z0-9\-._~]/.test(ch) ? ch : esc.toUpperCase();
+    });
+}
+
+const patternCache = new Map<string, RegExp>();
+
+/** Patterns are prefix matches; "*" matches any run of characters and a trailing "$" anchors the end. */
+function matches(pattern: string, path: string): boolean {
+  if (!pattern.includes("*") && !pattern.endsWith("$")) return path.startsWith(pattern);
+
+  let re = patternCache.get(pattern);
+  if (!re) {
+    const anchored = pattern.endsWith("$");
+    const body = (anchored ? pattern.slice(0, -1) : pattern)
+      .split("*")
+      .map((part) => part.replace(/[.+?^${}()|[\]\\]/g, "\\$&"))
+      .join(".*");
+    re = new RegExp(`^${body}${anchored ? "$" : ""}`);
+    if (patternCache.size < 1000) patternCache.set(pattern, re);
+  }
+  return re.test(path);
+}
@@ -0,0 +1,183 @@
+import { Inline, MdBlock, parseBlocks, plainText } from './blocks';
+import { displayWidth } from './format';
+
+/** Section underline characters, outermost first, in the order Sphinx's docs use them. */
+const UNDERLINES = ["=", "-", "~", "^", '"', "'"];
+
+interface RstContext {
+  /** Markdown levels of the open sections, so skipped levels ("#" then "###") don't break reST's nesting. */
+  sections: number[];
+  /** "|imageN|" substitution definitions waiting for the end of the current block. */
+  substitutions: string[];
+  imageCount: number;
+}
+
+/** Text or a piece of inline markup; markup needs an escaped space where it touches a word. */
+type Part = { text: string; markup: boolean };
+
+/**
+ * Renders converter markdown as reStructuredText for Sphinx. Tables become
+ * list-table directives, which unlike grid tables survive long cell text,
+ * inline images become substitutions, and raw HTML blocks become raw::
+ * directives. reST cannot nest inline markup, so bold text inside a link
+ * keeps only the link.
+ */
+export function markdownToRst(markdown: string): string {
+  const blocks = parseBlocks(markdown);
+  // A transition may not begin or end a document.
+  while (blocks[0]?.kind === "rule") blocks.shift();
+  while (blocks[blocks.length - 1]?.kind === "rule") blocks.pop();
+
+  const out = renderBlocks(blocks, { sections: [], substitutions: [], imageCount: 0 });
+  return out ? `${out}\n` : "";
+}
+
+function renderBlocks(blocks: MdBlock[], ctx: RstContext): string {
+  const out: string[] = [];
+  blocks.forEach((block, i) => {
+    // An indented quote straight after a list would read as part of the last item; an empty comment ends the list.
+    if (block.kind === "quote" && blocks[i - 1]?.kind === "list") out.push("..");
+    const rendered = renderBlock(block, ctx);
+    if (!rendered) return;
+    out.push(rendered);
+    if (ctx.substitutions.length) out.push(ctx.substitutions.splice(0).join("\n"));
+  });
+  return out.join("\n\n");
+}
+
+function renderBlock(block: MdBlock, ctx: RstContext): string {
+  switch (block.kind) {
+    case "heading": {
+      while (ctx.sections.length && ctx.sections[ctx.sections.length - 1] >= block.level) ctx.sections.pop();
+      ctx.sections.push(block.level);
+      const title = join(renderInline(block.content, ctx)).replace(/\n/g, " ");
+      const char = UNDERLINES[Math.min(ctx.sections.length, UNDERLINES.length) - 1];
+      return `${title}\n${char.repeat(Math.max(displayWidth(title), 3))}`;
+    }
+    case "paragraph": {
+      const [only] = block.content;
+      if (block.content.length === 1 && only.kind === "image") return imageDirective(only.src, only.alt);
+      const text = join(renderInline(block.content, ctx));
+      // Hard line breaks only survive in a line block.
+      if (block.content.some((n) => n.kind === "break")) return text.split("\n").map((line) => `| ${line}`).join("\n");
+      return guardLineStarts(text);
+    }
+    case "code":
+      return `${block.lang ? `.. code-block:: ${block.lang}` : "::"}\n\n${indent(block.code || " ", 3)}`;
+    case "quote":
+      return indent(renderBlocks(block.blocks, ctx), 3);
+    case "list": {
+      const items = block.items.map((item, i) => {
+        const marker = block.ordered ? `${block.start + i}.` : "-";
+        const task = item.task === undefined ? "" : item.task ? "☑ " : "☐ ";
+        const body = renderBlocks(item.blocks, ctx) || "\\";
+        return `${marker} ${task}${indent(body, marker.length + 1).trimStart()}`;
+      });
+      const compact = block.items.every((item) => item.blocks.length <= 1 && item.blocks[0]?.kind !== "list");
+      return items.join(compact ? "\n" : "\n\n");
+    }
+    case "table":
+      return renderTable(block, ctx);
+    case "rule":
+      return "----";
+    case "html":
+      return `.. raw:: html\n\n${indent(block.html, 3)}`;
+  }
+}
+
+function renderTable(block: Extract<MdBlock, { kind: "table" }>, ctx: RstContext): string {
+  const width = Math.max(block.header.length, ...block.rows.map((r) => r.length));
+  const row = (cells: Inline[][]) =>
+    Array.from({ length: width }, (_v, i) => {
+      const text = join(renderInline(cells[i] ?? [], ctx)).replace(/\n/g, " ");
+      return `${i === 0 ? "   * - " : "     - "}${text}`.trimEnd();
+    }).join("\n");
+
+  return [".. list-table::", "   :header-rows: 1", "", row(block.header), ...block.rows.map(row)].join("\n");
+}
+
+function renderInline(nodes: Inline[], ctx: RstContext): Part[] {
+  const parts: Part[] = [];
+  for (const node of nodes) {
+    switch (node.kind) {
+      case "text":
+        parts.push({ text: escapeText(node.text), markup: false });
+        break;
+      case "strong":
+      case "em":
+      case "sup": {
+        const inner = escapeText(plainText(node.children)).trim();
+        if (!inner) break;
+        const text = node.kind === "strong" ? `**${inner}**` : node.kind === "em" ? `*${inner}*` : `:sup:\`${inner}\``;
+        parts.push({ text, markup: true });
+        break;
+      }
+      case "strike":
+        // Plain reST has no strikethrough; the text is kept.
+        parts.push(...renderInline(node.children, ctx));
+        break;
+      case "code": {
+        const code = node.text.trim();
+        if (code) parts.push({ text: code.includes("``") ? `:code:\`${code.replace(/[`\\]/g, "\\$&")}\`` : `\`\`${code}\`\``, markup: true });
+        break;
+      }
+      case "link": {
+        const label = plainText(node.children).replace(/\s+/g, " ").trim();
+        if (node.href.startsWith("#")) {
+          parts.push({ text: label ? `\`${escapeInterpreted(label)}\`_` : "", markup: true });
+        } else if (!label || label === node.href || `mailto:${label}` === node.href) {
+          parts.push({ text: node.href.replace(/^mailto:/i, ""), markup: true });
+        } else {
+          parts.push({ text: `\`${escapeInterpreted(label)} <${node.href}>\`__`, markup: true });
+        }
+        break;
+      }
+      case "image": {
+        const name = `image${++ctx.imageCount}`;
+        ctx.substitutions.push(`.. |${name}| ${imageDirective(node.src, node.alt).slice(3)}`);
+        parts.push({ text: `|${name}|`, markup: true });
+        break;
+      }
+      case "break":
+        parts.push({ text: "\n", markup: false });
+        break;
+      case "html":
+        break;
+    }
+  }
+  return parts;
+}
+
+/** Joins parts, adding "\ " (an escaped, invisible space) where markup would otherwise touch a word. */
+function join(parts: Part[]): string {
+  let out = "";
+  parts.forEach((part, i) => {
+    if (!part.text) return;
+    if (part.markup && /[\p{L}\p{N}]$/u.test(out)) out += "\\ ";
+    out += part.text;
+    if (part.markup && /^[\p{L}\p{N}]/u.test(parts[i + 1]?.text || "")) out += "\\ ";
+  });
+  return out;
+}
+
+function imageDirective(src: string, alt: string): string {
+  return `.. image:: ${src}${alt ? `\n   :alt: ${alt.replace(/\n/g, " ")}` : ""}`;
+}
+
+function escapeText(text: string): string {
+  return text.replace(/[\\*`|]/g, "\\$&").replace(/_(?![\p{L}\p{N}])/gu, "\\_");
+}
+
+function escapeInterpreted(text: string): string {
+  return text.replace(/[\\`<>]/g, "\\$&");
+}
+
+/** List markers, directives and field markers at the start of a paragraph line are escaped. */
+function guardLineStarts(text: string): string {
+  return text.replace(/^(?=(?:[-+•]|#\.|\d+[.)]|\(\d+\)|\.\.|:[^:\s][^:]*:)(?:\s|$))/gm, "\\");
+}
+
+function indent(text: string, width: number): string {
+  const pad = " ".repeat(width);
+  return text.split("\n").map((line) => (line ? pad + line : line)).join("\n");
+}
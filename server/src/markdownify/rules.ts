@@ -0,0 +1,69 @@
+import * as cheerio from 'cheerio';
+import { selectElements } from './query';
+
+/**
+ * What a custom rule does with the elements it matches.
+ * - drop: remove the element and its content
+ * - unwrap: keep the content, lose the element (and its markdown, e.g. a link)
+ * - replace: emit `template` instead of the element's markdown
+ * - wrap: emit `prefix`, the element's markdown, then `suffix`
+ */
+export type RuleAction = "drop" | "unwrap" | "replace" | "wrap";
+
+/**
+ * A site quirk handled from configuration instead of code. In `template`,
+ * `prefix` and `suffix`, "{content}" is the element's converted markdown,
+ * "{text}" its plain text and "{attr:name}" one of its attributes.
+ */
+export interface ConversionRule {
+  /** CSS selector for the elements: by tag, class, attribute or any combination. */
+  selector: string;
+  action: RuleAction;
+  template?: string;
+  prefix?: string;
+  suffix?: string;
+}
+
+export const RULE_ACTIONS: readonly RuleAction[] = ["drop", "unwrap", "replace", "wrap"];
+
+/** Marks an element for the output rule with the index of the rule that matched it. */
+export const RULE_ATTR = "data-md-rule";
+
+const PLACEHOLDER_RE = /\{(content|text|attr:([^}]+))\}/g;
+
+/**
+ * Applies the DOM actions (drop, unwrap) and marks elements for the output
+ * actions, which need the converted content. Rules run in order and an
+ * element is claimed by the first rule that matches it.
+ */
+export function applyRules($: cheerio.CheerioAPI, rules: readonly ConversionRule[]): void {
+  // Tracked here rather than read back from RULE_ATTR, which the page could have written.
+  const claimed = new Set<any>();
+  rules.forEach((rule, index) => {
+    for (const el of selectElements($, rule.selector)) {
+      if (claimed.has(el)) continue;
+      claimed.add(el);
+      const $el = $(el);
+      if (rule.action === "drop") $el.remove();
+      else if (rule.action === "unwrap") $el.replaceWith($el.contents());
+      else $el.attr(RULE_ATTR, String(index));
+    }
+  });
+}
+
+/** The markdown for an element marked by applyRules; `content` is what its children converted to. */
+export function renderRule(rule: ConversionRule, content: string, node: any): string {
+  content = content.trim();
+  const fill = (template: string) =>
+    template.replace(PLACEHOLDER_RE, (_m, key: string, attr?: string) => {
+      if (attr) return node.getAttribute(attr.trim()) ?? "";
+      if (key === "text") return (node.textContent || "").trim();
+      return content;
+    });
+
+  const out = rule.action === "replace"
+    ? fill(rule.template ?? "")
+    : `${fill(rule.prefix ?? "")}${content}${fill(rule.suffix ?? "")}`;
+  if (!out.trim()) return "";
+  return node.isBlock ? `\n\n${out}\n\n` : out;
+}
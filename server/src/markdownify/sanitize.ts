@@ -0,0 +1,73 @@
+import * as cheerio from 'cheerio';
+
+/** What the sanitizer removed from a document. */
+export interface SanitizeReport {
+  /** Removed elements, counted by tag name. */
+  elements: Record<string, number>;
+  /** on* event-handler attributes removed. */
+  eventHandlers: number;
+  /** URL attributes dropped for javascript:, vbscript: or data:text/html targets. */
+  scriptUrls: number;
+}
+
+/**
+ * Elements whose content is code or another document rather than page text.
+ * <template> is easy to miss: its markup is inert in a browser, but a
+ * parser sees ordinary children and would convert them.
+ */
+const UNSAFE_SELECTOR = [
+  "script", "style", "template", "noembed", "noframes", "iframe", "frame",
+  "frameset", "object", "embed", "applet",
+].join(",");
+
+const URL_ATTRS = ["href", "src", "action", "formaction", "xlink:href", "data", "poster", "background", "srcset"];
+
+export function emptySanitizeReport(): SanitizeReport {
+  return { elements: {}, eventHandlers: 0, scriptUrls: 0 };
+}
+
+/**
+ * Strips executable content from a parsed document in place: script-bearing
+ * elements, event-handler attributes, srcdoc, and script URLs. Scraped HTML
+ * is untrusted; nothing here can carry text meant for the reader.
+ */
+export function sanitizeDocument($: cheerio.CheerioAPI, report: SanitizeReport = emptySanitizeReport()): SanitizeReport {
+  $(UNSAFE_SELECTOR).each((_i, el: any) => {
+    // Nested matches go with their ancestor and are not counted twice.
+    if ($(el).parents(UNSAFE_SELECTOR).length) return;
+    report.elements[el.name] = (report.elements[el.name] ?? 0) + 1;
+  });
+  $(UNSAFE_SELECTOR).remove();
+
+  $("*").each((_i, el: any) => {
+    for (const name of Object.keys(el.attribs ?? {})) {
+      const lower = name.toLowerCase();
+      if (lower.startsWith("on")) {
+        $(el).removeAttr(name);
+        report.eventHandlers++;
+      } else if (lower === "srcdoc") {
+        $(el).removeAttr(name);
+      } else if (URL_ATTRS.includes(lower) && isScriptUrl(el.attribs[name])) {
+        $(el).removeAttr(name);
+        report.scriptUrls++;
+      }
+    }
+  });
+
+  return report;
+}
+
+/** Sanitizes an HTML string; see sanitizeDocument. */
+export function sanitizeHtml(html: string | null | undefined): { html: string; report: SanitizeReport } {
+  const report = emptySanitizeReport();
+  if (!html) return { html: "", report };
+  const $ = cheerio.load(html);
+  sanitizeDocument($, report);
+  return { html: $.html(), report };
+}
+
+/** Browsers ignore control characters and whitespace inside the scheme, so "java\tscript:" still runs. */
+function isScriptUrl(value: string | undefined): boolean {
+  const normalized = (value || "").replace(/[\x00-\x20\x7F-\x9F]/g, "").toLowerCase();
+  return /(?:^|,)(?:javascript|vbscript):/.test(normalized) || /^data:text\/html/.test(normalized);
+}
@@ -0,0 +1,85 @@
+import { createHash } from 'crypto';
+import { MarkdownOptions, streamMarkdown, validateOptions } from './markdown';
+import { htmlBlocks } from './stream';
+
+export interface ConversionSession {
+  /**
+   * Converts what this snapshot of the page adds to the ones before it and
+   * returns that markdown, or "" when nothing is new.
+   */
+  update(html: string | null | undefined): Promise<string>;
+  /** Everything the session has returned, in order. */
+  readonly markdown: string;
+}
+
+const digest = (text: string) => createHash("sha1").update(text).digest("base64");
+
+/**
+ * A list item's chunk reopens its list with the item's position as `start`,
+ * which shifts when a virtualized list drops the items above it; these leave
+ * the position out so the item is recognized wherever it is.
+ */
+const OL_START_RE = /(<ol\b[^>]*?)\sstart\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+)/gi;
+const ORDERED_MARKER_RE = /^(\s*)\d+\. /gm;
+
+/**
+ * Follows a page that grows in place (infinite scroll, "load more") across
+ * successive HTML snapshots. Each snapshot is only tokenized into blocks,
+ * one per list item where the content is a list (see htmlBlocks); blocks
+ * seen in an earlier snapshot reuse their markdown without any parsing, so
+ * each scroll pass converts just what it added instead of the whole page
+ * again. A block's markdown is returned once for each copy of it in a
+ * single snapshot: identical blocks on one page (two "Sold out" cards) all
+ * come through, while a block whose markup changed but whose markdown didn't
+ * (a lazy image swap, a "seen" class) and items a virtualized list dropped
+ * and re-rendered, or that moved up when the items above them were dropped,
+ * are not returned twice. A copy that only reappears after its twin was
+ * dropped from the page can't be told from the twin, and is not returned.
+ *
+ * Conversion works as in streamMarkdown, so `toc` and `outputFormat` are not
+ * applied, and `onProgress` is not called, as a session has no end. When a
+ * block fails, update rejects with its ConversionError (PANIC_RECOVERED for
+ * a converter bug), and the session carries on as if the snapshot had
+ * never been given.
+ */
+export function createConversionSession(baseUrl?: string | null, options: MarkdownOptions = {}): ConversionSession {
+  validateOptions(options);
+  const blockOptions: MarkdownOptions = { ...options, onProgress: undefined };
+  // Markdown of every block converted so far, and its key, by the block's digest.
+  const converted = new Map<string, { md: string; key: string }>();
+  // The most copies of a markdown any one snapshot has had, all of them returned.
+  const emitted = new Map<string, number>();
+  const parts: string[] = [];
+
+  return {
+    async update(html) {
+      if (!html) return "";
+      const fresh: string[] = [];
+      // Recorded only once the whole snapshot converted, so a failed update leaves the session as it was.
+      const blocks = new Map<string, { md: string; key: string }>();
+      const counts = new Map<string, number>();
+      for await (const block of htmlBlocks(html, { chunkChars: 1 })) {
+        const blockKey = digest(block.replace(OL_START_RE, "$1"));
+        let result = converted.get(blockKey) ?? blocks.get(blockKey);
+        if (!result) {
+          let md = "";
+          for await (const piece of streamMarkdown(block, baseUrl, blockOptions)) md += piece;
+          result = { md, key: digest(md.replace(ORDERED_MARKER_RE, "$11. ").replace(/\s+/g, " ").trim()) };
+          blocks.set(blockKey, result);
+        }
+        if (!result.md) continue;
+
+        const count = (counts.get(result.key) ?? 0) + 1;
+        counts.set(result.key, count);
+        if (count > (emitted.get(result.key) ?? 0)) fresh.push(result.md);
+      }
+      for (const [blockKey, result] of blocks) converted.set(blockKey, result);
+      for (const [key, count] of counts) if (count > (emitted.get(key) ?? 0)) emitted.set(key, count);
+      parts.push(...fresh);
+      return fresh.join("\n\n");
+    },
+    get markdown() {
+      return parts.join("\n\n");
+    },
+  };
+}
@@ -0,0 +1,35 @@
+import * as cheerio from 'cheerio';
+
+const SHADOW_ROOT_SELECTOR = "template[shadowrootmode], template[shadowroot]";
+
+/**
+ * Replaces each declarative shadow root (<template shadowrootmode>, or the
+ * older shadowroot attribute) with the tree a browser renders: the template's
+ * content becomes the host's children, and every <slot> is filled with the
+ * host's light-DOM children assigned to it, or keeps its fallback content
+ * when none are. Light-DOM children that no slot takes are not rendered and
+ * are dropped, as in a browser. Runs before sanitizing, which removes any
+ * <template> left over.
+ */
+export function flattenShadowRoots($: cheerio.CheerioAPI): void {
+  // Document order puts nested roots after their ancestors; going backwards composes the innermost first.
+  for (const template of $(SHADOW_ROOT_SELECTOR).toArray().reverse()) {
+    const $host = $(template).parent();
+    // Only a host's first declarative shadow root attaches.
+    if (!$host.length || $host.children(SHADOW_ROOT_SELECTOR).first()[0] !== template) continue;
+
+    const light = $host.contents().toArray().filter((node) => node !== template);
+    const shadow = cheerio.load($(template).html() || "", null, false);
+
+    shadow("slot").each((_i, slot) => {
+      const name = shadow(slot).attr("name") || "";
+      const assigned = light.filter((node: any) =>
+        node.type === "tag" ? ($(node).attr("slot") || "") === name : name === "" && node.type === "text"
+      );
+      if (assigned.length) shadow(slot).replaceWith(assigned.map((node) => $.html(node)).join(""));
+      else shadow(slot).replaceWith(shadow(slot).contents());
+    });
+
+    $host.empty().append(shadow.html());
+  }
+}
@@ -0,0 +1,125 @@
+import { parseBlocks, plainText } from './blocks';
+import { plainHeadingText } from './outline';
+
+/** Google Sheets rejects cells longer than this many characters. */
+export const SHEETS_CELL_LIMIT = 50000;
+
+export interface SheetsOptions {
+  /** Longest cell produced (default SHEETS_CELL_LIMIT). */
+  maxCellChars?: number;
+}
+
+export interface SheetsTable {
+  /** Headings above the table, outermost first. */
+  headings: string[];
+  /** Header row first, every row padded to the same width. */
+  rows: string[][];
+}
+
+export interface SheetsExport {
+  /** The document without its tables, split into cell-sized pieces at block boundaries. */
+  text: string[];
+  tables: SheetsTable[];
+}
+
+const DELIMITER_ROW_RE = /^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$/;
+
+/**
+ * Flattens converted markdown into values that survive a spreadsheet: tables
+ * come out as row arrays (one cell per column), and the remaining text is
+ * split under the cell limit with newlines and pipes escaped, so one document
+ * never spills into broken rows. Every value has gone through sheetCell.
+ */
+export function flattenForSheets(markdown: string | null | undefined, options: SheetsOptions = {}): SheetsExport {
+  const limit = Math.max(100, options.maxCellChars ?? SHEETS_CELL_LIMIT);
+  const lines = (markdown || "").replace(/\r\n?/g, "\n").split("\n");
+  const tables: SheetsTable[] = [];
+  const text: string[] = [];
+  const breadcrumb: string[] = [];
+  let fence: string | null = null;
+
+  for (let i = 0; i < lines.length; i++) {
+    const line = lines[i];
+    const marker = line.match(/^\s{0,3}(`{3,}|~{3,})/);
+    if (marker) {
+      if (!fence) fence = marker[1];
+      else if (marker[1][0] === fence[0] && marker[1].length >= fence.length) fence = null;
+    }
+    if (fence || marker) {
+      text.push(line);
+      continue;
+    }
+
+    const heading = line.match(/^\s{0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$/);
+    if (heading) {
+      breadcrumb.length = heading[1].length - 1;
+      breadcrumb[heading[1].length - 1] = plainHeadingText(heading[2]);
+    }
+
+    if (line.includes("|") && DELIMITER_ROW_RE.test(lines[i + 1] || "") && lines[i + 1].includes("-")) {
+      const start = i;
+      for (i += 2; i < lines.length && lines[i].trim() && lines[i].includes("|"); i++);
+      const [block] = parseBlocks(lines.slice(start, i).join("\n"));
+      i--;
+      if (block?.kind !== "table") continue;
+
+      const rows = [block.header, ...block.rows].map((row) => row.map((cell) => sheetCell(plainText(cell), limit)));
+      const width = Math.max(...rows.map((r) => r.length));
+      tables.push({ headings: breadcrumb.filter(Boolean), rows: rows.map((r) => [...r, ...Array(width - r.length).fill("")]) });
+      continue;
+    }
+    text.push(line);
+  }
+
+  const body = text.join("\n").replace(/\n{3,}/g, "\n\n").trim();
+  return { text: body ? splitCells(body, limit) : [], tables };
+}
+
+/**
+ * Makes one value safe for a cell and for TSV: newlines become "\n", tabs
+ * "\t" and pipes "\|", anything that Sheets would evaluate as a formula gets
+ * the apostrophe prefix that keeps it text, and the result is cut to `limit`.
+ */
+export function sheetCell(value: string | null | undefined, limit = SHEETS_CELL_LIMIT): string {
+  let out = (value ?? "").replace(/\r\n?/g, "\n").replace(/\n/g, "\\n").replace(/\t/g, "\\t").replace(/\|/g, "\\|");
+  if (/^[=+\-@]/.test(out)) out = `'${out}`;
+  return out.length > limit ? out.slice(0, limit - 1).replace(/\\$/, "") + "…" : out;
+}
+
+/** Tab-separated rows, each value passed through sheetCell. */
+export function toTsv(rows: string[][]): string {
+  return rows.map((row) => row.map((value) => sheetCell(value)).join("\t")).join("\n");
+}
+
+/** Packs blocks into cells under the limit, breaking oversized blocks at whitespace. */
+function splitCells(markdown: string, limit: number): string[] {
+  const cells: string[] = [];
+  let current = "";
+
+  const pushPiece = (piece: string) => {
+    const joined = current ? `${current}\n\n${piece}` : piece;
+    if (sheetCell(joined, Infinity).length <= limit) {
+      current = joined;
+      return;
+    }
+    if (current) cells.push(sheetCell(current, limit));
+    current = "";
+    if (sheetCell(piece, Infinity).length <= limit) {
+      current = piece;
+      return;
+    }
+    // Escaping can double the length, so cut raw text at half the limit and at a space where there is one.
+    let rest = piece;
+    while (sheetCell(rest, Infinity).length > limit) {
+      const window = rest.slice(0, Math.floor(limit / 2));
+      const cut = window.lastIndexOf(" ") > window.length / 2 ? window.lastIndexOf(" ") : window.length;
+      cells.push(sheetCell(rest.slice(0, cut).trimEnd(), limit));
+      rest = rest.slice(cut).trimStart();
+    }
+    current = rest;
+  };
+
+  for (const block of markdown.split(/\n{2,}/)) pushPiece(block);
+  if (current) cells.push(sheetCell(current, limit));
+  return cells;
+}
@@ -0,0 +1,118 @@
+import * as cheerio from 'cheerio';
+import * as zlib from 'zlib';
+import logger from '../logger';
+
+export interface SitemapUrl {
+  url: string;
+  /** ISO-8601; omitted when missing or unparseable. */
+  lastmod?: string;
+  changefreq?: string;
+  /** Between 0 and 1; the protocol default of 0.5 is not filled in. */
+  priority?: number;
+}
+
+export interface SitemapEntry {
+  url: string;
+  lastmod?: string;
+}
+
+export interface ParsedSitemap {
+  kind: "urlset" | "sitemapindex" | "text";
+  /** Page URLs, for urlset and plain-text sitemaps. */
+  urls: SitemapUrl[];
+  /** Child sitemaps, for sitemap index files; the caller decides whether to fetch them. */
+  sitemaps: SitemapEntry[];
+}
+
+/** The protocol caps a single sitemap at 50,000 URLs; anything past that is ignored. */
+const MAX_ENTRIES = 50000;
+
+/**
+ * Parses a sitemap.xml, a sitemap index or a plain-text sitemap (one URL per
+ * line). Gzipped input is detected by its magic bytes rather than the file
+ * name, since servers often send .xml.gz without a gzip content encoding.
+ */
+export function parseSitemap(input: string | Buffer | null | undefined): ParsedSitemap {
+  const result: ParsedSitemap = { kind: "urlset", urls: [], sitemaps: [] };
+  const text = decodeSitemap(input);
+  if (!text.trim()) return result;
+
+  if (!/^\s*</.test(text)) {
+    result.kind = "text";
+    for (const line of text.split(/\r?\n/)) {
+      const url = line.trim();
+      if (/^https?:\/\//i.test(url) && result.urls.length < MAX_ENTRIES) result.urls.push({ url });
+    }
+    return result;
+  }
+
+  const $ = cheerio.load(text, { xml: true });
+  // Tags are matched by local name so prefixed documents (<sm:url>) parse too.
+  const byLocalName = (parent: cheerio.Cheerio<any>, name: string) =>
+    parent.children().filter((_i, el: any) => localName(el.name) === name);
+  const childText = (parent: cheerio.Cheerio<any>, name: string) => byLocalName(parent, name).first().text().trim();
+
+  const root = $.root().children().filter((_i, el: any) => el.type === "tag").first();
+  if (localName(root.prop("tagName") || "") === "sitemapindex") {
+    result.kind = "sitemapindex";
+    byLocalName(root, "sitemap").each((_i, el) => {
+      if (result.sitemaps.length >= MAX_ENTRIES) return false;
+      const $el = $(el);
+      const url = childText($el, "loc");
+      if (!url) return;
+      const entry: SitemapEntry = { url };
+      const lastmod = toIsoDate(childText($el, "lastmod"));
+      if (lastmod) entry.lastmod = lastmod;
+      result.sitemaps.push(entry);
+    });
+    return result;
+  }
+
+  byLocalName(root, "url").each((_i, el) => {
+    if (result.urls.length >= MAX_ENTRIES) return false;
+    const $el = $(el);
+    const url = childText($el, "loc");
+    if (!url) return;
+    const entry: SitemapUrl = { url };
+    const lastmod = toIsoDate(childText($el, "lastmod"));
+    if (lastmod) entry.lastmod = lastmod;
+    const changefreq = childText($el, "changefreq").toLowerCase();
+    if (changefreq) entry.changefreq = changefreq;
+    const priority = parseFloat(childText($el, "priority"));
+    if (!Number.isNaN(priority)) entry.priority = Math.min(1, Math.max(0, priority));
+    result.urls.push(entry);
+  });
+
+  return result;
+}
+
+/** The protocol's limit on an uncompressed sitemap; a bigger one is a gzip bomb or broken. */
+const MAX_SITEMAP_BYTES = 50 * 1024 * 1024;
+
+function decodeSitemap(input: string | Buffer | null | undefined): string {
+  if (!input) return "";
+  if (typeof input === "string") return input;
+  if (input.length > 2 && input[0] === 0x1f && input[1] === 0x8b) {
+    try {
+      return zlib.gunzipSync(input, { maxOutputLength: MAX_SITEMAP_BYTES }).toString("utf8");
+    } catch (err: any) {
+      if (err.code === "ERR_BUFFER_TOO_LARGE") {
+        logger.log('warn', `Skipping sitemap: it unpacks to more than ${MAX_SITEMAP_BYTES} bytes`);
+        return "";
+      }
+      logger.log('warn', `Failed to gunzip sitemap: ${err.message}`);
+      return "";
+    }
+  }
+  return input.toString("utf8");
+}
+
+function localName(name: string): string {
+  return name.replace(/^.*:/, "").toLowerCase();
+}
+
+function toIsoDate(value: string): string | undefined {
+  if (!value) return undefined;
+  const date = new Date(value);
+  return Number.isNaN(date.getTime()) ? undefined : date.toISOString();
+}
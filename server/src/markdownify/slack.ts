@@ -0,0 +1,105 @@
+import { Inline, MdBlock, parseBlocks, plainText } from './blocks';
+import { displayWidth } from './format';
+
+/**
+ * Renders converter markdown in Slack's mrkdwn dialect: single-character
+ * emphasis (*bold*, _italic_, ~strike~), <url|text> links and no headings,
+ * so headings become bold lines. Slack has no tables either; they are laid
+ * out as aligned text in a code block, which keeps the columns readable.
+ */
+export function markdownToSlack(markdown: string): string {
+  const out = renderBlocks(parseBlocks(markdown), 0);
+  return out ? `${out}\n` : "";
+}
+
+function renderBlocks(blocks: MdBlock[], depth: number): string {
+  return blocks.map((block) => renderBlock(block, depth)).filter(Boolean).join("\n\n");
+}
+
+function renderBlock(block: MdBlock, depth: number): string {
+  switch (block.kind) {
+    case "heading":
+      return emphasis("*", renderInline(block.content).replace(/\n/g, " "));
+    case "paragraph":
+      return renderInline(block.content);
+    case "code":
+      return codeBlock(block.code);
+    case "quote":
+      return renderBlocks(block.blocks, depth).split("\n").map((line) => `> ${line}`.trimEnd()).join("\n");
+    case "list":
+      return block.items
+        .map((item, i) => {
+          const marker = block.ordered ? `${block.start + i}.` : depth % 2 ? "◦" : "•";
+          const task = item.task === undefined ? "" : item.task ? "☑ " : "☐ ";
+          // Nested lists and later paragraphs hang under the item's text.
+          const body = item.blocks.map((b) => renderBlock(b, depth + 1)).filter(Boolean).join("\n");
+          return `${marker} ${task}${body.replace(/\n/g, "\n    ")}`.trimEnd();
+        })
+        .join("\n");
+    case "table":
+      return codeBlock(layoutTable([block.header, ...block.rows].map((row) => row.map((cell) => plainText(cell).replace(/\s+/g, " ").trim()))));
+    case "rule":
+      return "———";
+    case "html":
+      return escape(block.html.replace(/<[^>]+>/g, " ").replace(/\s+/g, " ").trim());
+  }
+}
+
+/** Plain columns padded to a common width, header separated by a dashed line. */
+function layoutTable(rows: string[][]): string {
+  const width = Math.max(...rows.map((r) => r.length));
+  const columns = Array.from({ length: width }, (_v, i) => Math.max(...rows.map((r) => displayWidth(r[i] ?? ""))));
+  const line = (row: string[]) =>
+    columns.map((w, i) => (row[i] ?? "") + " ".repeat(w - displayWidth(row[i] ?? ""))).join("  ").trimEnd();
+  const [header, ...body] = rows;
+  return [line(header), columns.map((w) => "-".repeat(w)).join("  "), ...body.map(line)].join("\n");
+}
+
+function renderInline(nodes: Inline[]): string {
+  return nodes
+    .map((node) => {
+      switch (node.kind) {
+        case "text":
+          return escape(node.text);
+        case "strong":
+          return emphasis("*", renderInline(node.children));
+        case "em":
+          return emphasis("_", renderInline(node.children));
+        case "strike":
+          return emphasis("~", renderInline(node.children));
+        case "sup":
+          return renderInline(node.children);
+        case "code":
+          return node.text.trim() ? `\`${escape(node.text).replace(/`/g, "'")}\`` : "";
+        case "link": {
+          const text = plainText(node.children).replace(/\s+/g, " ").trim();
+          if (node.href.startsWith("#")) return escape(text);
+          const url = node.href.replace(/[<>|]/g, encodeURIComponent);
+          return text && text !== node.href ? `<${url}|${escape(text).replace(/\|/g, "¦")}>` : `<${url}>`;
+        }
+        case "image":
+          return `<${node.src.replace(/[<>|]/g, encodeURIComponent)}|${escape(node.alt || "image").replace(/\|/g, "¦")}>`;
+        case "break":
+          return "\n";
+        case "html":
+          return "";
+      }
+    })
+    .join("");
+}
+
+/** Slack only honours a mark that hugs its text, so surrounding spaces move outside it. */
+function emphasis(mark: string, text: string): string {
+  const m = text.match(/^(\s*)([\s\S]*?)(\s*)$/)!;
+  return m[2] ? `${m[1]}${mark}${m[2]}${mark}${m[3]}` : text;
+}
+
+function codeBlock(code: string): string {
+  // A zero-width space keeps a fence inside the code from closing the block.
+  return `\`\`\`\n${escape(code).replace(/```/g, "`\u200B``")}\n\`\`\``;
+}
+
+/** The three characters Slack requires as entities; everything else passes through. */
+function escape(text: string): string {
+  return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+}
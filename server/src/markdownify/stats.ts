@@ -0,0 +1,53 @@
+import { markdownToPlainText } from './text';
+
+export interface TextStats {
+  words: number;
+  /** Characters of visible text, excluding markup. */
+  characters: number;
+  paragraphs: number;
+  /** Estimated minutes at 230 words/min (or 500 CJK characters/min). */
+  readingTimeMinutes: number;
+  /** Share (0–1) of visible characters that belong to link text. */
+  linkDensity: number;
+}
+
+const WORDS_PER_MINUTE = 230;
+const CJK_CHARS_PER_MINUTE = 500;
+const CJK = /[\u3040-\u30FF\u3400-\u4DBF\u4E00-\u9FFF\uAC00-\uD7AF]/g;
+
+const wordSegmenter = new Intl.Segmenter(undefined, { granularity: "word" });
+
+/**
+ * Computes size and shape statistics for converted markdown, measured on the
+ * visible text so markup and URLs never inflate the numbers. Words are counted
+ * with Intl.Segmenter, which handles scripts without spaces.
+ */
+export function computeTextStats(markdown: string | null | undefined): TextStats {
+  const text = markdownToPlainText(markdown);
+  const characters = text.replace(/\s+/g, "").length;
+
+  let words = 0;
+  for (const segment of wordSegmenter.segment(text)) {
+    if (segment.isWordLike) words++;
+  }
+
+  const paragraphs = (markdown || "")
+    .split(/\n\s*\n/)
+    .map((block) => block.trim())
+    .filter((block) => block && !/^(?:#{1,6}\s|`{3,}|~{3,}|\||[-*_]{3,}$|[-*+]\s|\d+[.)]\s)/.test(block))
+    .length;
+
+  const linkChars = [...(markdown || "").matchAll(/(?<!!)\[([^\]]*)\]\([^)]*\)/g)]
+    .reduce((sum, m) => sum + m[1].replace(/\s+/g, "").length, 0);
+
+  const cjkChars = (text.match(CJK) || []).length;
+  const minutes = (words - Math.min(words, cjkChars)) / WORDS_PER_MINUTE + cjkChars / CJK_CHARS_PER_MINUTE;
+
+  return {
+    words,
+    characters,
+    paragraphs,
+    readingTimeMinutes: Math.round(minutes * 10) / 10,
+    linkDensity: characters ? Math.round((Math.min(linkChars, characters) / characters) * 1000) / 1000 : 0,
+  };
+}
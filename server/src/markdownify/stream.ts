@@ -0,0 +1,224 @@
+import { END_TAG_RE, impliedCloseIndex, RAW_TEXT_ELEMENTS, RESUME_RE, START_TAG_RE, VOID_ELEMENTS } from './repair';
+
+export interface HtmlBlockOptions {
+  /** Buffered HTML that triggers a flush at the next block boundary (default 64 KB). */
+  chunkChars?: number;
+}
+
+interface StreamElement {
+  name: string;
+  /** The start tag as written, for reopening a list in the next chunk. */
+  tag: string;
+  /** Whether the start tag went into the current chunk, so the chunk has to close it. */
+  buffered: boolean;
+  /** Page chrome or document head: everything inside is dropped. */
+  skip: boolean;
+  /** Items closed so far, for lists. */
+  items: number;
+}
+
+/** Lists are the one structure a chunk may end inside of, between two items, so a long list doesn't become one chunk. */
+const LISTS = new Set(["ul", "ol"]);
+
+/** Raw-text elements that are never content. */
+const DROPPED_RAW_TEXT = new Set(["script", "style", "title", "iframe", "noembed", "noframes", "noscript"]);
+
+const HEAD_CONTENT = new Set(["meta", "link", "title", "base", "script", "style", "noscript", "template"]);
+
+/** Structures converted as a whole: a chunk never ends inside one. */
+const ATOMIC = new Set([
+  "table", "ul", "ol", "dl", "pre", "blockquote", "figure", "details", "select", "svg", "math", "picture",
+  "a", "button", "label", "p", "h1", "h2", "h3", "h4", "h5", "h6", "li", "dt", "dd", "tr", "td", "th",
+  "caption", "code", "template",
+]);
+
+/** End tags after which a chunk may end. */
+const BLOCK_ENDS = new Set([
+  "p", "div", "section", "article", "main", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "dl", "table",
+  "pre", "blockquote", "figure", "details", "header", "footer", "address", "fieldset", "form", "center",
+]);
+
+const CHROME_ROLE_RE = /\brole\s*=\s*["']?(?:navigation|banner|contentinfo|search|complementary)\b/i;
+
+/** How long an unfinished tag may wait for more input before its "<" is taken as text. */
+const MAX_PENDING_TAG = 64 * 1024;
+
+/**
+ * Splits HTML into self-contained chunks at block boundaries without ever
+ * building a document tree, so memory stays proportional to the largest
+ * block rather than the page. Input may arrive in pieces. Page chrome
+ * (<nav>, <aside>, header and footer outside an article, landmark roles),
+ * the <head> and scripts are dropped on the way, since without a whole
+ * document there is no main-content detection to remove them later.
+ * Each chunk is balanced: elements still open when it ends are closed in it,
+ * and a list split between chunks is reopened (numbering carried on) in the next.
+ */
+export async function* htmlBlocks(
+  input: string | AsyncIterable<string | Buffer>,
+  options: HtmlBlockOptions = {}
+): AsyncGenerator<string> {
+  const chunkChars = options.chunkChars ?? 64 * 1024;
+  const stack: StreamElement[] = [];
+  const ready: string[] = [];
+  let buffer: string[] = [];
+  let buffered = 0;
+  /** Whether the chunk holds more than tags it carried over or had to close. */
+  let hasContent = false;
+  let skipDepth = 0;
+  let pending = "";
+
+  const append = (html: string, content = true) => {
+    if (skipDepth) return;
+    buffer.push(html);
+    buffered += html.length;
+    if (content && html.trim()) hasContent = true;
+  };
+  const flush = () => {
+    for (let i = stack.length - 1; i >= 0; i--) {
+      if (!stack[i].buffered) continue;
+      buffer.push(`</${stack[i].name}>`);
+      stack[i].buffered = false;
+    }
+    const chunk = buffer.join("");
+    buffer = [];
+    buffered = 0;
+    if (hasContent) ready.push(chunk);
+    hasContent = false;
+    for (const el of stack) {
+      if (!LISTS.has(el.name) || el.skip) continue;
+      el.buffered = true;
+      append(el.name === "ol" ? continueNumbering(el.tag, el.items) : el.tag, false);
+    }
+  };
+  const open = (name: string) => stack.some((el) => el.name === name);
+  const pop = () => {
+    const el = stack.pop()!;
+    if (el.buffered) append(`</${el.name}>`, false);
+    if (el.skip) skipDepth--;
+  };
+  /** Closes the element at `index` and everything inside it, then ends the chunk if this is a good place to. */
+  const closeFrom = (index: number) => {
+    const name = stack[index].name;
+    while (stack.length > index) pop();
+    const parent = stack[stack.length - 1];
+    const betweenItems = name === "li" && parent !== undefined && LISTS.has(parent.name);
+    if (betweenItems) parent.items++;
+    const atomic = stack.some((el) => ATOMIC.has(el.name) && !(betweenItems && el === parent));
+    if ((BLOCK_ENDS.has(name) || betweenItems) && !skipDepth && buffered >= chunkChars && !atomic) flush();
+  };
+
+  const startTag = (name: string, tag: string, selfClosing: boolean) => {
+    if (name === "html") return;
+    if (open("head") && (name === "body" || !HEAD_CONTENT.has(name))) {
+      while (open("head")) pop();
+    }
+    if (name === "body") return;
+    const implied = impliedCloseIndex(stack.map((el) => el.name), name);
+    if (implied !== -1) closeFrom(implied);
+    if (VOID_ELEMENTS.has(name) || (selfClosing && (open("svg") || open("math")))) {
+      append(tag);
+      return;
+    }
+    const chrome =
+      name === "head" || name === "nav" || name === "aside" || CHROME_ROLE_RE.test(tag) ||
+      ((name === "header" || name === "footer") && !open("article") && !open("main") && !open("section"));
+    const skip = !skipDepth && chrome;
+    stack.push({ name, tag, buffered: !skipDepth && !skip, skip, items: 0 });
+    if (skip) skipDepth++;
+    else append(tag, false);
+  };
+
+  const endTag = (name: string) => {
+    if (name === "html" || name === "body") return;
+    let index = stack.length - 1;
+    while (index >= 0 && stack[index].name !== name) index--;
+    if (index !== -1) closeFrom(index);
+  };
+
+  /** Consumes every complete token in `pending`; at the end of input, whatever is left. */
+  const consume = (final: boolean) => {
+    let pos = 0;
+    while (pos < pending.length) {
+      const lt = pending.indexOf("<", pos);
+      if (lt === -1) {
+        append(pending.slice(pos));
+        pos = pending.length;
+        break;
+      }
+      if (lt > pos) append(pending.slice(pos, lt));
+      pos = lt;
+
+      if (pending.startsWith("<!--", pos)) {
+        const end = pending.indexOf("-->", pos + 4);
+        if (end !== -1) pos = end + 3;
+        else if (!final) break;
+        else {
+          RESUME_RE.lastIndex = pos + 4;
+          pos = RESUME_RE.exec(pending)?.index ?? pending.length;
+        }
+        continue;
+      }
+      if (pending[pos + 1] === "!" || pending[pos + 1] === "?") {
+        const gt = pending.indexOf(">", pos);
+        if (gt === -1 && !final) break;
+        pos = gt === -1 ? pending.length : gt + 1;
+        continue;
+      }
+
+      END_TAG_RE.lastIndex = pos;
+      const end = END_TAG_RE.exec(pending);
+      if (end) {
+        pos = END_TAG_RE.lastIndex;
+        endTag(end[1].toLowerCase());
+        continue;
+      }
+      START_TAG_RE.lastIndex = pos;
+      const start = START_TAG_RE.exec(pending);
+      if (!start) {
+        if (!final && pending.length - pos < MAX_PENDING_TAG) break;
+        append("&lt;");
+        pos++;
+        continue;
+      }
+
+      const name = start[1].toLowerCase();
+      if (RAW_TEXT_ELEMENTS.has(name) && !open("svg") && !open("math")) {
+        const closeRe = new RegExp(`</${name}\\s*>`, "ig");
+        closeRe.lastIndex = START_TAG_RE.lastIndex;
+        const close = closeRe.exec(pending);
+        if (!close && !final) break;
+        let stop = close ? closeRe.lastIndex : pending.length;
+        if (!close) {
+          RESUME_RE.lastIndex = START_TAG_RE.lastIndex;
+          stop = RESUME_RE.exec(pending)?.index ?? pending.length;
+        }
+        if (!DROPPED_RAW_TEXT.has(name)) {
+          const content = pending.slice(START_TAG_RE.lastIndex, close ? close.index : stop);
+          append(`${start[0]}${content}</${name}>`);
+        }
+        pos = stop;
+        continue;
+      }
+      pos = START_TAG_RE.lastIndex;
+      startTag(name, start[0], start[3] === "/");
+    }
+    pending = pending.slice(pos);
+  };
+
+  const decoder = new TextDecoder();
+  for await (const piece of typeof input === "string" ? [input] : input) {
+    pending += typeof piece === "string" ? piece : decoder.decode(piece, { stream: true });
+    consume(false);
+    while (ready.length) yield ready.shift()!;
+  }
+  pending += decoder.decode();
+  consume(true);
+  flush();
+  while (ready.length) yield ready.shift()!;
+}
+
+/** The start tag of an ordered list resumed after `items` items. */
+function continueNumbering(tag: string, items: number): string {
+  const start = Number(tag.match(/\sstart\s*=\s*["']?(-?\d+)/i)?.[1] ?? 1);
+  return tag.replace(/\sstart\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+)/i, "").replace(/^<ol/i, `<ol start="${start + items}"`);
+}
@@ -0,0 +1,171 @@
+import * as cheerio from 'cheerio';
+import { resolveUrl } from './urls';
+
+export type StructuredDataFormat = "json-ld" | "microdata" | "rdfa";
+
+export interface StructuredDataItem {
+  format: StructuredDataFormat;
+  /** Short type names with any schema.org namespace stripped, e.g. ["Product"]. */
+  types: string[];
+  data: Record<string, any>;
+}
+
+/**
+ * Parses JSON-LD blocks, microdata (itemscope/itemprop) and RDFa
+ * (typeof/property) into a flat list of typed items. Many sites already
+ * publish exactly the data robots scrape, so this is often more reliable than
+ * selectors. Malformed blocks are skipped rather than failing the whole page.
+ */
+export function extractStructuredData(html: string | null | undefined, baseUrl?: string | null): StructuredDataItem[] {
+  if (!html) return [];
+
+  const $ = cheerio.load(html);
+  return [
+    ...extractJsonLd($),
+    ...extractMicrodata($, baseUrl ?? null),
+    ...extractRdfa($, baseUrl ?? null),
+  ];
+}
+
+export function shortTypeName(type: string): string {
+  return type.replace(/^https?:\/\/(?:www\.)?schema\.org\//i, "").replace(/^schema:/i, "").trim();
+}
+
+function toTypeList(value: unknown): string[] {
+  const list = Array.isArray(value) ? value : value ? [value] : [];
+  return list.filter((v): v is string => typeof v === "string").map(shortTypeName).filter(Boolean);
+}
+
+function parseJsonLenient(raw: string): any {
+  try {
+    return JSON.parse(raw);
+  } catch {
+    // HTML comment wrappers, trailing commas and raw control characters are
+    // the usual culprits in hand-written blocks.
+    const repaired = raw
+      .trim()
+      .replace(/^<!--/, "")
+      .replace(/-->$/, "")
+      .replace(/,\s*([}\]])/g, "$1")
+      .replace(/[\u0000-\u001F]+/g, " ");
+    return JSON.parse(repaired);
+  }
+}
+
+function extractJsonLd($: cheerio.CheerioAPI): StructuredDataItem[] {
+  const items: StructuredDataItem[] = [];
+
+  const collect = (node: any) => {
+    if (Array.isArray(node)) {
+      node.forEach(collect);
+      return;
+    }
+    if (!node || typeof node !== "object") return;
+    if (Array.isArray(node["@graph"])) {
+      node["@graph"].forEach(collect);
+      if (!node["@type"]) return;
+    }
+    items.push({ format: "json-ld", types: toTypeList(node["@type"]), data: node });
+  };
+
+  $('script[type="application/ld+json" i]').each((_i, el) => {
+    const raw = $(el).contents().text();
+    if (!raw.trim()) return;
+    try {
+      collect(parseJsonLenient(raw));
+    } catch {
+    }
+  });
+
+  return items;
+}
+
+/**
+ * Value of a microdata/RDFa property element per the HTML spec's rules:
+ * URL-bearing elements yield their resolved URL, time/data/meter their
+ * machine-readable value, everything else its text.
+ */
+function propertyValue($el: cheerio.Cheerio<any>, baseUrl: string | null, contentAttr: string): string {
+  const tag = ($el.get(0)?.name || "").toLowerCase();
+  const attr = (name: string) => $el.attr(name)?.trim();
+
+  const explicit = attr(contentAttr);
+  if (explicit !== undefined) return explicit;
+
+  if (["a", "area", "link"].includes(tag) && attr("href")) return resolveUrl(attr("href")!, baseUrl);
+  if (["img", "audio", "video", "source", "embed", "iframe", "track"].includes(tag) && attr("src")) {
+    return resolveUrl(attr("src")!, baseUrl);
+  }
+  if (tag === "object" && attr("data")) return resolveUrl(attr("data")!, baseUrl);
+  if (tag === "time" && attr("datetime")) return attr("datetime")!;
+  if ((tag === "data" || tag === "meter") && attr("value")) return attr("value")!;
+
+  return $el.text().replace(/\s+/g, " ").trim();
+}
+
+function addProperty(target: Record<string, any>, name: string, value: any): void {
+  if (target[name] === undefined) target[name] = value;
+  else if (Array.isArray(target[name])) target[name].push(value);
+  else target[name] = [target[name], value];
+}
+
+function extractMicrodata($: cheerio.CheerioAPI, baseUrl: string | null): StructuredDataItem[] {
+  const readItem = (scope: any): Record<string, any> => {
+    const $scope = $(scope);
+    const data: Record<string, any> = {};
+    const types = ($scope.attr("itemtype") || "").split(/\s+/).filter(Boolean);
+    if (types.length) data["@type"] = types.length === 1 ? shortTypeName(types[0]) : types.map(shortTypeName);
+    if ($scope.attr("itemid")) data["@id"] = $scope.attr("itemid");
+
+    // A property belongs to this scope when its nearest itemscope ancestor is
+    // the scope itself; deeper properties belong to nested items.
+    $scope.find("[itemprop]").each((_i, el) => {
+      const $el = $(el);
+      if ($el.parent().closest("[itemscope]").get(0) !== scope) return;
+      const value = $el.is("[itemscope]") ? readItem(el) : propertyValue($el, baseUrl, "content");
+      for (const name of ($el.attr("itemprop") || "").split(/\s+/).filter(Boolean)) {
+        addProperty(data, name, value);
+      }
+    });
+
+    return data;
+  };
+
+  const items: StructuredDataItem[] = [];
+  $("[itemscope]").not("[itemprop]").each((_i, el) => {
+    const data = readItem(el);
+    items.push({ format: "microdata", types: toTypeList(data["@type"]), data });
+  });
+  return items;
+}
+
+function extractRdfa($: cheerio.CheerioAPI, baseUrl: string | null): StructuredDataItem[] {
+  const stripPrefix = (name: string) => name.replace(/^https?:\/\/(?:www\.)?schema\.org\//i, "").replace(/^[a-z][\w-]*:(?!\/\/)/i, "");
+
+  const readItem = (scope: any): Record<string, any> => {
+    const $scope = $(scope);
+    const data: Record<string, any> = {};
+    const types = ($scope.attr("typeof") || "").split(/\s+/).filter(Boolean).map(stripPrefix);
+    if (types.length) data["@type"] = types.length === 1 ? types[0] : types;
+    const id = $scope.attr("resource") || $scope.attr("about");
+    if (id) data["@id"] = resolveUrl(id, baseUrl);
+
+    $scope.find("[property]").each((_i, el) => {
+      const $el = $(el);
+      if ($el.parent().closest("[typeof]").get(0) !== scope) return;
+      const value = $el.is("[typeof]") ? readItem(el) : propertyValue($el, baseUrl, "content");
+      for (const name of ($el.attr("property") || "").split(/\s+/).filter(Boolean)) {
+        addProperty(data, stripPrefix(name), value);
+      }
+    });
+
+    return data;
+  };
+
+  const items: StructuredDataItem[] = [];
+  $("[typeof]").not("[property]").each((_i, el) => {
+    const data = readItem(el);
+    items.push({ format: "rdfa", types: toTypeList(data["@type"]), data });
+  });
+  return items;
+}
@@ -0,0 +1,112 @@
+import * as cheerio from 'cheerio';
+
+/**
+ * What becomes of inline <svg> graphics.
+ * - text: their accessible name (aria-label, aria-labelledby or <title>), as text
+ * - asset: an image reference ("svg-1.svg") to markup returned alongside the markdown
+ * - raw: the SVG markup itself, passed through
+ * - drop: nothing
+ */
+export type SvgMode = "text" | "asset" | "raw" | "drop";
+
+export interface SvgAsset {
+  /** File name the markdown refers to, e.g. "svg-1.svg". */
+  name: string;
+  /** Standalone SVG document. */
+  markup: string;
+  label?: string;
+}
+
+/** Marks the placeholder left for an SVG with the index of its asset. */
+export const SVG_ATTR = "data-md-svg";
+
+const SVG_NS = "http://www.w3.org/2000/svg";
+
+/**
+ * Removed from exported markup. <foreignObject> holds HTML; the animation
+ * elements can set any attribute, including an href to a javascript: URL,
+ * from their to/from/values, which the sanitizer doesn't look at.
+ */
+const UNSAFE_SVG_ELEMENTS = new Set(["foreignObject", "set", "animate", "animateMotion", "animateTransform"]);
+
+/**
+ * Replaces inline SVGs ahead of the technical-noise removal that would
+ * otherwise drop them. Decorative graphics (aria-hidden, role="presentation"
+ * or "none") and sprite sheets holding only <defs> and <symbol> go in every
+ * mode. In asset and raw modes, <use> references into a sprite sheet
+ * elsewhere on the page are inlined so the markup stands on its own, and
+ * <foreignObject> and animation elements are removed. Together with the
+ * sanitizer's removal of scripts, event handlers and script URLs, that
+ * leaves static drawing markup. Assets are appended to `assets`, and their
+ * placeholders are rendered by renderSvg.
+ */
+export function prepareInlineSvgs($: cheerio.CheerioAPI, mode: SvgMode, assets: SvgAsset[]): void {
+  // Outermost only: an <svg> nested in another is part of the same graphic.
+  const svgs = $("svg").toArray().filter((el) => $(el).parents("svg").length === 0);
+  for (const el of svgs) {
+    const $svg = $(el);
+    const role = ($svg.attr("role") || "").toLowerCase();
+    const decorative = $svg.attr("aria-hidden") === "true" || role === "presentation" || role === "none";
+    const drawable = $svg.children().toArray().some((child: any) => !["defs", "symbol", "title", "desc"].includes(child.name));
+    if (mode === "drop" || decorative || !drawable) continue;
+
+    const label = accessibleName($, $svg);
+    if (mode === "text") {
+      if (label) $svg.replaceWith($("<span>").text(label));
+      continue;
+    }
+
+    inlineUses($, $svg);
+    // Selectors match tag names lowercased, but the parser keeps SVG's camelCase.
+    $svg.find("*").filter((_i, node: any) => UNSAFE_SVG_ELEMENTS.has(node.name)).remove();
+    if (!$svg.attr("xmlns")) $svg.attr("xmlns", SVG_NS);
+    const asset: SvgAsset = { name: `svg-${assets.length + 1}.svg`, markup: $.html($svg) };
+    if (label) asset.label = label;
+    $svg.replaceWith($("<span>").attr(SVG_ATTR, String(assets.push(asset) - 1)));
+  }
+}
+
+/** The markdown for a placeholder left by prepareInlineSvgs. */
+export function renderSvg(asset: SvgAsset, mode: SvgMode): string {
+  if (mode === "raw") return asset.markup.replace(/\s*\n\s*/g, " ");
+  return `![${(asset.label || "").replace(/[[\]]/g, "")}](${asset.name})`;
+}
+
+function accessibleName($: cheerio.CheerioAPI, $svg: cheerio.Cheerio<any>): string {
+  const labelledBy = ($svg.attr("aria-labelledby") || "").split(/\s+/).filter(Boolean);
+  const name =
+    ($svg.attr("aria-label") || "").trim() ||
+    labelledBy.map((id) => byId($, id).text().trim()).filter(Boolean).join(" ") ||
+    $svg.children("title").first().text().trim();
+  return name.replace(/\s+/g, " ");
+}
+
+/** Compared directly: page ids can hold anything, including what would break a selector. */
+function byId($: cheerio.CheerioAPI, id: string): cheerio.Cheerio<any> {
+  return $("[id]").filter((_i, el: any) => el.attribs.id === id).first();
+}
+
+/** Replaces <use href="#id"> with a copy of what it points to, when that lives outside this SVG. */
+function inlineUses($: cheerio.CheerioAPI, $svg: cheerio.Cheerio<any>): void {
+  $svg.find("use").each((_i, use) => {
+    const $use = $(use);
+    const href = $use.attr("href") || $use.attr("xlink:href") || "";
+    if (!href.startsWith("#")) return;
+    const id = href.slice(1);
+    const $target = byId($, id);
+    if (!$target.length || $svg.find($target).length) return;
+
+    if ($target.is("symbol") && !$svg.attr("viewBox") && $target.attr("viewBox")) $svg.attr("viewBox", $target.attr("viewBox")!);
+    const $copy = $("<g>").append($target.is("symbol") ? $target.contents().clone() : $target.clone().removeAttr("id"));
+    // A <g> has no x and y, so the offset <use> applies becomes a translation.
+    const x = $use.attr("x") || "0";
+    const y = $use.attr("y") || "0";
+    const transform = [$use.attr("transform"), x !== "0" || y !== "0" ? `translate(${x} ${y})` : ""].filter(Boolean).join(" ");
+    if (transform) $copy.attr("transform", transform);
+    for (const attr of ["fill", "stroke", "class"]) {
+      const value = $use.attr(attr);
+      if (value !== undefined) $copy.attr(attr, value);
+    }
+    $use.replaceWith($copy);
+  });
+}
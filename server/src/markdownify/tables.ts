@@ -0,0 +1,134 @@
+import * as cheerio from 'cheerio';
+
+export interface ExtractedTable {
+  /** Position of the table in document order, counting nested tables. */
+  index: number;
+  caption?: string;
+  /** Detected header row, empty when the table has no header. */
+  headers: string[];
+  /** Body rows; every row has the same number of columns as the widest row. */
+  rows: string[][];
+  /** RFC 4180 CSV of the header (if any) followed by the body rows. */
+  csv: string;
+}
+
+/** Guards against absurd span values in broken markup blowing up the grid. */
+const MAX_SPAN = 1000;
+
+const LINE_BREAK = "\uE001";
+
+/**
+ * Returns every <table> as a rectangular grid with colspan/rowspan expanded —
+ * a spanned value is repeated in each cell it covers, which is what spreadsheet
+ * exports expect. Nested tables are extracted separately and their rows never
+ * leak into the parent grid.
+ */
+export function extractTables(html: string | null | undefined): ExtractedTable[] {
+  if (!html) return [];
+
+  const $ = cheerio.load(html);
+  const tables: ExtractedTable[] = [];
+
+  $("table").each((index, table) => {
+    const $table = $(table);
+    const $rows = $table.children("tr").add($table.children("thead, tbody, tfoot").children("tr"));
+    if ($rows.length === 0) return;
+
+    const grid: string[][] = [];
+    const headerFlags: boolean[] = [];
+
+    $rows.each((rowIndex, tr) => {
+      grid[rowIndex] = grid[rowIndex] || [];
+      const $cells = $(tr).children("td, th");
+      headerFlags[rowIndex] = $(tr).parent().is("thead") || ($cells.length > 0 && $cells.filter("th").length === $cells.length);
+
+      let col = 0;
+      $cells.each((_c, cell) => {
+        const $cell = $(cell);
+        while (grid[rowIndex][col] !== undefined) col++;
+
+        const colspan = clampSpan($cell.attr("colspan"));
+        const rowspan = Math.min(clampSpan($cell.attr("rowspan")), $rows.length - rowIndex);
+        const text = cellText($, $cell);
+
+        for (let r = 0; r < rowspan; r++) {
+          const row = (grid[rowIndex + r] = grid[rowIndex + r] || []);
+          for (let c = 0; c < colspan; c++) row[col + c] = text;
+        }
+        col += colspan;
+      });
+    });
+
+    const width = grid.reduce((max, row) => Math.max(max, row.length), 0);
+    if (width === 0) return;
+    const rect = grid.map((row) => Array.from({ length: width }, (_v, i) => row[i] ?? ""));
+
+    let headerCount = 0;
+    while (headerCount < rect.length - 1 && headerFlags[headerCount]) headerCount++;
+    const headers = mergeHeaderRows(rect.slice(0, headerCount));
+    const rows = rect.slice(headerCount);
+
+    const result: ExtractedTable = {
+      index,
+      headers,
+      rows,
+      csv: toCsv(headers.length ? [headers, ...rows] : rows),
+    };
+    const caption = $table.children("caption").text().replace(/\s+/g, " ").trim();
+    if (caption) result.caption = caption;
+    tables.push(result);
+  });
+
+  return tables;
+}
+
+function clampSpan(value: string | undefined): number {
+  const n = parseInt(value || "1", 10);
+  return Number.isFinite(n) && n > 0 ? Math.min(n, MAX_SPAN) : 1;
+}
+
+/**
+ * Cell text with source-formatting whitespace collapsed; only real line breaks
+ * (<br> and block boundaries) survive as "\n".
+ */
+function cellText($: cheerio.CheerioAPI, $cell: cheerio.Cheerio<any>): string {
+  const $clone = $cell.clone();
+  $clone.find("table").remove();
+  $clone.find("br").replaceWith(LINE_BREAK);
+  $clone.find("p, div, li").each((_i, el) => {
+    $(el).append(LINE_BREAK);
+  });
+  return $clone
+    .text()
+    .split(LINE_BREAK)
+    .map((line) => line.replace(/\s+/g, " ").trim())
+    .filter(Boolean)
+    .join("\n");
+}
+
+/**
+ * Stacked header rows (a grouping row above the real column names) collapse
+ * into one "Group / Column" label per column, skipping repeats produced by
+ * colspan expansion.
+ */
+function mergeHeaderRows(rows: string[][]): string[] {
+  if (rows.length === 0) return [];
+  return rows[0].map((_v, col) => {
+    const parts: string[] = [];
+    for (const row of rows) {
+      const value = row[col];
+      if (value && parts[parts.length - 1] !== value) parts.push(value);
+    }
+    return parts.join(" / ");
+  });
+}
+
+export function toCsv(rows: string[][]): string {
+  return rows
+    .map((row) => row.map(csvField).join(","))
+    .join("\r\n");
+}
+
+function csvField(value: string): string {
+  return /[",\r\n]/.test(value) ? `"${value.replace(/"/g, '""')}"` : value;
+}
@@ -0,0 +1,26 @@
+/**
+ * Reduces markdown to the text a reader would see: link and image syntax
+ * collapse to their labels, emphasis/heading/list/quote markers and table
+ * pipes are removed, and fenced code keeps only its contents.
+ */
+export function markdownToPlainText(markdown: string | null | undefined): string {
+  if (!markdown) return "";
+
+  return markdown
+    .replace(/^\s{0,3}(`{3,}|~{3,})[^\n]*$/gm, "")
+    .replace(/!\[([^\]]*)\]\([^)]*\)/g, "$1")
+    .replace(/\[([^\]]*)\]\([^)]*\)/g, "$1")
+    .replace(/<[^>\n]+>/g, " ")
+    .replace(/^\s{0,3}#{1,6}\s+/gm, "")
+    .replace(/^\s*(?:[-*+]|\d+[.)])\s+/gm, "")
+    .replace(/^\s*>\s?/gm, "")
+    .replace(/^\s*\|?(?:\s*:?-{3,}:?\s*\|)+\s*:?-*:?\s*$/gm, "")
+    .replace(/\s*\|\s*/g, " ")
+    .replace(/(\*\*|__|~~)(.+?)\1/g, "$2")
+    .replace(/(^|[^\\\w])[*_](\S(?:.*?\S)?)[*_](?!\w)/g, "$1$2")
+    .replace(/`([^`]*)`/g, "$1")
+    .replace(/\\([!-\/:-@\[-`{-~])/g, "$1")
+    .replace(/[ \t]+/g, " ")
+    .replace(/\n{3,}/g, "\n\n")
+    .trim();
+}
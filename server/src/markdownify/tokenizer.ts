@@ -0,0 +1,208 @@
+import * as fs from 'fs';
+import * as path from 'path';
+import logger from '../logger';
+
+export type TokenEncoding = "cl100k_base" | "o200k_base";
+
+export interface TokenCount {
+  encoding: TokenEncoding;
+  tokens: number;
+  /** False when no rank file was available and the count is an estimate. */
+  exact: boolean;
+}
+
+/**
+ * Pre-tokenization patterns of the OpenAI encodings. BPE merges never cross
+ * the pieces these produce, which is what makes per-piece counting (and
+ * truncation at piece boundaries) exact. JavaScript has no inline (?i:), so
+ * the contraction alternatives spell out both cases.
+ */
+const CONTRACTION = "'(?:[sS]|[tT]|[rR][eE]|[vV][eE]|[mM]|[lL][lL]|[dD])";
+const PATTERNS: Record<TokenEncoding, string> = {
+  cl100k_base: `${CONTRACTION}|[^\\r\\n\\p{L}\\p{N}]?\\p{L}+|\\p{N}{1,3}| ?[^\\s\\p{L}\\p{N}]+[\\r\\n]*|\\s*[\\r\\n]+|\\s+(?!\\S)|\\s+`,
+  o200k_base: [
+    `[^\\r\\n\\p{L}\\p{N}]?[\\p{Lu}\\p{Lt}\\p{Lm}\\p{Lo}\\p{M}]*[\\p{Ll}\\p{Lm}\\p{Lo}\\p{M}]+(?:${CONTRACTION})?`,
+    `[^\\r\\n\\p{L}\\p{N}]?[\\p{Lu}\\p{Lt}\\p{Lm}\\p{Lo}\\p{M}]+[\\p{Ll}\\p{Lm}\\p{Lo}\\p{M}]*(?:${CONTRACTION})?`,
+    `\\p{N}{1,3}`,
+    ` ?[^\\s\\p{L}\\p{N}]+[\\r\\n/]*`,
+    `\\s*[\\r\\n]+`,
+    `\\s+(?!\\S)`,
+    `\\s+`,
+  ].join("|"),
+};
+
+/**
+ * Rank files use the standard .tiktoken format ("<base64 token> <rank>" per
+ * line) and are looked up as `<TOKENIZER_RANKS_DIR>/<encoding>.tiktoken`. The
+ * vocabularies are several megabytes, so they're not bundled: without them
+ * every count is an estimate.
+ */
+const RANKS_DIR = process.env.TOKENIZER_RANKS_DIR || "";
+
+const rankCache = new Map<TokenEncoding, Map<string, number> | null>();
+
+function loadRanks(encoding: TokenEncoding): Map<string, number> | null {
+  if (rankCache.has(encoding)) return rankCache.get(encoding)!;
+
+  let ranks: Map<string, number> | null = null;
+  const file = RANKS_DIR ? path.join(RANKS_DIR, `${encoding}.tiktoken`) : "";
+  if (file && fs.existsSync(file)) {
+    try {
+      ranks = new Map();
+      for (const line of fs.readFileSync(file, "utf8").split("\n")) {
+        const [token, rank] = line.trim().split(/\s+/);
+        if (!token || rank === undefined) continue;
+        ranks.set(Buffer.from(token, "base64").toString("latin1"), Number(rank));
+      }
+    } catch (err: any) {
+      logger.log('warn', `Failed to load ${encoding} ranks from ${file}: ${err.message}`);
+      ranks = null;
+    }
+  }
+  // Cached, so this is logged once per encoding.
+  if (!ranks) logger.log('warn', `No ${encoding} rank file in TOKENIZER_RANKS_DIR; token counts are estimates`);
+
+  rankCache.set(encoding, ranks);
+  return ranks;
+}
+
+function pretokenize(text: string, encoding: TokenEncoding): string[] {
+  return text.match(new RegExp(PATTERNS[encoding], "gu")) || [];
+}
+
+/** A mergeable pair of adjacent parts: bytes [left, mid) and [mid, right). */
+interface Pair {
+  rank: number;
+  left: number;
+  mid: number;
+  right: number;
+}
+
+/** Lowest rank first, the leftmost of equal ranks first, as the encoder merges them. */
+const pairBefore = (a: Pair, b: Pair) => a.rank < b.rank || (a.rank === b.rank && a.left < b.left);
+
+function pushPair(heap: Pair[], pair: Pair): void {
+  let i = heap.push(pair) - 1;
+  while (i > 0) {
+    const parent = (i - 1) >> 1;
+    if (!pairBefore(heap[i], heap[parent])) break;
+    [heap[i], heap[parent]] = [heap[parent], heap[i]];
+    i = parent;
+  }
+}
+
+function popPair(heap: Pair[]): Pair | undefined {
+  const top = heap[0];
+  const last = heap.pop();
+  if (heap.length && last) {
+    heap[0] = last;
+    let i = 0;
+    for (;;) {
+      const left = 2 * i + 1;
+      const right = left + 1;
+      let first = i;
+      if (left < heap.length && pairBefore(heap[left], heap[first])) first = left;
+      if (right < heap.length && pairBefore(heap[right], heap[first])) first = right;
+      if (first === i) break;
+      [heap[i], heap[first]] = [heap[first], heap[i]];
+      i = first;
+    }
+  }
+  return top;
+}
+
+/**
+ * Byte-pair merge of one pre-token: repeatedly merge the adjacent pair with
+ * the lowest rank until no mergeable pair remains. Returns the token count.
+ * Parts are linked by their byte offsets and candidate pairs wait in a heap,
+ * dropped when a merge changed either side, so a long piece (a run of
+ * whitespace) takes O(n log n) rather than a rescan per merge.
+ */
+function bpeCount(piece: string, ranks: Map<string, number>): number {
+  const bytes = Buffer.from(piece, "utf8").toString("latin1");
+  if (ranks.has(bytes)) return 1;
+
+  // The part starting at byte i ends at end[i] (-1 once merged into the one
+  // before it) and follows the part starting at prev[i].
+  const end = Array.from({ length: bytes.length }, (_v, i) => i + 1);
+  const prev = Array.from({ length: bytes.length }, (_v, i) => i - 1);
+  const heap: Pair[] = [];
+  const consider = (left: number) => {
+    const mid = end[left];
+    if (mid >= bytes.length) return;
+    const rank = ranks.get(bytes.slice(left, end[mid]));
+    if (rank !== undefined) pushPair(heap, { rank, left, mid, right: end[mid] });
+  };
+  for (let i = 0; i < bytes.length - 1; i++) consider(i);
+
+  let parts = bytes.length;
+  for (let pair = popPair(heap); pair; pair = popPair(heap)) {
+    const { left, mid, right } = pair;
+    if (end[left] !== mid || end[mid] !== right) continue;
+    end[left] = right;
+    end[mid] = -1;
+    if (right < bytes.length) prev[right] = left;
+    parts--;
+    consider(left);
+    if (left > 0) consider(prev[left]);
+  }
+  return parts;
+}
+
+/**
+ * Fallback when no vocabulary is installed: common short words are single
+ * tokens, longer runs average about four bytes per token for ASCII and about
+ * one token per character elsewhere.
+ */
+function estimateCount(piece: string): number {
+  const trimmed = piece.replace(/^\s/, "");
+  if (!trimmed) return 1;
+  if (/^[\x00-\x7F]+$/.test(trimmed)) return trimmed.length <= 6 ? 1 : Math.ceil(trimmed.length / 4);
+  return Array.from(trimmed).length;
+}
+
+function pieceCounter(encoding: TokenEncoding): { count: (piece: string) => number; exact: boolean } {
+  const ranks = loadRanks(encoding);
+  if (!ranks) return { count: estimateCount, exact: false };
+  const cache = new Map<string, number>();
+  return {
+    count: (piece) => {
+      let n = cache.get(piece);
+      if (n === undefined) {
+        n = bpeCount(piece, ranks);
+        if (cache.size < 50000) cache.set(piece, n);
+      }
+      return n;
+    },
+    exact: true,
+  };
+}
+
+/**
+ * Counts tokens as an OpenAI model using `encoding` would, but only when the
+ * encoding's rank file is installed (see TOKENIZER_RANKS_DIR). Otherwise the
+ * count is a rough estimate from the pre-tokenized pieces (see estimateCount),
+ * least reliable outside English, and `exact` is false.
+ */
+export function countTokens(text: string | null | undefined, encoding: TokenEncoding = "cl100k_base"): TokenCount {
+  const { count, exact } = pieceCounter(encoding);
+  const tokens = pretokenize(text || "", encoding).reduce((sum, piece) => sum + count(piece), 0);
+  return { encoding, tokens, exact };
+}
+
+/**
+ * Cuts text to at most `maxTokens` tokens at a pre-token boundary, so the
+ * result never ends in half a word and is the same on every run. Without a
+ * rank file the budget is measured with the same estimate as countTokens.
+ */
+export function truncateToTokens(text: string, maxTokens: number, encoding: TokenEncoding = "cl100k_base"): string {
+  const { count } = pieceCounter(encoding);
+  let used = 0;
+  let out = "";
+  for (const piece of pretokenize(text, encoding)) {
+    used += count(piece);
+    if (used > maxTokens) break;
+    out += piece;
+  }
+  return out;
+}
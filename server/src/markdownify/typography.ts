@@ -0,0 +1,37 @@
+/**
+ * Direction of typographic normalization applied to scraped text.
+ * - ascii: curly quotes, dashes, ellipses and exotic spaces become plain ASCII,
+ *   which CSV/Sheets exports and LLM tokenizers handle far better
+ * - unicode: the reverse, straight quotes and ASCII dash/ellipsis sequences
+ *   become their typographic equivalents
+ */
+export type TypographyMode = "ascii" | "unicode";
+
+const ASCII_REPLACEMENTS: Array<[RegExp, string]> = [
+  [/[‘’‚‛′‵]/g, "'"],
+  [/[“”„‟″‶«»]/g, '"'],
+  [/—|―/g, "--"],
+  [/[‐‑‒–−]/g, "-"],
+  [/…/g, "..."],
+  [/[\u00A0\u2000-\u200A\u202F\u205F\u3000]/g, " "],
+  [/[\u00AD\u200B\u2060\uFEFF]/g, ""],
+];
+
+/**
+ * Normalize the typography of a plain text fragment. Only ever called on text
+ * nodes, never on code or URLs, so quote conversion cannot corrupt markup.
+ */
+export function normalizeTypography(text: string, mode: TypographyMode): string {
+  if (mode === "ascii") {
+    return ASCII_REPLACEMENTS.reduce((acc, [re, rep]) => acc.replace(re, rep), text);
+  }
+
+  return text
+    .replace(/\.\.\./g, "…")
+    .replace(/(^|[^-])---(?!-)/g, "$1—")
+    .replace(/(^|[^-])--(?!-)/g, "$1–")
+    .replace(/(^|[\s([{—–])"/g, "$1“")
+    .replace(/"/g, "”")
+    .replace(/(^|[\s([{—–])'/g, "$1‘")
+    .replace(/'/g, "’");
+}
@@ -0,0 +1,136 @@
+import * as cheerio from 'cheerio';
+import { URL } from 'url';
+
+/**
+ * Resolves `href` against `baseUrl`, returning the input unchanged when there
+ * is no base or it cannot be parsed. data:, mailto:, tel: and javascript: URLs
+ * are absolute and therefore pass through untouched.
+ */
+export function resolveUrl(href: string, baseUrl?: string | null): string {
+  const trimmed = href.trim();
+  if (!trimmed) return "";
+  try {
+    return baseUrl ? new URL(trimmed, baseUrl).toString() : new URL(trimmed).toString();
+  } catch {
+    return trimmed;
+  }
+}
+
+/**
+ * Resolves the effective base for relative URLs: a <base href> in the page
+ * wins over the URL the page was fetched from, as it does in browsers.
+ */
+export function documentBaseUrl($: cheerio.CheerioAPI, baseUrl?: string | null): string | null {
+  const baseHref = $("base[href]").first().attr("href");
+  if (baseHref) {
+    const resolved = resolveUrl(baseHref, baseUrl);
+    if (/^https?:\/\//i.test(resolved)) return resolved;
+  }
+  return baseUrl ?? null;
+}
+
+/**
+ * The URL the page says it lives at: <link rel="canonical">, falling back to
+ * og:url. Relative canonicals are resolved against the document base, and
+ * anything that isn't http(s) is ignored. Returns null when neither is set.
+ */
+export function extractCanonicalUrl(html: string | null | undefined, baseUrl?: string | null): string | null {
+  if (!html) return null;
+  const $ = cheerio.load(html);
+  const base = documentBaseUrl($, baseUrl);
+
+  const candidates = [
+    $('link[rel~="canonical" i][href]').first().attr("href"),
+    $('meta[property="og:url" i], meta[name="og:url" i]').first().attr("content"),
+  ];
+  for (const candidate of candidates) {
+    if (!candidate?.trim()) continue;
+    const resolved = resolveUrl(candidate, base);
+    if (/^https?:\/\//i.test(resolved)) return resolved;
+  }
+  return null;
+}
+
+export interface NormalizeUrlOptions {
+  /** Sort query parameters by name (default true). */
+  sortQuery?: boolean;
+  /**
+   * Query parameters to drop. Strings match names exactly, case-insensitively;
+   * defaults to DEFAULT_STRIP_PARAMS. Pass [] to keep every parameter.
+   */
+  stripParams?: (string | RegExp)[];
+  /** Drop the #fragment (default true). */
+  stripHash?: boolean;
+  /** Drop a leading "www." from the host (default false). */
+  stripWww?: boolean;
+  /** Drop a trailing "/" from non-root paths (default false). */
+  stripTrailingSlash?: boolean;
+}
+
+/** Tracking and session parameters that never change what a page shows. */
+export const DEFAULT_STRIP_PARAMS: (string | RegExp)[] = [
+  /^utm_/i, "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid", "twclid", "igshid",
+  "mc_cid", "mc_eid", "_ga", "_gl", "_hsenc", "_hsmi", "mkt_tok", "oly_anon_id", "oly_enc_id", "vero_id",
+];
+
+/**
+ * Rewrites a URL into one canonical spelling so the same page crawled via
+ * different links deduplicates: lowercase scheme and host, no default port or
+ * trailing dot, dot segments resolved, percent-escapes uppercased with
+ * unreserved characters decoded, tracking parameters dropped and the rest
+ * sorted. Unparseable input is returned trimmed but otherwise unchanged.
+ */
+export function normalizeUrl(url: string, options: NormalizeUrlOptions = {}): string {
+  let parsed: URL;
+  try {
+    parsed = new URL(url.trim());
+  } catch {
+    return url.trim();
+  }
+  // WHATWG URL already lowercases the scheme and host, drops default ports and resolves "." and "..".
+  if (!/^https?:$/.test(parsed.protocol)) return parsed.toString();
+
+  parsed.hostname = parsed.hostname.replace(/\.$/, "");
+  if (options.stripWww) parsed.hostname = parsed.hostname.replace(/^www\./, "");
+
+  let path = normalizeEscapes(parsed.pathname);
+  if (options.stripTrailingSlash && path.length > 1) path = path.replace(/\/+$/, "") || "/";
+
+  const strip = options.stripParams ?? DEFAULT_STRIP_PARAMS;
+  const pairs = parsed.search
+    .slice(1)
+    .split("&")
+    .filter(Boolean)
+    .map((pair) => normalizeEscapes(pair))
+    .filter((pair) => {
+      const name = safeDecode(pair.split("=")[0].replace(/\+/g, " "));
+      return !strip.some((rule) => (typeof rule === "string" ? rule.toLowerCase() === name.toLowerCase() : rule.test(name)));
+    });
+  // Sort by name only: a stable sort keeps repeated parameters (?a=2&a=1) in their original, meaningful order.
+  if (options.sortQuery ?? true) pairs.sort((x, y) => compareNames(x.split("=")[0], y.split("=")[0]));
+
+  const hash = (options.stripHash ?? true) ? "" : parsed.hash;
+  const auth = parsed.username ? `${parsed.username}${parsed.password ? `:${parsed.password}` : ""}@` : "";
+  return `${parsed.protocol}//${auth}${parsed.host}${path}${pairs.length ? `?${pairs.join("&")}` : ""}${hash}`;
+}
+
+/** Uppercases %xx escapes and decodes the ones that stand for unreserved characters. */
+function normalizeEscapes(value: string): string {
+  return value.replace(/%([0-9a-f]{2})/gi, (_m, hex: string) => {
+    const char = String.fromCharCode(parseInt(hex, 16));
+    return /[A-Za-z0-9\-._~]/.test(char) ? char : `%${hex.toUpperCase()}`;
+  });
+}
+
+/** decodeURIComponent, returning malformed input unchanged. */
+export function safeDecode(value: string): string {
+  try {
+    return decodeURIComponent(value);
+  } catch {
+    return value;
+  }
+}
+
+function compareNames(a: string, b: string): number {
+  return a < b ? -1 : a > b ? 1 : 0;
+}
@@ -0,0 +1,781 @@
+import * as cheerio from 'cheerio';
+import { ConversionError, ConversionErrorCode } from './errors';
+
+export interface XPathMatch {
+  type: "element" | "text" | "attribute" | "comment" | "document";
+  /** Tag or attribute name. */
+  name?: string;
+  /** XPath string-value: all descendant text for elements, the value for attributes. */
+  text: string;
+  /** Outer HTML, for elements. */
+  html?: string;
+  attributes?: Record<string, string>;
+  /** Absolute location path of the node, e.g. /html/body/div[2]/a[1]. */
+  path: string;
+}
+
+export interface XPathResult {
+  resultType: "nodes" | "string" | "number" | "boolean";
+  /** Matching nodes in document order; empty for scalar results. */
+  nodes: XPathMatch[];
+  /** The value of a scalar expression such as count(//a) or string(//title). */
+  value?: string | number | boolean;
+}
+
+/**
+ * Evaluates an XPath 1.0 expression against an HTML document. Element and
+ * attribute names are matched case-insensitively, as browsers do for HTML.
+ * Variables and namespace prefixes are not supported. Throws on a malformed
+ * expression.
+ */
+export function evaluateXPath(html: string | null | undefined, expression: string): XPathResult {
+  const ast = parseXPath(expression);
+  const $ = cheerio.load(html || "");
+  const doc = new XDocument($.root()[0] as any);
+  const value = evaluate(ast, { node: doc.root, position: 1, size: 1, doc });
+
+  if (Array.isArray(value)) {
+    return { resultType: "nodes", nodes: value.map((node) => describe($, node)) };
+  }
+  return { resultType: typeof value as "string" | "number" | "boolean", nodes: [], value };
+}
+
+// ---------------------------------------------------------------------------
+// Data model
+// ---------------------------------------------------------------------------
+
+/** domhandler nodes, plus synthetic attribute nodes (domhandler keeps attributes as a plain object). */
+type XNode = any;
+type Value = XNode[] | string | number | boolean;
+
+interface Context {
+  node: XNode;
+  position: number;
+  size: number;
+  doc: XDocument;
+}
+
+class XDocument {
+  readonly root: XNode;
+  private order = new Map<XNode, number>();
+  private attrs = new Map<XNode, XNode[]>();
+
+  constructor(root: XNode) {
+    this.root = root;
+    let i = 0;
+    const walk = (node: XNode) => {
+      this.order.set(node, i++);
+      for (const child of node.children ?? []) walk(child);
+    };
+    walk(root);
+  }
+
+  attributesOf(el: XNode): XNode[] {
+    if (!isElement(el)) return [];
+    let list = this.attrs.get(el);
+    if (!list) {
+      list = Object.entries(el.attribs ?? {}).map(([name, value], i) => ({ type: "attribute", name, value, parent: el, index: i }));
+      this.attrs.set(el, list);
+    }
+    return list;
+  }
+
+  /** Attributes sort right after their element, in source order. */
+  position(node: XNode): number {
+    if (node.type === "attribute") return (this.order.get(node.parent) ?? 0) + (node.index + 1) / 1e4;
+    return this.order.get(node) ?? 0;
+  }
+
+  sort(nodes: XNode[]): XNode[] {
+    const unique = [...new Set(nodes)];
+    return unique.sort((a, b) => this.position(a) - this.position(b));
+  }
+}
+
+function isElement(node: XNode): boolean {
+  return node.type === "tag" || node.type === "script" || node.type === "style";
+}
+
+function isText(node: XNode): boolean {
+  return node.type === "text" || node.type === "cdata";
+}
+
+function stringValue(node: XNode): string {
+  if (node.type === "attribute") return node.value;
+  if (node.type === "text" || node.type === "comment") return node.data ?? "";
+  if (node.type === "cdata") return (node.children ?? []).map(stringValue).join("");
+  if (isElement(node) || node.type === "root") {
+    let out = "";
+    const walk = (n: XNode) => {
+      for (const child of n.children ?? []) {
+        if (isText(child)) out += child.type === "cdata" ? stringValue(child) : child.data;
+        else if (isElement(child)) walk(child);
+      }
+    };
+    walk(node);
+    return out;
+  }
+  return "";
+}
+
+// ---------------------------------------------------------------------------
+// Tokenizer
+// ---------------------------------------------------------------------------
+
+type Token = { type: "op" | "name" | "number" | "literal" | "var" | "eof"; value: string };
+
+const AXES = new Set([
+  "ancestor", "ancestor-or-self", "attribute", "child", "descendant", "descendant-or-self",
+  "following", "following-sibling", "namespace", "parent", "preceding", "preceding-sibling", "self",
+]);
+const NODE_TYPES = new Set(["node", "text", "comment", "processing-instruction"]);
+const OPERATOR_NAMES = new Set(["and", "or", "div", "mod"]);
+
+function tokenize(expr: string): Token[] {
+  const tokens: Token[] = [];
+  const re = /\s*(?:(\d+(?:\.\d*)?|\.\d+)|(\.\.|::|\/\/|!=|<=|>=|[\/()[\]@,|+\-=<>.*])|"([^"]*)"|'([^']*)'|\$([\p{L}_][\p{L}\p{N}_.\-]*(?::[\p{L}_][\p{L}\p{N}_.\-]*)?)|([\p{L}_][\p{L}\p{N}_.\-]*(?::(?:\*|[\p{L}_][\p{L}\p{N}_.\-]*))?))/uy;
+  let pos = 0;
+  while (pos < expr.length) {
+    if (/^\s*$/.test(expr.slice(pos))) break;
+    re.lastIndex = pos;
+    const m = re.exec(expr);
+    if (!m) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid XPath expression: unexpected "${expr.slice(pos).trim()[0]}" at ${pos}`);
+    pos = re.lastIndex;
+
+    // Numbers are tried first so ".5" is a number while "." alone is an op.
+    if (m[1] !== undefined) tokens.push({ type: "number", value: m[1] });
+    else if (m[2] !== undefined) tokens.push({ type: "op", value: m[2] });
+    else if (m[3] !== undefined || m[4] !== undefined) tokens.push({ type: "literal", value: m[3] ?? m[4] });
+    else if (m[5] !== undefined) tokens.push({ type: "var", value: m[5] });
+    else tokens.push({ type: "name", value: m[6] });
+  }
+  tokens.push({ type: "eof", value: "" });
+
+  // Per the spec, "*" multiplies and and/or/div/mod are operators only right
+  // after a token that can end an operand; elsewhere they are name tests.
+  tokens.forEach((token, index) => {
+    const prev = tokens[index - 1];
+    const afterOperand = !!prev && (prev.type !== "op" || [")", "]", ".", ".."].includes(prev.value));
+    if (token.type === "op" && token.value === "*" && !afterOperand) token.type = "name";
+    else if (token.type === "name" && OPERATOR_NAMES.has(token.value) && afterOperand) token.type = "op";
+  });
+  return tokens;
+}
+
+// ---------------------------------------------------------------------------
+// Parser
+// ---------------------------------------------------------------------------
+
+type NodeTest = { kind: "name"; name: string } | { kind: "any" } | { kind: "type"; nodeType: string; literal?: string };
+interface Step {
+  axis: string;
+  test: NodeTest;
+  predicates: Expr[];
+}
+type Expr =
+  | { type: "binary"; op: string; left: Expr; right: Expr }
+  | { type: "negate"; operand: Expr }
+  | { type: "union"; paths: Expr[] }
+  | { type: "path"; absolute: boolean; filter?: Expr; steps: Step[] }
+  | { type: "filter"; primary: Expr; predicates: Expr[] }
+  | { type: "literal"; value: string }
+  | { type: "number"; value: number }
+  | { type: "call"; name: string; args: Expr[] };
+
+const parseCache = new Map<string, Expr>();
+
+function parseXPath(expression: string): Expr {
+  const cached = parseCache.get(expression);
+  if (cached) return cached;
+
+  const tokens = tokenize(expression);
+  let i = 0;
+  const peek = (offset = 0) => tokens[Math.min(i + offset, tokens.length - 1)];
+  const isOp = (value: string, offset = 0) => peek(offset).type === "op" && peek(offset).value === value;
+  const fail = (message: string): never => {
+    throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, `Invalid XPath expression: ${message} in "${expression}"`);
+  };
+  const expect = (value: string) => {
+    if (!isOp(value)) fail(`expected "${value}" but found "${peek().value || "end of input"}"`);
+    i++;
+  };
+  const binary = (next: () => Expr, ops: string[]) => (): Expr => {
+    let left = next();
+    for (;;) {
+      const op = ops.find((o) => isOp(o));
+      if (!op) return left;
+      i++;
+      left = { type: "binary", op, left, right: next() };
+    }
+  };
+
+  const parsePredicates = (): Expr[] => {
+    const predicates: Expr[] = [];
+    while (isOp("[")) {
+      i++;
+      predicates.push(parseOr());
+      expect("]");
+    }
+    return predicates;
+  };
+
+  const parseStep = (): Step => {
+    if (isOp(".")) {
+      i++;
+      return { axis: "self", test: { kind: "type", nodeType: "node" }, predicates: [] };
+    }
+    if (isOp("..")) {
+      i++;
+      return { axis: "parent", test: { kind: "type", nodeType: "node" }, predicates: [] };
+    }
+
+    let axis = "child";
+    if (isOp("@")) {
+      i++;
+      axis = "attribute";
+    } else if (peek().type === "name" && isOp("::", 1)) {
+      axis = peek().value;
+      if (!AXES.has(axis)) fail(`unknown axis "${axis}"`);
+      i += 2;
+    }
+
+    let test: NodeTest;
+    const t = peek();
+    if (t.type === "name" && t.value === "*") {
+      i++;
+      test = { kind: "any" };
+    } else if (t.type === "name") {
+      i++;
+      if (NODE_TYPES.has(t.value) && isOp("(")) {
+        i++;
+        let literal: string | undefined;
+        if (t.value === "processing-instruction" && peek().type === "literal") literal = tokens[i++].value;
+        expect(")");
+        test = { kind: "type", nodeType: t.value, literal };
+      } else if (t.value.endsWith(":*")) {
+        test = { kind: "any" };
+      } else {
+        test = { kind: "name", name: t.value.toLowerCase() };
+      }
+    } else {
+      return fail(`expected a node test but found "${t.value || "end of input"}"`);
+    }
+
+    return { axis, test, predicates: parsePredicates() };
+  };
+
+  const descendantStep = (): Step => ({ axis: "descendant-or-self", test: { kind: "type", nodeType: "node" }, predicates: [] });
+
+  const parseRelative = (steps: Step[]): Step[] => {
+    steps.push(parseStep());
+    for (;;) {
+      if (isOp("/")) {
+        i++;
+        steps.push(parseStep());
+      } else if (isOp("//")) {
+        i++;
+        steps.push(descendantStep(), parseStep());
+      } else {
+        return steps;
+      }
+    }
+  };
+
+  const startsStep = () => {
+    const t = peek();
+    if (t.type === "op") return ["@", ".", ".."].includes(t.value);
+    if (t.type !== "name") return false;
+    if (isOp("::", 1)) return true;
+    if (isOp("(", 1)) return NODE_TYPES.has(t.value);
+    return true;
+  };
+
+  const parsePrimary = (): Expr => {
+    const t = peek();
+    if (t.type === "var") return fail(`variables are not supported ($${t.value})`);
+    if (t.type === "literal") {
+      i++;
+      return { type: "literal", value: t.value };
+    }
+    if (t.type === "number") {
+      i++;
+      return { type: "number", value: Number(t.value) };
+    }
+    if (isOp("(")) {
+      i++;
+      const inner = parseOr();
+      expect(")");
+      return inner;
+    }
+    if (t.type === "name" && isOp("(", 1)) {
+      i += 2;
+      const args: Expr[] = [];
+      if (!isOp(")")) {
+        args.push(parseOr());
+        while (isOp(",")) {
+          i++;
+          args.push(parseOr());
+        }
+      }
+      expect(")");
+      if (!(t.value in FUNCTIONS)) fail(`unknown function ${t.value}()`);
+      return { type: "call", name: t.value, args };
+    }
+    return fail(`unexpected "${t.value || "end of input"}"`);
+  };
+
+  const parsePath = (): Expr => {
+    if (isOp("/")) {
+      i++;
+      // A lone "/" selects the document root.
+      const steps = startsStep() ? parseRelative([]) : [];
+      return { type: "path", absolute: true, steps };
+    }
+    if (isOp("//")) {
+      i++;
+      return { type: "path", absolute: true, steps: parseRelative([descendantStep()]) };
+    }
+    if (startsStep()) return { type: "path", absolute: false, steps: parseRelative([]) };
+
+    const primary = parsePrimary();
+    const predicates = parsePredicates();
+    const filter: Expr = predicates.length ? { type: "filter", primary, predicates } : primary;
+    if (isOp("/")) {
+      i++;
+      return { type: "path", absolute: false, filter, steps: parseRelative([]) };
+    }
+    if (isOp("//")) {
+      i++;
+      return { type: "path", absolute: false, filter, steps: parseRelative([descendantStep()]) };
+    }
+    return filter;
+  };
+
+  const parseUnion = (): Expr => {
+    const first = parsePath();
+    if (!isOp("|")) return first;
+    const paths = [first];
+    while (isOp("|")) {
+      i++;
+      paths.push(parsePath());
+    }
+    return { type: "union", paths };
+  };
+
+  const parseUnary = (): Expr => {
+    if (isOp("-")) {
+      i++;
+      return { type: "negate", operand: parseUnary() };
+    }
+    return parseUnion();
+  };
+  const parseMultiplicative = binary(parseUnary, ["*", "div", "mod"]);
+  const parseAdditive = binary(parseMultiplicative, ["+", "-"]);
+  const parseRelational = binary(parseAdditive, ["<=", ">=", "<", ">"]);
+  const parseEquality = binary(parseRelational, ["=", "!="]);
+  const parseAnd = binary(parseEquality, ["and"]);
+  function parseOr(): Expr {
+    return binary(parseAnd, ["or"])();
+  }
+
+  if (!expression.trim()) fail("empty expression");
+  const ast = parseOr();
+  if (peek().type !== "eof") fail(`unexpected "${peek().value}"`);
+
+  if (parseCache.size < 500) parseCache.set(expression, ast);
+  return ast;
+}
+
+// ---------------------------------------------------------------------------
+// Evaluator
+// ---------------------------------------------------------------------------
+
+function evaluate(expr: Expr, ctx: Context): Value {
+  switch (expr.type) {
+    case "literal":
+      return expr.value;
+    case "number":
+      return expr.value;
+    case "negate":
+      return -toNumber(evaluate(expr.operand, ctx));
+    case "union": {
+      const nodes: XNode[] = [];
+      for (const path of expr.paths) {
+        const value = evaluate(path, ctx);
+        if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: | needs node-sets on both sides");
+        nodes.push(...value);
+      }
+      return ctx.doc.sort(nodes);
+    }
+    case "filter": {
+      const value = evaluate(expr.primary, ctx);
+      if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: predicates need a node-set");
+      return applyPredicates(value, expr.predicates, ctx);
+    }
+    case "path":
+      return evaluatePath(expr, ctx);
+    case "call":
+      return FUNCTIONS[expr.name](ctx, expr.args);
+    case "binary":
+      return evaluateBinary(expr.op, expr.left, expr.right, ctx);
+  }
+}
+
+function evaluatePath(expr: Extract<Expr, { type: "path" }>, ctx: Context): XNode[] {
+  let nodes: XNode[];
+  if (expr.filter) {
+    const value = evaluate(expr.filter, ctx);
+    if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: / needs a node-set on the left");
+    nodes = value;
+  } else {
+    nodes = [expr.absolute ? ctx.doc.root : ctx.node];
+  }
+
+  for (const step of expr.steps) {
+    const next: XNode[] = [];
+    for (const node of nodes) {
+      const candidates = axisNodes(step.axis, node, ctx.doc).filter((n) => testNode(step.test, n, step.axis));
+      next.push(...applyPredicates(candidates, step.predicates, ctx));
+    }
+    nodes = ctx.doc.sort(next);
+  }
+  return nodes;
+}
+
+/** Positions count in the order given, which for axis steps is proximity order. */
+function applyPredicates(nodes: XNode[], predicates: Expr[], ctx: Context): XNode[] {
+  let current = nodes;
+  for (const predicate of predicates) {
+    const size = current.length;
+    current = current.filter((node, index) => {
+      const value = evaluate(predicate, { node, position: index + 1, size, doc: ctx.doc });
+      return typeof value === "number" ? value === index + 1 : toBoolean(value);
+    });
+  }
+  return current;
+}
+
+/** Nodes along an axis, in proximity order (reverse document order for reverse axes). */
+function axisNodes(axis: string, node: XNode, doc: XDocument): XNode[] {
+  const children = (n: XNode): XNode[] => (n.type === "attribute" ? [] : (n.children ?? []).filter((c: XNode) => c.type !== "directive"));
+  const descendants = (n: XNode, out: XNode[] = []): XNode[] => {
+    for (const child of children(n)) {
+      out.push(child);
+      descendants(child, out);
+    }
+    return out;
+  };
+  const ancestors = (n: XNode): XNode[] => {
+    const out: XNode[] = [];
+    for (let p = n.parent; p; p = p.parent) out.push(p);
+    return out;
+  };
+  const siblings = (n: XNode): XNode[] => (n.type === "attribute" || !n.parent ? [] : children(n.parent));
+
+  switch (axis) {
+    case "child":
+      return children(node);
+    case "descendant":
+      return descendants(node);
+    case "descendant-or-self":
+      return [node, ...descendants(node)];
+    case "self":
+      return [node];
+    case "parent":
+      return node.parent ? [node.parent] : [];
+    case "ancestor":
+      return ancestors(node);
+    case "ancestor-or-self":
+      return [node, ...ancestors(node)];
+    case "attribute":
+      return doc.attributesOf(node);
+    case "following-sibling": {
+      const all = siblings(node);
+      return all.slice(all.indexOf(node) + 1);
+    }
+    case "preceding-sibling": {
+      const all = siblings(node);
+      return all.slice(0, Math.max(0, all.indexOf(node))).reverse();
+    }
+    case "following": {
+      const start = node.type === "attribute" ? node.parent : node;
+      const out: XNode[] = [];
+      if (node.type === "attribute") out.push(...descendants(start));
+      for (let n = start; n && n.parent; n = n.parent) {
+        for (const sibling of axisNodes("following-sibling", n, doc)) out.push(sibling, ...descendants(sibling));
+      }
+      return out;
+    }
+    case "preceding": {
+      const start = node.type === "attribute" ? node.parent : node;
+      const ancestorSet = new Set(ancestors(start));
+      const limit = doc.position(start);
+      return descendants(doc.root)
+        .filter((n) => doc.position(n) < limit && !ancestorSet.has(n))
+        .reverse();
+    }
+    default:
+      return []; // namespace axis: HTML documents expose no namespace nodes
+  }
+}
+
+function testNode(test: NodeTest, node: XNode, axis: string): boolean {
+  const principal = axis === "attribute" ? "attribute" : "element";
+  if (test.kind === "any") return principal === "attribute" ? node.type === "attribute" : isElement(node);
+  if (test.kind === "name") {
+    const isPrincipal = principal === "attribute" ? node.type === "attribute" : isElement(node);
+    return isPrincipal && String(node.name).toLowerCase() === test.name;
+  }
+  switch (test.nodeType) {
+    case "node":
+      return true;
+    case "text":
+      return isText(node);
+    case "comment":
+      return node.type === "comment";
+    default:
+      return false; // processing instructions do not occur in HTML
+  }
+}
+
+function evaluateBinary(op: string, leftExpr: Expr, rightExpr: Expr, ctx: Context): Value {
+  if (op === "or") return toBoolean(evaluate(leftExpr, ctx)) || toBoolean(evaluate(rightExpr, ctx));
+  if (op === "and") return toBoolean(evaluate(leftExpr, ctx)) && toBoolean(evaluate(rightExpr, ctx));
+
+  const left = evaluate(leftExpr, ctx);
+  const right = evaluate(rightExpr, ctx);
+  switch (op) {
+    case "+":
+      return toNumber(left) + toNumber(right);
+    case "-":
+      return toNumber(left) - toNumber(right);
+    case "*":
+      return toNumber(left) * toNumber(right);
+    case "div":
+      return toNumber(left) / toNumber(right);
+    case "mod":
+      return toNumber(left) % toNumber(right);
+    default:
+      return compare(op, left, right);
+  }
+}
+
+/**
+ * XPath 1.0 comparison: a node-set compares true if any member does, after
+ * converting to the other operand's type; = and != prefer boolean, then
+ * number, then string; relational operators always compare numbers.
+ */
+function compare(op: string, left: Value, right: Value): boolean {
+  const leftNodes = Array.isArray(left);
+  const rightNodes = Array.isArray(right);
+
+  if (leftNodes && rightNodes) {
+    const rightStrings = (right as XNode[]).map(stringValue);
+    return (left as XNode[]).some((l) => rightStrings.some((r) => compareAtoms(op, stringValue(l), r)));
+  }
+  if (leftNodes || rightNodes) {
+    const nodes = (leftNodes ? left : right) as XNode[];
+    const other = leftNodes ? right : left;
+    if (typeof other === "boolean") return compareAtoms(op, leftNodes ? toBoolean(nodes) : other, leftNodes ? other : toBoolean(nodes));
+    return nodes.some((n) => {
+      const atom = typeof other === "number" ? toNumber(stringValue(n)) : stringValue(n);
+      return leftNodes ? compareAtoms(op, atom, other) : compareAtoms(op, other, atom);
+    });
+  }
+  return compareAtoms(op, left, right);
+}
+
+function compareAtoms(op: string, left: string | number | boolean | XNode[], right: string | number | boolean | XNode[]): boolean {
+  if (op === "=" || op === "!=") {
+    let equal: boolean;
+    if (typeof left === "boolean" || typeof right === "boolean") equal = toBoolean(left) === toBoolean(right);
+    else if (typeof left === "number" || typeof right === "number") equal = toNumber(left) === toNumber(right);
+    else equal = toString(left) === toString(right);
+    return op === "=" ? equal : !equal;
+  }
+  const l = toNumber(left);
+  const r = toNumber(right);
+  switch (op) {
+    case "<":
+      return l < r;
+    case "<=":
+      return l <= r;
+    case ">":
+      return l > r;
+    default:
+      return l >= r;
+  }
+}
+
+function toBoolean(value: Value): boolean {
+  if (Array.isArray(value)) return value.length > 0;
+  if (typeof value === "number") return value !== 0 && !Number.isNaN(value);
+  if (typeof value === "string") return value.length > 0;
+  return value;
+}
+
+function toNumber(value: Value): number {
+  if (typeof value === "number") return value;
+  if (typeof value === "boolean") return value ? 1 : 0;
+  const text = Array.isArray(value) ? (value.length ? stringValue(value[0]) : "") : value;
+  return /^\s*-?(?:\d+(?:\.\d*)?|\.\d+)\s*$/.test(text) ? Number(text) : NaN;
+}
+
+function toString(value: Value): string {
+  if (Array.isArray(value)) return value.length ? stringValue(value[0]) : "";
+  if (typeof value === "boolean") return value ? "true" : "false";
+  if (typeof value === "number") return numberToString(value);
+  return value;
+}
+
+function numberToString(n: number): string {
+  if (Number.isNaN(n)) return "NaN";
+  if (!Number.isFinite(n)) return n > 0 ? "Infinity" : "-Infinity";
+  if (Object.is(n, -0)) return "0";
+  const text = String(n);
+  if (!/e/i.test(text)) return text;
+  // XPath never uses exponent notation.
+  return n.toFixed(Math.min(100, Math.max(0, 20 - Math.floor(Math.log10(Math.abs(n)))))).replace(/\.?0+$/, "");
+}
+
+// ---------------------------------------------------------------------------
+// Core function library
+// ---------------------------------------------------------------------------
+
+type XFunction = (ctx: Context, args: Expr[]) => Value;
+
+const arg = (ctx: Context, args: Expr[], i: number): Value => evaluate(args[i], ctx);
+const str = (ctx: Context, args: Expr[], i: number): string => (args[i] ? toString(evaluate(args[i], ctx)) : stringValue(ctx.node));
+const nodeArg = (ctx: Context, args: Expr[]): XNode | undefined => {
+  if (!args.length) return ctx.node;
+  const value = arg(ctx, args, 0);
+  if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: expected a node-set argument");
+  return value[0];
+};
+const nameOf = (node: XNode | undefined): string => (node && (isElement(node) || node.type === "attribute") ? String(node.name) : "");
+
+const FUNCTIONS: Record<string, XFunction> = {
+  last: (ctx) => ctx.size,
+  position: (ctx) => ctx.position,
+  count: (ctx, args) => {
+    const value = arg(ctx, args, 0);
+    if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: count() expects a node-set");
+    return value.length;
+  },
+  id: (ctx, args) => {
+    const value = arg(ctx, args, 0);
+    const ids = new Set((Array.isArray(value) ? value.map(stringValue).join(" ") : toString(value)).split(/\s+/).filter(Boolean));
+    const found: XNode[] = [];
+    const walk = (n: XNode) => {
+      for (const child of n.children ?? []) {
+        if (isElement(child) && ids.has(child.attribs?.id)) found.push(child);
+        walk(child);
+      }
+    };
+    walk(ctx.doc.root);
+    return found;
+  },
+  "local-name": (ctx, args) => nameOf(nodeArg(ctx, args)).replace(/^.*:/, ""),
+  "namespace-uri": () => "",
+  name: (ctx, args) => nameOf(nodeArg(ctx, args)),
+  string: (ctx, args) => str(ctx, args, 0),
+  concat: (ctx, args) => args.map((_a, i) => str(ctx, args, i)).join(""),
+  "starts-with": (ctx, args) => str(ctx, args, 0).startsWith(str(ctx, args, 1)),
+  contains: (ctx, args) => str(ctx, args, 0).includes(str(ctx, args, 1)),
+  "substring-before": (ctx, args) => {
+    const s = str(ctx, args, 0);
+    const idx = s.indexOf(str(ctx, args, 1));
+    return idx < 0 ? "" : s.slice(0, idx);
+  },
+  "substring-after": (ctx, args) => {
+    const s = str(ctx, args, 0);
+    const sub = str(ctx, args, 1);
+    const idx = s.indexOf(sub);
+    return idx < 0 ? "" : s.slice(idx + sub.length);
+  },
+  substring: (ctx, args) => {
+    // Positions are 1-based and rounded; characters are counted by code point.
+    const chars = Array.from(str(ctx, args, 0));
+    const start = round(toNumber(arg(ctx, args, 1)));
+    const end = args[2] ? start + round(toNumber(arg(ctx, args, 2))) : Infinity;
+    return chars.filter((_c, i) => i + 1 >= start && i + 1 < end).join("");
+  },
+  "string-length": (ctx, args) => Array.from(str(ctx, args, 0)).length,
+  "normalize-space": (ctx, args) => str(ctx, args, 0).replace(/[\x20\t\r\n]+/g, " ").trim(),
+  translate: (ctx, args) => {
+    const from = Array.from(str(ctx, args, 1));
+    const to = Array.from(str(ctx, args, 2));
+    return Array.from(str(ctx, args, 0))
+      .map((c) => {
+        const idx = from.indexOf(c);
+        return idx < 0 ? c : to[idx] ?? "";
+      })
+      .join("");
+  },
+  boolean: (ctx, args) => toBoolean(arg(ctx, args, 0)),
+  not: (ctx, args) => !toBoolean(arg(ctx, args, 0)),
+  true: () => true,
+  false: () => false,
+  lang: (ctx, args) => {
+    const wanted = str(ctx, args, 0).toLowerCase();
+    for (let n = ctx.node.type === "attribute" ? ctx.node.parent : ctx.node; n; n = n.parent) {
+      const lang = n.attribs?.lang ?? n.attribs?.["xml:lang"];
+      if (lang !== undefined) {
+        const value = String(lang).toLowerCase();
+        return value === wanted || value.startsWith(`${wanted}-`);
+      }
+    }
+    return false;
+  },
+  number: (ctx, args) => toNumber(args.length ? arg(ctx, args, 0) : stringValue(ctx.node)),
+  sum: (ctx, args) => {
+    const value = arg(ctx, args, 0);
+    if (!Array.isArray(value)) throw new ConversionError(ConversionErrorCode.INVALID_OPTIONS, "Invalid XPath expression: sum() expects a node-set");
+    return value.reduce((total: number, n: XNode) => total + toNumber(stringValue(n)), 0);
+  },
+  floor: (ctx, args) => Math.floor(toNumber(arg(ctx, args, 0))),
+  ceiling: (ctx, args) => Math.ceil(toNumber(arg(ctx, args, 0))),
+  round: (ctx, args) => round(toNumber(arg(ctx, args, 0))),
+};
+
+/** XPath round(): halves go towards positive infinity, NaN and infinities pass through. */
+function round(n: number): number {
+  return Number.isFinite(n) ? Math.floor(n + 0.5) : n;
+}
+
+// ---------------------------------------------------------------------------
+// Output
+// ---------------------------------------------------------------------------
+
+function describe($: cheerio.CheerioAPI, node: XNode): XPathMatch {
+  const path = xpathOf(node);
+  if (node.type === "attribute") return { type: "attribute", name: node.name, text: node.value, path };
+  if (node.type === "root") return { type: "document", text: stringValue(node), path };
+  if (node.type === "comment") return { type: "comment", text: node.data ?? "", path };
+  if (isText(node)) return { type: "text", text: stringValue(node), path };
+  return {
+    type: "element",
+    name: node.name,
+    text: stringValue(node),
+    html: $.html(node),
+    attributes: { ...(node.attribs ?? {}) },
+    path,
+  };
+}
+
+/** Absolute, index-qualified location path of a node, e.g. /html[1]/body[1]/p[2]. */
+export function xpathOf(node: XNode): string {
+  if (node.type === "root") return "/";
+  if (node.type === "attribute") return `${xpathOf(node.parent)}/@${node.name}`;
+
+  const parts: string[] = [];
+  for (let n = node; n && n.type !== "root"; n = n.parent) {
+    const siblings = (n.parent?.children ?? []) as XNode[];
+    const same = (s: XNode) =>
+      isElement(n) ? isElement(s) && s.name === n.name : isText(n) ? isText(s) : s.type === n.type;
+    const index = siblings.filter(same).indexOf(n) + 1;
+    const test = isElement(n) ? n.name : isText(n) ? "text()" : "comment()";
+    parts.unshift(`${test}[${index}]`);
+  }
+  return `/${parts.join("/")}`;
+}
@@ -0,0 +1,57 @@
+import JSZip from 'jszip';
+import { ConversionError, ConversionErrorCode } from './errors';
+
+/** Largest single entry unpacked from an archive; a bigger one is a zip bomb or broken. */
+export const MAX_ZIP_ENTRY_BYTES = 50 * 1024 * 1024;
+
+export interface ZipReader {
+  /** The entry's bytes, or undefined when the archive has no such entry. */
+  bytes(name: string): Promise<Buffer | undefined>;
+  /** The entry as UTF-8 text, or "" when the archive has no such entry. */
+  text(name: string): Promise<string>;
+}
+
+/**
+ * Reads entries of an archive with a cap on what they unpack to: each entry
+ * to MAX_ZIP_ENTRY_BYTES, and all entries together to `totalLimit`. Sizes in
+ * the archive's directory can lie, so entries are counted as they inflate
+ * and abandoned as soon as they pass the cap, with an INPUT_TOO_LARGE
+ * ConversionError.
+ */
+export function createZipReader(zip: JSZip, totalLimit = Infinity): ZipReader {
+  let unpacked = 0;
+
+  const bytes = async (name: string): Promise<Buffer | undefined> => {
+    const file = zip.file(name);
+    if (!file) return undefined;
+    const limit = Math.min(MAX_ZIP_ENTRY_BYTES, totalLimit - unpacked);
+    const tooLarge = () =>
+      new ConversionError(ConversionErrorCode.INPUT_TOO_LARGE, `Archive entry ${name} unpacks to more than ${limit} bytes`);
+
+    const data = await new Promise<Buffer>((resolve, reject) => {
+      const chunks: Uint8Array[] = [];
+      let size = 0;
+      const stream = file.internalStream("uint8array");
+      stream
+        .on("data", (chunk: Uint8Array) => {
+          size += chunk.length;
+          if (size > limit) {
+            stream.pause();
+            reject(tooLarge());
+            return;
+          }
+          chunks.push(chunk);
+        })
+        .on("error", reject)
+        .on("end", () => resolve(Buffer.concat(chunks)))
+        .resume();
+    });
+    unpacked += data.length;
+    return data;
+  };
+
+  return {
+    bytes,
+    text: async (name) => (await bytes(name))?.toString("utf8") ?? "",
+  };
+}
@@ -3,6 +3,7 @@ import axios from "axios";
 import logger from "../../logger";
 import Run from "../../models/Run";
 import Robot from "../../models/Robot";
+import { markdownToAirtable } from "../../markdownify/airtable";
 
 interface AirtableUpdateTask {
   robotId: string;
@@ -24,6 +25,9 @@ const MAX_RETRIES = 3;
 const BASE_API_DELAY = 2000;
 const MAX_QUEUE_SIZE = 1000;
 
+// Fields holding converted markdown, by the "Type" of the records that carry it.
+const RICH_TEXT_FIELDS: Record<string, string> = { Markdown: "Content" };
+
 export let airtableUpdateTasks: { [runId: string]: AirtableUpdateTask } = {};
 let isProcessingAirtable = false;
 
@@ -136,7 +140,7 @@ function mergeRelatedData(serializableOutput: SerializableOutput, binaryOutput:
         markdownData.push({
           "Index": index + 1,
           "Type": "Markdown",
-          "Content": item.content
+          "Content": markdownToAirtable(item.content)
         });
       }
     });
@@ -460,9 +464,10 @@ export async function writeDataToAirtable(
         for (const field of missingFields) {
           const sampleRow = processedData.find(row => field in row && row[field] !== '');
           if (sampleRow) {
-            const sampleValue = sampleRow[field];
+            const richText = processedData.some(row => RICH_TEXT_FIELDS[row.Type] === field);
+            const fieldType = richText ? 'richText' : inferFieldType(sampleRow[field]);
             try {
-              await createAirtableField(baseId, tableName, field, sampleValue, accessToken, tableId);
+              await createAirtableField(baseId, tableName, field, fieldType, accessToken, tableId);
               console.log(`Successfully created field: ${field}`);
               await new Promise(resolve => setTimeout(resolve, 200));
             } catch (fieldError: any) {
@@ -575,14 +580,12 @@ async function createAirtableField(
   baseId: string,
   tableName: string,
   fieldName: string,
-  sampleValue: any,
+  fieldType: string,
   accessToken: string,
   tableId: string,
   retries = MAX_RETRIES
 ): Promise<void> {
   try {
-    const fieldType = inferFieldType(sampleValue);
-    
     console.log(`Creating field ${fieldName} with type ${fieldType}`);
     
     const response = await axios.post(
@@ -596,7 +599,7 @@ async function createAirtableField(
   } catch (error: any) {
     if (retries > 0 && error.response?.status === 429) {
       await new Promise(resolve => setTimeout(resolve, BASE_API_DELAY));
-      return createAirtableField(baseId, tableName, fieldName, sampleValue, accessToken, tableId, retries - 1);
+      return createAirtableField(baseId, tableName, fieldName, fieldType, accessToken, tableId, retries - 1);
     }
     
     if (error.response?.status === 422) {
@@ -2,6 +2,7 @@ import { google } from "googleapis";
 import logger from "../../logger";
 import Run from "../../models/Run";
 import Robot from "../../models/Robot";
+import { flattenForSheets } from "../../markdownify/sheets";
 
 interface GoogleSheetUpdateTask {
   robotId: string;
@@ -100,10 +101,20 @@ export async function updateGoogleSheet(robotId: string, runId: string) {
         }
 
         if (serializableOutput.markdown && Array.isArray(serializableOutput.markdown) && serializableOutput.markdown.length > 0) {
-          const markdownData = serializableOutput.markdown.map((item, index) => ({
-            "Index": index + 1,
-            "Content": item.content || ""
-          }));
+          // Tables go to their own sheets as real rows; the remaining text is split under the cell limit.
+          const markdownData: Record<string, any>[] = [];
+          const markdownTables: Record<string, any>[][] = [];
+          serializableOutput.markdown.forEach((item, index) => {
+            const flattened = flattenForSheets(item.content || "");
+            flattened.text.forEach((content, part) => {
+              markdownData.push({ "Index": index + 1, "Part": part + 1, "Content": content });
+            });
+            for (const table of flattened.tables) {
+              const [header, ...rows] = table.rows;
+              const keys = header.map((name, col) => (name && header.indexOf(name) === col ? name : `Column ${col + 1}`));
+              markdownTables.push(rows.map((row) => Object.fromEntries(keys.map((key, col) => [key, row[col]]))));
+            }
+          });
 
           await processOutputType(
             robotId,
@@ -112,6 +123,16 @@ export async function updateGoogleSheet(robotId: string, runId: string) {
             markdownData,
             plainRobot
           );
+
+          for (const [tableIndex, tableData] of markdownTables.entries()) {
+            await processOutputType(
+              robotId,
+              spreadsheetId,
+              `Markdown - Table ${tableIndex + 1}`,
+              tableData,
+              plainRobot
+            );
+          }
         }
 
         if (serializableOutput.html && Array.isArray(serializableOutput.html) && serializableOutput.html.length > 0) {